@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/bench"
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	toolparams "mcp_tstr/internal/params"
+)
+
+var (
+	benchTool        string
+	benchParams      string
+	benchMethod      string
+	benchConcurrency int
+	benchRequests    int
+	benchDuration    time.Duration
+	benchRate        float64
+	benchTimeout     time.Duration
+	benchSessions    int
+	benchOutput      string
+)
+
+// benchCmd represents the bench command
+var benchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Measure throughput and latency of a tool, ping or list requests",
+	Long: `Send the same request to a server over and over and report throughput, error rate and
+p50/p90/p99/max latencies with a histogram. The request is a tool call with --tool and
+--params, or ping, tools/list, resources/list or prompts/list with --method.
+
+--concurrency requests are kept in flight, for --requests requests or for --duration, and
+--rate caps the requests started per second. By default every worker shares one session;
+--sessions opens several sessions and spreads the workers over them. Tool results flagged as
+errors count as failed requests. Use --output json to track results across server versions.
+
+Examples:
+  mcp_tstr bench -s weather --tool forecast --params '{"city":"Oslo"}' -c 8 -n 1000
+  mcp_tstr bench -s weather --method ping -c 4 --duration 30s --rate 200
+  mcp_tstr bench -s weather --method tools/list -c 16 --sessions 16 -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runBench(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+	benchCmd.Flags().StringVar(&benchTool, "tool", "", "tool to call")
+	benchCmd.Flags().StringVar(&benchParams, "params", "{}", "tool parameters as JSON, @file (JSON or YAML) or - for stdin")
+	benchCmd.Flags().StringVar(&benchMethod, "method", "ping", "request to send without --tool: ping, tools/list, resources/list or prompts/list")
+	benchCmd.Flags().IntVarP(&benchConcurrency, "concurrency", "c", 1, "number of requests in flight at once")
+	benchCmd.Flags().IntVarP(&benchRequests, "requests", "n", 0, fmt.Sprintf("number of requests to send (default %d unless --duration is set)", bench.DefaultRequests))
+	benchCmd.Flags().DurationVarP(&benchDuration, "duration", "d", 0, "how long to send requests")
+	benchCmd.Flags().Float64Var(&benchRate, "rate", 0, "maximum requests started per second (default: no limit)")
+	benchCmd.Flags().DurationVar(&benchTimeout, "timeout", 30*time.Second, "timeout of each request")
+	benchCmd.Flags().IntVar(&benchSessions, "sessions", 1, "number of sessions the workers are spread over")
+	benchCmd.Flags().StringVarP(&benchOutput, "output", "o", "text", "report format: text or json")
+	benchCmd.MarkFlagsMutuallyExclusive("tool", "method")
+}
+
+func runBench(cmd *cobra.Command) error {
+	if benchOutput != "text" && benchOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", benchOutput)
+	}
+	if benchConcurrency < 1 || benchSessions < 1 {
+		return fmt.Errorf("--concurrency and --sessions must be at least 1")
+	}
+	if benchSessions > benchConcurrency {
+		return fmt.Errorf("--sessions cannot exceed --concurrency, as idle sessions would not be measured")
+	}
+	if benchRequests < 0 || benchDuration < 0 || benchRate < 0 {
+		return fmt.Errorf("--requests, --duration and --rate cannot be negative")
+	}
+
+	target, err := benchTarget(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	targetServer := serverName
+	if targetServer == "" {
+		targetServer = cfg.DefaultServer
+	}
+	if targetServer == "" {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+
+	// Every manager holds its own session to the server
+	clients := make([]*mcp.Client, 0, benchSessions)
+	for i := 0; i < benchSessions; i++ {
+		manager := newManager()
+		defer manager.Close()
+		if err := manager.InitializeServers(mcpConfig, []string{targetServer}); err != nil {
+			return fmt.Errorf("failed to initialize MCP servers: %w", err)
+		}
+		client, err := manager.GetClient(targetServer)
+		if err != nil {
+			return fmt.Errorf("failed to get client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	logrus.Infof("Benchmarking %s on server %s", target.Name, targetServer)
+	result := bench.Run(context.Background(), clients, target, bench.Options{
+		Concurrency: benchConcurrency,
+		Requests:    benchRequests,
+		Duration:    benchDuration,
+		Rate:        benchRate,
+		Timeout:     benchTimeout,
+	})
+
+	if benchOutput == "json" {
+		return result.WriteJSON(os.Stdout)
+	}
+	return result.WriteText(os.Stdout)
+}
+
+// benchTarget builds the request to benchmark from the flags
+func benchTarget(cmd *cobra.Command) (bench.Target, error) {
+	if benchTool != "" {
+		params, err := toolparams.Load(benchParams, os.Stdin)
+		if err != nil {
+			return bench.Target{}, fmt.Errorf("failed to parse tool parameters: %w", err)
+		}
+		return bench.CallTool(benchTool, params), nil
+	}
+	if cmd.Flags().Changed("params") {
+		return bench.Target{}, fmt.Errorf("--params requires --tool")
+	}
+
+	switch benchMethod {
+	case "ping":
+		return bench.Ping(), nil
+	case "tools/list":
+		return bench.ListTools(), nil
+	case "resources/list":
+		return bench.ListResources(), nil
+	case "prompts/list":
+		return bench.ListPrompts(), nil
+	}
+	return bench.Target{}, fmt.Errorf("invalid method %q (expected ping, tools/list, resources/list or prompts/list)", benchMethod)
+}
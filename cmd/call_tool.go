@@ -4,17 +4,33 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"os"
+	"time"
 
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/spf13/cobra"
 	"github.com/sirupsen/logrus"
 
 	"mcp_tstr/internal/config"
 	"mcp_tstr/internal/mcp"
+	toolparams "mcp_tstr/internal/params"
+	"mcp_tstr/internal/render"
+	"mcp_tstr/internal/schema"
 )
 
 var (
-	toolName   string
-	toolParams string
+	toolName       string
+	toolParams     string
+	skipValidation bool
+	toolOutput     string
+	mediaDir       string
+	imageProtocol  string
+	interactive    bool
+	toolArgs       []string
+	batchFile      string
+	concurrency    int
+	failFast       bool
 )
 
 // callToolCmd represents the call-tool command
@@ -22,11 +38,32 @@ var callToolCmd = &cobra.Command{
 	Use:   "call-tool",
 	Short: "Execute a specific tool with parameters",
 	Long: `Execute a tool provided by the MCP server with the specified parameters.
-Parameters should be provided as JSON-RPC formatted string.
+Parameters can be given as inline JSON, read from a JSON or YAML file with @file, read from
+stdin with -, and set or overridden with repeated --arg key=value flags whose values are
+converted to the types declared by the tool's input schema.
 
-Example:
-  mcp_tstr call-tool --name "get_weather" --params '{"location":"New York"}'`,
+Examples:
+  mcp_tstr call-tool --name "get_weather" --params '{"location":"New York"}'
+  mcp_tstr call-tool --name "get_weather" --params @weather.yaml --arg days=3
+  echo '{"location":"Oslo"}' | mcp_tstr call-tool --name "get_weather" --params -
+  mcp_tstr call-tool --name "get_chart" -o text
+
+With --batch, calls are read from a JSONL file and results are written as JSONL with timing
+and errors, followed by a summary on stderr:
+  mcp_tstr call-tool --batch calls.jsonl --concurrency 4 > results.jsonl`,
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if batchFile != "" {
+			if toolName != "" || interactive || cmd.Flags().Changed("params") || len(toolArgs) > 0 {
+				return fmt.Errorf("--batch cannot be combined with --name, --params, --arg or --interactive")
+			}
+			return runCallToolBatch()
+		}
+		if toolName == "" {
+			return fmt.Errorf("required flag \"name\" not set")
+		}
+		if interactive && (cmd.Flags().Changed("params") || len(toolArgs) > 0) {
+			return fmt.Errorf("--interactive cannot be combined with --params or --arg")
+		}
 		return runCallTool()
 	},
 }
@@ -34,9 +71,18 @@ Example:
 func init() {
 	rootCmd.AddCommand(callToolCmd)
 	
-	callToolCmd.Flags().StringVarP(&toolName, "name", "n", "", "tool name to execute (required)")
-	callToolCmd.Flags().StringVarP(&toolParams, "params", "p", "{}", "JSON-RPC formatted parameters")
-	_ = callToolCmd.MarkFlagRequired("name")
+	callToolCmd.Flags().StringVarP(&toolName, "name", "n", "", "tool name to execute (required unless --batch is used)")
+	callToolCmd.Flags().StringVarP(&toolOutput, "output", "o", "json", "result format: json, or text to render the content for reading")
+	callToolCmd.Flags().StringVar(&mediaDir, "media-dir", "", "directory for images and audio returned by the tool (default: a temp directory)")
+	callToolCmd.Flags().StringVar(&imageProtocol, "images", "auto", "inline image protocol: auto, none, kitty or sixel")
+	// -p is taken by the global --provider-name flag
+	callToolCmd.Flags().StringVar(&toolParams, "params", "{}", "parameters as JSON, @file (JSON or YAML) or - for stdin")
+	callToolCmd.Flags().StringArrayVar(&toolArgs, "arg", nil, "set a parameter as key=value, typed by the tool's schema (repeatable, dotted keys for nested objects)")
+	callToolCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "build the parameters by answering prompts generated from the tool's input schema")
+	callToolCmd.Flags().BoolVar(&skipValidation, "skip-validation", false, "send the parameters without checking them against the tool's input schema")
+	callToolCmd.Flags().StringVar(&batchFile, "batch", "", "run the calls in a JSONL file (one {\"server\",\"tool\",\"arguments\"} per line, - for stdin)")
+	callToolCmd.Flags().IntVar(&concurrency, "concurrency", 1, "number of batch calls run in parallel")
+	callToolCmd.Flags().BoolVar(&failFast, "fail-fast", false, "stop the batch at the first call that does not succeed")
 }
 
 func runCallTool() error {
@@ -60,14 +106,22 @@ func runCallTool() error {
 		return fmt.Errorf("no server specified and no default server configured")
 	}
 
+	if toolOutput != "text" && toolOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", toolOutput)
+	}
+	graphics, err := render.ParseGraphics(imageProtocol)
+	if err != nil {
+		return err
+	}
+
 	// Parse tool parameters
-	var params map[string]interface{}
-	if err := json.Unmarshal([]byte(toolParams), &params); err != nil {
+	params, err := toolparams.Load(toolParams, os.Stdin)
+	if err != nil {
 		return fmt.Errorf("failed to parse tool parameters: %w", err)
 	}
 
 	// Initialize MCP manager
-	manager := mcp.NewManager(logrus.StandardLogger())
+	manager := newManager()
 	defer manager.Close()
 
 	// Initialize the target server
@@ -82,6 +136,39 @@ func runCallTool() error {
 
 	ctx := context.Background()
 
+	// Look up the tool to check its input and output schemas
+	tool, err := client.FindTool(ctx, toolName)
+	if err != nil && (!skipValidation || interactive) {
+		return err
+	}
+
+	if len(toolArgs) > 0 {
+		var inputSchema *jsonschema.Schema
+		if tool != nil {
+			inputSchema = tool.InputSchema
+		}
+		if err := toolparams.ApplyArgs(params, toolArgs, inputSchema); err != nil {
+			return err
+		}
+	}
+
+	if interactive {
+		params, err = promptToolParams(tool)
+		if err != nil {
+			return err
+		}
+		if params == nil {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	if tool != nil && !skipValidation {
+		if err := mcp.ValidateToolArguments(tool, params); err != nil {
+			return fmt.Errorf("%w (use --skip-validation to send them anyway)", err)
+		}
+	}
+
 	// Execute the tool
 	logrus.WithFields(logrus.Fields{
 		"tool":   toolName,
@@ -95,5 +182,134 @@ func runCallTool() error {
 	}
 
 	// Output results
-	return outputJSON(result)
+	if err := outputToolResult(result, graphics); err != nil {
+		return err
+	}
+
+	if tool != nil {
+		return mcp.ValidateToolOutput(tool, result)
+	}
+	return nil
+}
+
+// runCallToolBatch runs every call in the batch file and prints one JSON result per line
+func runCallToolBatch() error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	defaultServer := serverName
+	if defaultServer == "" {
+		defaultServer = cfg.DefaultServer
+	}
+
+	input := os.Stdin
+	if batchFile != "-" {
+		file, err := os.Open(batchFile)
+		if err != nil {
+			return fmt.Errorf("failed to open batch file: %w", err)
+		}
+		defer file.Close()
+		input = file
+	}
+
+	calls, err := mcp.ReadBatch(input, defaultServer)
+	if err != nil {
+		return fmt.Errorf("failed to read batch file: %w", err)
+	}
+	if len(calls) == 0 {
+		return fmt.Errorf("batch file contains no calls")
+	}
+
+	// Initialize MCP manager
+	manager := newManager()
+	defer manager.Close()
+
+	if err := manager.InitializeServers(mcpConfig, mcp.BatchServers(calls)); err != nil {
+		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	summary := manager.RunBatch(context.Background(), calls, mcp.BatchOptions{
+		Concurrency: concurrency,
+		FailFast:    failFast,
+		Validate:    !skipValidation,
+	}, func(result mcp.BatchResult) {
+		if err := encoder.Encode(result); err != nil {
+			logrus.WithError(err).Error("Failed to write batch result")
+		}
+	})
+
+	fmt.Fprintf(os.Stderr, "%d calls in %s: %d ok, %d tool errors, %d failed, %d skipped\n",
+		summary.Total, summary.Duration.Round(time.Millisecond), summary.Succeeded, summary.ToolErrors, summary.Failed, summary.Skipped)
+
+	if failures := summary.Failures(); failures > 0 {
+		return fmt.Errorf("%d of %d calls did not succeed", failures, summary.Total)
+	}
+	return nil
+}
+
+// promptToolParams walks the user through the tool's input schema, confirms the resulting
+// parameters and offers to save them to a file. It returns nil if the user cancels.
+func promptToolParams(tool *sdk.Tool) (map[string]interface{}, error) {
+	prompter := schema.NewPrompter(os.Stdin, os.Stdout)
+
+	fmt.Printf("Parameters for %s", tool.Name)
+	if tool.Description != "" {
+		fmt.Printf(": %s", tool.Description)
+	}
+	fmt.Println()
+
+	params, err := prompter.Object(tool.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	pretty, err := json.MarshalIndent(params, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format parameters: %w", err)
+	}
+	fmt.Printf("\n%s\n\n", pretty)
+
+	if err := mcp.ValidateToolArguments(tool, params); err != nil {
+		fmt.Printf("Warning: %v\n", err)
+	}
+
+	proceed, err := prompter.Confirm("Call the tool with these parameters?", true)
+	if err != nil || !proceed {
+		return nil, err
+	}
+
+	path, err := prompter.Line("Save parameters to file (empty to skip): ")
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		if err := os.WriteFile(path, append(pretty, '\n'), 0o644); err != nil {
+			return nil, fmt.Errorf("failed to save parameters: %w", err)
+		}
+		fmt.Printf("Saved parameters to %s\n", path)
+	}
+
+	return params, nil
+}
+
+// outputToolResult prints a tool result as JSON or renders its content for reading. --json-raw
+// always prints compact JSON.
+func outputToolResult(result *sdk.CallToolResult, graphics render.Graphics) error {
+	if toolOutput == "json" || jsonRaw {
+		return outputJSON(result)
+	}
+
+	renderer := render.New(os.Stdout, mediaDir)
+	if imageProtocol != "auto" {
+		renderer.SetGraphics(graphics)
+	}
+	return renderer.Result(result)
 }
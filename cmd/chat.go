@@ -3,12 +3,14 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"os"
 
-	"github.com/spf13/cobra"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
 
 	"mcp_tstr/internal/chat"
 	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
 	"mcp_tstr/internal/mcp"
 	"mcp_tstr/internal/providers"
 )
@@ -27,8 +29,20 @@ The chat session will continue until you type 'bye', 'exit', 'end', or 'quit'.`,
 	},
 }
 
+var (
+	startupWorkers int
+	includeTools   []string
+	excludeTools   []string
+	toolApproval   string
+)
+
 func init() {
 	rootCmd.AddCommand(chatCmd)
+
+	chatCmd.Flags().IntVar(&startupWorkers, "startup-workers", constants.DefaultStartupWorkers, "number of MCP servers to initialize concurrently")
+	chatCmd.Flags().StringSliceVar(&includeTools, "include-tools", nil, "only offer tools matching these globs ([server:]pattern)")
+	chatCmd.Flags().StringSliceVar(&excludeTools, "exclude-tools", nil, "never offer tools matching these globs ([server:]pattern)")
+	chatCmd.Flags().StringVar(&toolApproval, "approval", "", "when to ask before running a tool: always, destructive or never (default from config)")
 }
 
 func runChat() error {
@@ -78,17 +92,38 @@ func runChat() error {
 		serverNames = []string{targetServer}
 	}
 
+	approvalSetting := toolApproval
+	if approvalSetting == "" {
+		approvalSetting = cfg.ToolApproval
+	}
+	approval, err := chat.ParseApprovalPolicy(approvalSetting)
+	if err != nil {
+		return err
+	}
+
 	// Initialize MCP manager
-	manager := mcp.NewManager(logrus.StandardLogger())
+	manager := newManager()
 	defer manager.Close()
 
+	if err := manager.AddToolFilters(includeTools, excludeTools); err != nil {
+		return fmt.Errorf("invalid tool filter: %w", err)
+	}
+
 	// Initialize MCP servers
-	if err := manager.InitializeServers(mcpConfig, serverNames); err != nil {
-		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	manager.SetStartupWorkers(startupWorkers)
+	initErr := manager.InitializeServers(mcpConfig, serverNames)
+	if report := manager.StartupReport(); len(report) > 1 {
+		_ = mcp.WriteStartupSummary(os.Stdout, report)
+		fmt.Println()
+	}
+	if initErr != nil {
+		return fmt.Errorf("failed to initialize MCP servers: %w", initErr)
 	}
 
 	// Create chat session
 	session := chat.NewSession(provider, manager)
+	session.SetApprovalPolicy(approval)
+	manager.SetStateHandler(session.HandleStateChange)
 
 	// Load available tools from MCP servers
 	ctx := context.Background()
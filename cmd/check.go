@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/report"
+)
+
+// checkStderrLimit is how much of each server's stderr is kept for failing checks
+const checkStderrLimit = 64 * 1024
+
+var (
+	checkReport     string
+	checkReportFile string
+	checkTimeout    time.Duration
+)
+
+// checkCmd represents the check command
+var checkCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Check the health of every configured MCP server",
+	Long: `Connect to every server in the MCP configuration (or only --server), ping it and list its
+tools, resources and prompts. Each step is reported as a test case with its duration, grouped
+by server, and failing cases include what the server wrote to stderr.
+
+Use --report junit, tap or json for CI systems; the command exits non-zero if any check fails.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCheck()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+	checkCmd.Flags().StringVar(&checkReport, "report", string(report.FormatText), "report format: text, junit, tap or json")
+	checkCmd.Flags().StringVar(&checkReportFile, "report-file", "", "write the report to this file instead of stdout")
+	checkCmd.Flags().DurationVar(&checkTimeout, "timeout", 10*time.Second, "timeout for each check after startup")
+}
+
+func runCheck() error {
+	format, err := report.ParseFormat(checkReport)
+	if err != nil {
+		return err
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	var servers []string
+	if serverName != "" {
+		servers = []string{serverName}
+	}
+
+	manager := newManager()
+	defer manager.Close()
+	manager.CaptureStderr(checkStderrLimit)
+
+	// Servers that fail to start are reported as failed checks rather than aborting the run
+	if err := manager.InitializeServers(mcpConfig, servers); err != nil && len(manager.StartupReport()) == 0 {
+		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	}
+
+	checks := manager.CheckServers(context.Background(), checkTimeout)
+	suites := make([]report.Suite, 0, len(checks))
+	for _, check := range checks {
+		suites = append(suites, checkSuite(check, manager.ServerStderr(check.Server)))
+	}
+
+	var out io.Writer = os.Stdout
+	if checkReportFile != "" {
+		file, err := os.Create(checkReportFile)
+		if err != nil {
+			return fmt.Errorf("failed to create report file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := report.Write(out, format, suites); err != nil {
+		return err
+	}
+
+	if totals := report.Count(suites...); totals.Failed > 0 {
+		return fmt.Errorf("%d of %d checks failed", totals.Failed, totals.Tests)
+	}
+	return nil
+}
+
+// checkSuite converts a server's checks into a report suite, attaching its stderr to failures
+func checkSuite(check mcp.ServerCheck, stderr string) report.Suite {
+	suite := report.Suite{Name: check.Server}
+	for _, step := range check.Steps {
+		c := report.Case{Name: step.Name, Duration: step.Duration, Status: report.StatusPassed, Message: step.Detail}
+		switch {
+		case step.Err != nil:
+			c.Status = report.StatusFailed
+			c.Message = step.Err.Error()
+			c.Output = stderr
+		case step.Skipped:
+			c.Status = report.StatusSkipped
+		}
+		suite.Cases = append(suite.Cases, c)
+	}
+	return suite
+}
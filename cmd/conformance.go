@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+)
+
+var (
+	conformanceOutput   string
+	conformanceTimeout  time.Duration
+	conformanceMinScore float64
+)
+
+// conformanceCmd represents the conformance command
+var conformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Check an MCP server against the protocol specification",
+	Long: `Drive a server through protocol checks with a raw JSON-RPC client: the initialize handshake
+and version negotiation, ping, errors for unknown methods and invalid params, pagination
+cursors, declared capabilities against actual behavior, notification handling and tool schema
+validity.
+
+Every finding links to the section of the spec it tests. The score weighs MUST requirements
+three times as much as SHOULD recommendations, and the command exits non-zero when the score
+is below --min-score. Supports stdio and streamable HTTP servers.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runConformance()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(conformanceCmd)
+	conformanceCmd.Flags().StringVarP(&conformanceOutput, "output", "o", "text", "report format: text or json")
+	conformanceCmd.Flags().DurationVar(&conformanceTimeout, "timeout", 10*time.Second, "timeout for each check")
+	conformanceCmd.Flags().Float64Var(&conformanceMinScore, "min-score", 100, "lowest passing score in percent")
+}
+
+func runConformance() error {
+	if conformanceOutput != "text" && conformanceOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", conformanceOutput)
+	}
+
+	// Load configurations
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	// Determine which server to use
+	targetServer := serverName
+	if targetServer == "" {
+		targetServer = cfg.DefaultServer
+	}
+	if targetServer == "" {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+	serverConfig, ok := mcpConfig.Servers[targetServer]
+	if !ok {
+		return fmt.Errorf("server %s not found in configuration", targetServer)
+	}
+
+	dial := func() (conformance.Conn, error) {
+		return dialConn(targetServer, serverConfig, nil)
+	}
+
+	report, err := conformance.Run(context.Background(), targetServer, dial, conformanceTimeout)
+	if err != nil {
+		return err
+	}
+
+	if conformanceOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if report.Score < conformanceMinScore {
+		return fmt.Errorf("conformance score %.1f%% is below %.1f%%", report.Score, conformanceMinScore)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/fuzz"
+)
+
+var (
+	fuzzToolName       string
+	fuzzSeed           int64
+	fuzzValid          int
+	fuzzTimeout        time.Duration
+	fuzzStringLength   int
+	fuzzDepth          int
+	fuzzShrinkAttempts int
+	fuzzOutput         string
+)
+
+// fuzzToolCmd represents the fuzz-tool command
+var fuzzToolCmd = &cobra.Command{
+	Use:   "fuzz-tool",
+	Short: "Call a tool with generated valid, boundary and invalid arguments",
+	Long: `Generate arguments for a tool from its input schema and call the tool with each of them:
+random valid inputs, then for the arguments and every property within them empty and huge
+strings, unicode and control characters, zero, negative and huge numbers, limits of the
+schema and one past them, empty and huge arrays, deep nesting, missing required properties,
+unknown properties and values of the wrong type.
+
+A server should answer every call with a result, a tool error or an invalid params error.
+Crashes, timeouts, disconnects and protocol errors are findings; the server is restarted
+after each one, and each is shrunk to the smallest input that still reproduces it. Inputs
+depend only on --seed, so a run can be repeated exactly. The command exits non-zero when
+there are findings. Supports stdio and streamable HTTP servers.
+
+Examples:
+  mcp_tstr fuzz-tool -s weather --name forecast
+  mcp_tstr fuzz-tool -s weather --name forecast --seed 42 --timeout 5s -o json > fuzz.json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if !cmd.Flags().Changed("seed") {
+			fuzzSeed = time.Now().UnixNano()
+		}
+		return runFuzzTool()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(fuzzToolCmd)
+	fuzzToolCmd.Flags().StringVarP(&fuzzToolName, "name", "n", "", "tool to fuzz (required)")
+	fuzzToolCmd.Flags().Int64Var(&fuzzSeed, "seed", 0, "seed of the generated inputs (default: random, printed in the report)")
+	fuzzToolCmd.Flags().IntVar(&fuzzValid, "valid", fuzz.DefaultValid, "number of random valid inputs")
+	fuzzToolCmd.Flags().DurationVar(&fuzzTimeout, "timeout", 10*time.Second, "timeout of each call; a call that takes longer is a finding")
+	fuzzToolCmd.Flags().IntVar(&fuzzStringLength, "string-length", fuzz.DefaultStringLength, "length of the huge strings")
+	fuzzToolCmd.Flags().IntVar(&fuzzDepth, "depth", fuzz.DefaultDepth, "nesting depth of the deeply nested values")
+	fuzzToolCmd.Flags().IntVar(&fuzzShrinkAttempts, "shrink-attempts", fuzz.DefaultShrinkAttempts, "most calls spent shrinking each finding")
+	fuzzToolCmd.Flags().StringVarP(&fuzzOutput, "output", "o", "text", "report format: text or json")
+	_ = fuzzToolCmd.MarkFlagRequired("name")
+}
+
+func runFuzzTool() error {
+	if fuzzOutput != "text" && fuzzOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", fuzzOutput)
+	}
+	if fuzzValid < 1 || fuzzStringLength < 1 || fuzzDepth < 1 || fuzzShrinkAttempts < 1 {
+		return fmt.Errorf("--valid, --string-length, --depth and --shrink-attempts must be at least 1")
+	}
+	if fuzzTimeout <= 0 {
+		return fmt.Errorf("--timeout must be positive")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	targetServer := serverName
+	if targetServer == "" {
+		targetServer = cfg.DefaultServer
+	}
+	if targetServer == "" {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+	serverConfig, ok := mcpConfig.Servers[targetServer]
+	if !ok {
+		return fmt.Errorf("server %s not found in configuration", targetServer)
+	}
+
+	dial := func(stderr io.Writer) (conformance.Conn, error) {
+		return dialConn(targetServer, serverConfig, stderr)
+	}
+
+	// An interrupt skips the remaining inputs and reports the ones run so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logrus.Infof("Fuzzing tool %s of server %s with seed %d", fuzzToolName, targetServer, fuzzSeed)
+	report, err := fuzz.Run(ctx, dial, fuzzToolName, fuzz.Options{
+		Seed:           fuzzSeed,
+		Valid:          fuzzValid,
+		Timeout:        fuzzTimeout,
+		StringLength:   fuzzStringLength,
+		Depth:          fuzzDepth,
+		ShrinkAttempts: fuzzShrinkAttempts,
+	}, func(result fuzz.Result) {
+		entry := logrus.WithFields(logrus.Fields{"kind": result.Kind, "outcome": result.Outcome, "duration": result.Duration.Round(time.Millisecond)})
+		if fuzz.IsFinding(result.Outcome) {
+			entry.Warnf("%s: %s", result.Description(), result.Message)
+		} else {
+			entry.Debug(result.Description())
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to fuzz tool %s: %w", fuzzToolName, err)
+	}
+
+	if fuzzOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(report.Findings) > 0 {
+		return fmt.Errorf("%d findings for tool %s (seed %d)", len(report.Findings), fuzzToolName, fuzzSeed)
+	}
+	return nil
+}
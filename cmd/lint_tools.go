@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/lint"
+)
+
+var (
+	lintOutput         string
+	lintFailOn         string
+	lintMaxDescription int
+	lintTimeout        time.Duration
+)
+
+// lintToolsCmd represents the lint-tools command
+var lintToolsCmd = &cobra.Command{
+	Use:   "lint-tools",
+	Short: "Check tool definitions for problems that confuse models or break providers",
+	Long: `List the tools of every configured server (or only --server) and check their definitions:
+invalid JSON Schemas, input schemas that are not objects, required fields missing from
+properties, untyped properties, missing or overly long descriptions, names that OpenAI
+rejects (they must match ^[a-zA-Z0-9_-]{1,64}$) and tool names listed twice or shared by
+several servers. Tools are listed with a raw JSON-RPC client, so a malformed tool or schema is
+reported on its own instead of failing the whole listing. Supports stdio and streamable HTTP
+servers.
+
+Each issue is an error, a warning or an info. The command exits non-zero when an issue is at
+least as severe as --fail-on.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runLintTools()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(lintToolsCmd)
+	lintToolsCmd.Flags().StringVarP(&lintOutput, "output", "o", "text", "report format: text or json")
+	lintToolsCmd.Flags().StringVar(&lintFailOn, "fail-on", string(lint.SeverityError), "exit non-zero on issues of this severity or worse: error, warning or info")
+	lintToolsCmd.Flags().DurationVar(&lintTimeout, "timeout", 30*time.Second, "timeout for listing the tools of each server")
+	lintToolsCmd.Flags().IntVar(&lintMaxDescription, "max-description", lint.DefaultMaxDescription, "longest acceptable description in characters")
+}
+
+func runLintTools() error {
+	if lintOutput != "text" && lintOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", lintOutput)
+	}
+	failOn, err := lint.ParseSeverity(lintFailOn)
+	if err != nil {
+		return err
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	servers := []string{serverName}
+	if serverName == "" {
+		servers = make([]string, 0, len(mcpConfig.Servers))
+		for name := range mcpConfig.Servers {
+			servers = append(servers, name)
+		}
+		sort.Strings(servers)
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no MCP servers available for use")
+	}
+
+	linter := lint.New(lint.Options{MaxDescription: lintMaxDescription})
+	failed := 0
+	for _, server := range servers {
+		serverConfig, ok := mcpConfig.Servers[server]
+		if !ok {
+			return fmt.Errorf("server %s not found in configuration", server)
+		}
+		// Servers that cannot be listed are skipped so the others are still linted
+		tools, err := listRawTools(server, serverConfig)
+		if err != nil {
+			logrus.WithError(err).Errorf("Failed to list tools of server %s", server)
+			failed++
+			continue
+		}
+		linter.AddServer(server, tools)
+	}
+
+	report := linter.Report()
+	if lintOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d servers could not be linted", failed)
+	}
+	if count := report.Count(failOn); count > 0 {
+		return fmt.Errorf("found %d issues of severity %s or worse", count, failOn)
+	}
+	return nil
+}
+
+// listRawTools lists the tools of a server over a raw connection, so that every tool can be
+// decoded and linted on its own
+func listRawTools(server string, serverConfig config.MCPServer) ([]json.RawMessage, error) {
+	conn, err := dialConn(server, serverConfig, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), lintTimeout)
+	defer cancel()
+	if err := conformance.Initialize(ctx, conn); err != nil {
+		return nil, err
+	}
+	return conformance.ListTools(ctx, conn)
+}
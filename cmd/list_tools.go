@@ -5,10 +5,8 @@ import (
 	"fmt"
 
 	"github.com/spf13/cobra"
-	"github.com/sirupsen/logrus"
 
 	"mcp_tstr/internal/config"
-	"mcp_tstr/internal/mcp"
 )
 
 // listToolsCmd represents the list-tools command
@@ -48,7 +46,7 @@ func runListTools() error {
 	}
 
 	// Initialize MCP manager
-	manager := mcp.NewManager(logrus.StandardLogger())
+	manager := newManager()
 	defer manager.Close()
 
 	// Initialize the target server
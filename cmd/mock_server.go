@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/mockserver"
+)
+
+var (
+	mockFixture   string
+	mockRecording string
+	mockTransport string
+	mockListen    string
+	mockPath      string
+	mockLatency   time.Duration
+	mockJitter    time.Duration
+	mockErrorRate float64
+	mockSeed      int64
+)
+
+// mockServerCmd represents the mock-server command
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Serve canned MCP responses from a fixture or a recording",
+	Long: `Run an MCP server that answers tools/list, tools/call, resources/*, prompts/* and ping from a
+YAML fixture (--fixture) or from a session captured with --record (--recording). Recordings with
+several servers are narrowed down with --server; the first server is used otherwise.
+
+The server speaks stdio by default, or streamable HTTP with --transport http. --latency and
+--jitter delay every response after the handshake, and --error-rate answers that share of
+requests with an internal error; --seed makes the injected jitter and errors reproducible.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runMockServer(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockServerCmd)
+	mockServerCmd.Flags().StringVar(&mockFixture, "fixture", "", "YAML fixture describing tools, resources, prompts and responses")
+	mockServerCmd.Flags().StringVar(&mockRecording, "recording", "", "session recorded with --record to answer from")
+	mockServerCmd.Flags().StringVar(&mockTransport, "transport", "stdio", "transport to serve: stdio or http")
+	mockServerCmd.Flags().StringVar(&mockListen, "listen", "localhost:8080", "address to listen on with --transport http")
+	mockServerCmd.Flags().StringVar(&mockPath, "path", "/mcp", "endpoint path with --transport http")
+	mockServerCmd.Flags().DurationVar(&mockLatency, "latency", 0, "delay added to every response (overrides the fixture)")
+	mockServerCmd.Flags().DurationVar(&mockJitter, "jitter", 0, "random extra delay of up to this much")
+	mockServerCmd.Flags().Float64Var(&mockErrorRate, "error-rate", 0, "share of requests, between 0 and 1, that fail with an internal error (overrides the fixture)")
+	mockServerCmd.Flags().Int64Var(&mockSeed, "seed", 0, "seed for injected jitter and errors (default: random)")
+	mockServerCmd.MarkFlagsMutuallyExclusive("fixture", "recording")
+	mockServerCmd.MarkFlagsOneRequired("fixture", "recording")
+}
+
+func runMockServer(cmd *cobra.Command) error {
+	if mockErrorRate < 0 || mockErrorRate > 1 {
+		return fmt.Errorf("--error-rate must be between 0 and 1")
+	}
+
+	fixture, err := loadMockFixture()
+	if err != nil {
+		return err
+	}
+
+	opts := mockserver.Options{Jitter: mockJitter, Seed: mockSeed}
+	if cmd.Flags().Changed("latency") {
+		fixture.Latency = mockLatency
+	}
+	if cmd.Flags().Changed("error-rate") {
+		fixture.ErrorRate = mockErrorRate
+	}
+	server := mockserver.New(fixture, opts)
+
+	switch mockTransport {
+	case "stdio":
+		logrus.Debug("Serving mock MCP server on stdio")
+		return server.ServeStdio(context.Background(), os.Stdin, os.Stdout)
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle(mockPath, server.HTTPHandler())
+		logrus.Infof("Serving mock MCP server at http://%s%s", mockListen, mockPath)
+		return http.ListenAndServe(mockListen, mux)
+	}
+	return fmt.Errorf("invalid transport %q (expected stdio or http)", mockTransport)
+}
+
+// loadMockFixture reads the fixture, or builds one from a recording
+func loadMockFixture() (*mockserver.Fixture, error) {
+	if mockFixture != "" {
+		return mockserver.Load(mockFixture)
+	}
+
+	file, err := os.Open(mockRecording)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	messages, err := mcp.ReadRecording(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read recording %s: %w", mockRecording, err)
+	}
+	return mockserver.FromRecording(messages, serverName)
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"mcp_tstr/internal/config"
-	"mcp_tstr/internal/mcp"
 )
 
 // pingCmd represents the ping command
@@ -49,7 +48,7 @@ func runPing() error {
 	}
 
 	// Initialize MCP manager
-	manager := mcp.NewManager(logrus.StandardLogger())
+	manager := newManager()
 	defer manager.Close()
 
 	// Initialize the target server
@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/proxy"
+)
+
+var (
+	proxyTransport string
+	proxyListen    string
+	proxyPath      string
+	proxyFaults    string
+	proxyLatency   time.Duration
+	proxyJitter    time.Duration
+	proxyDropRate  float64
+	proxySeed      int64
+	proxyQuiet     bool
+)
+
+// proxyCmd represents the proxy command
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Forward an MCP host's traffic to a configured server, injecting faults",
+	Long: `Sit between an MCP host, such as an IDE, and the server selected with --server (or the
+default server). The host connects to the proxy over stdio, or over streamable HTTP with
+--transport http, and every session is forwarded to a new connection to the server.
+
+All traffic is logged as a wire trace unless --quiet is set; --trace-file, --trace-max-bytes and
+--trace-compact shape it, and --record captures it for replay or mock-server.
+
+Faults are injected with --latency, --jitter and --drop-rate, which affect every message except
+the initialize handshake, or with a YAML --faults file whose rules delay, drop, fail or rewrite
+matching messages:
+
+  latency: 100ms
+  rules:
+    - method: tools/call
+      tool: weather
+      replace:
+        - {old: Sunny, new: Hail}
+    - method: resources/read
+      on: request
+      probability: 0.2
+      error: {message: Internal error}`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProxy(cmd)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+	proxyCmd.Flags().StringVar(&proxyTransport, "transport", "stdio", "transport to offer the host: stdio or http")
+	proxyCmd.Flags().StringVar(&proxyListen, "listen", "localhost:8080", "address to listen on with --transport http")
+	proxyCmd.Flags().StringVar(&proxyPath, "path", "/mcp", "endpoint path with --transport http")
+	proxyCmd.Flags().StringVar(&proxyFaults, "faults", "", "YAML file with the faults to inject")
+	proxyCmd.Flags().DurationVar(&proxyLatency, "latency", 0, "delay added to every message (overrides the faults file)")
+	proxyCmd.Flags().DurationVar(&proxyJitter, "jitter", 0, "random extra delay of up to this much (overrides the faults file)")
+	proxyCmd.Flags().Float64Var(&proxyDropRate, "drop-rate", 0, "share of messages, between 0 and 1, that are dropped (overrides the faults file)")
+	proxyCmd.Flags().Int64Var(&proxySeed, "seed", 0, "seed for injected jitter, drops and rule probabilities (default: random)")
+	proxyCmd.Flags().BoolVarP(&proxyQuiet, "quiet", "q", false, "do not log the forwarded traffic")
+}
+
+func runProxy(cmd *cobra.Command) error {
+	faults, err := loadProxyFaults(cmd)
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	targetServer := serverName
+	if targetServer == "" {
+		targetServer = cfg.DefaultServer
+	}
+	if targetServer == "" {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+	serverConfig, ok := mcpConfig.Servers[targetServer]
+	if !ok {
+		return fmt.Errorf("server %s not found in configuration", targetServer)
+	}
+
+	// The proxy logs its traffic by default; the log goes to stderr, so it never mixes with stdio
+	if tracer == nil && !proxyQuiet {
+		tracer = mcp.NewTracer(logrus.StandardLogger().Out, mcp.TraceOptions{MaxPayload: traceMaxBytes, Compact: traceCompact})
+	}
+	manager := newManager()
+	defer manager.Close()
+
+	dial := func() (sdkmcp.Transport, error) {
+		return manager.Transport(targetServer, serverConfig)
+	}
+	p := proxy.New(targetServer, dial, proxy.Options{Faults: *faults, Seed: proxySeed}, logrus.StandardLogger())
+
+	switch proxyTransport {
+	case "stdio":
+		logrus.Debugf("Proxying stdio to server %s", targetServer)
+		return p.Serve(context.Background(), sdkmcp.NewStdioTransport())
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle(proxyPath, p.HTTPHandler())
+		logrus.Infof("Proxying http://%s%s to server %s", proxyListen, proxyPath, targetServer)
+		return http.ListenAndServe(proxyListen, mux)
+	}
+	return fmt.Errorf("invalid transport %q (expected stdio or http)", proxyTransport)
+}
+
+// loadProxyFaults reads the --faults file and applies the fault flags on top of it
+func loadProxyFaults(cmd *cobra.Command) (*proxy.Faults, error) {
+	faults := &proxy.Faults{}
+	if proxyFaults != "" {
+		loaded, err := proxy.Load(proxyFaults)
+		if err != nil {
+			return nil, err
+		}
+		faults = loaded
+	}
+
+	if cmd.Flags().Changed("latency") {
+		faults.Latency = proxyLatency
+	}
+	if cmd.Flags().Changed("jitter") {
+		faults.Jitter = proxyJitter
+	}
+	if cmd.Flags().Changed("drop-rate") {
+		faults.DropRate = proxyDropRate
+	}
+	if err := faults.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid faults: %w", err)
+	}
+	return faults, nil
+}
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/replay"
+)
+
+var (
+	replayOutput  string
+	replayTimeout time.Duration
+	replayIgnore  []string
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay <recording.jsonl>",
+	Short: "Re-send recorded requests to live servers and diff the responses",
+	Long: `Replay a session captured with --record. The client requests and notifications of every
+recorded server (or only --server) are sent again, in order, to the server of the same name in
+the MCP configuration, and each response is compared with the recorded one.
+
+Differences are reported by path, such as result.tools[0].description. Use --ignore for values
+expected to change between runs; [*] matches any array index. The command exits non-zero when
+a response differs or a request fails. Supports stdio and streamable HTTP servers.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runReplay(args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+	replayCmd.Flags().StringVarP(&replayOutput, "output", "o", "text", "report format: text or json")
+	replayCmd.Flags().DurationVar(&replayTimeout, "timeout", 30*time.Second, "timeout for each request")
+	replayCmd.Flags().StringArrayVar(&replayIgnore, "ignore", nil, "response path to leave out of the comparison, e.g. result.serverInfo.version (repeatable)")
+}
+
+func runReplay(path string) error {
+	if replayOutput != "text" && replayOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", replayOutput)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open recording: %w", err)
+	}
+	defer file.Close()
+
+	messages, err := mcp.ReadRecording(file)
+	if err != nil {
+		return fmt.Errorf("failed to read recording %s: %w", path, err)
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	opts := replay.Options{Timeout: replayTimeout, Ignore: replayIgnore}
+	if serverName != "" {
+		opts.Servers = []string{serverName}
+	}
+
+	dial := func(server string) (conformance.Conn, error) {
+		serverConfig, ok := mcpConfig.Servers[server]
+		if !ok {
+			return nil, fmt.Errorf("server %s not found in configuration", server)
+		}
+		return dialConn(server, serverConfig, nil)
+	}
+
+	report, err := replay.Run(context.Background(), messages, dial, opts)
+	if err != nil {
+		return err
+	}
+
+	if replayOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if mismatches := report.Mismatches(); mismatches > 0 {
+		return fmt.Errorf("%d of %d replayed requests did not match the recording", mismatches, len(report.Exchanges))
+	}
+	return nil
+}
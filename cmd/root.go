@@ -12,13 +12,18 @@ import (
 )
 
 var (
-	cfgFile      string
-	serverName   string
-	providerName string
-	logLevel     string
-	useAllMCP    bool
-	logToFile    bool
-	jsonRaw      bool
+	cfgFile       string
+	serverName    string
+	providerName  string
+	logLevel      string
+	useAllMCP     bool
+	logToFile     bool
+	jsonRaw       bool
+	recordFile    string
+	traceEnabled  bool
+	traceFile     string
+	traceMaxBytes int
+	traceCompact  bool
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -28,14 +33,19 @@ var rootCmd = &cobra.Command{
 	Long: `mcp_tstr is a comprehensive CLI tool for testing Model Context Protocol (MCP) servers.
 It provides capabilities to discover server features, execute tools, and chat with AI models
 that have access to MCP server tools and resources.`,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		initLogging()
+		return initTraffic()
 	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() error {
-	return rootCmd.Execute()
+	err := rootCmd.Execute()
+	if closeErr := closeTraffic(); err == nil {
+		err = closeErr
+	}
+	return err
 }
 
 func init() {
@@ -49,6 +59,11 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&useAllMCP, "use-all-mcp", "u", false, "include all servers in chat session")
 	rootCmd.PersistentFlags().BoolVarP(&logToFile, "log-to-file", "f", false, "store logs to persistent file")
 	rootCmd.PersistentFlags().BoolVarP(&jsonRaw, "json-raw", "j", false, "turn off json formatting in discovery results")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "record every JSON-RPC message exchanged with servers to this JSONL file")
+	rootCmd.PersistentFlags().BoolVar(&traceEnabled, "trace", false, "log every JSON-RPC message exchanged with servers")
+	rootCmd.PersistentFlags().StringVar(&traceFile, "trace-file", "", "write the trace to this file instead of the log (implies --trace)")
+	rootCmd.PersistentFlags().IntVar(&traceMaxBytes, "trace-max-bytes", 2048, "truncate traced payloads longer than this many bytes (0 for no limit)")
+	rootCmd.PersistentFlags().BoolVar(&traceCompact, "trace-compact", false, "print traced payloads on one line instead of indented")
 
 	// Version flag
 	rootCmd.Flags().BoolP("version", "v", false, "show version information")
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	sdkmcp "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+	"mcp_tstr/internal/gateway"
+	"mcp_tstr/internal/mcp"
+)
+
+var (
+	serveTransport        string
+	serveListen           string
+	servePath             string
+	serveServers          []string
+	serveNaming           string
+	serveStartupWorkers   int
+	serveIncludeTools     []string
+	serveExcludeTools     []string
+	serveIncludePrompts   []string
+	serveExcludePrompts   []string
+	serveIncludeResources []string
+	serveExcludeResources []string
+)
+
+// serveCmd represents the serve command
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve every configured MCP server behind a single MCP server",
+	Long: `Run an MCP server that aggregates the tools, prompts and resources of every server in mcp.json
+(or of --servers), so a host such as an IDE only needs to configure one server. Calls are
+forwarded to the server that owns the tool, prompt or resource, reconnecting it if needed.
+
+Names are prefixed with the server name and "__" (weather__forecast) so they never collide;
+--naming auto, or tool_naming "auto" in mcp.json, only prefixes names that several servers
+share. Resources keep their URIs. When a server announces a list change, connects, fails or is
+disabled, the gateway updates its lists and notifies the host.
+
+--include-tools/--exclude-tools, --include-prompts/--exclude-prompts and
+--include-resources/--exclude-resources take globs on the server-side names, as
+[server:]pattern. The gateway speaks stdio by default, or streamable HTTP with --transport http.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runServe()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+	serveCmd.Flags().StringVar(&serveTransport, "transport", "stdio", "transport to serve: stdio or http")
+	serveCmd.Flags().StringVar(&serveListen, "listen", "localhost:8080", "address to listen on with --transport http")
+	serveCmd.Flags().StringVar(&servePath, "path", "/mcp", "endpoint path with --transport http")
+	serveCmd.Flags().StringSliceVar(&serveServers, "servers", nil, "servers to aggregate (default: all servers in mcp.json)")
+	serveCmd.Flags().StringVar(&serveNaming, "naming", "", "how names are exposed: namespace or auto (default: tool_naming from mcp.json, else namespace)")
+	serveCmd.Flags().IntVar(&serveStartupWorkers, "startup-workers", constants.DefaultStartupWorkers, "number of MCP servers to initialize concurrently")
+	serveCmd.Flags().StringSliceVar(&serveIncludeTools, "include-tools", nil, "only serve tools matching these globs ([server:]pattern)")
+	serveCmd.Flags().StringSliceVar(&serveExcludeTools, "exclude-tools", nil, "never serve tools matching these globs ([server:]pattern)")
+	serveCmd.Flags().StringSliceVar(&serveIncludePrompts, "include-prompts", nil, "only serve prompts matching these globs ([server:]pattern)")
+	serveCmd.Flags().StringSliceVar(&serveExcludePrompts, "exclude-prompts", nil, "never serve prompts matching these globs ([server:]pattern)")
+	serveCmd.Flags().StringSliceVar(&serveIncludeResources, "include-resources", nil, "only serve resources whose names match these globs ([server:]pattern)")
+	serveCmd.Flags().StringSliceVar(&serveExcludeResources, "exclude-resources", nil, "never serve resources whose names match these globs ([server:]pattern)")
+}
+
+func runServe() error {
+	if serveTransport != "stdio" && serveTransport != "http" {
+		return fmt.Errorf("invalid transport %q (expected stdio or http)", serveTransport)
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	prompts, err := mcp.ParseFilters(serveIncludePrompts, serveExcludePrompts)
+	if err != nil {
+		return fmt.Errorf("invalid prompt filter: %w", err)
+	}
+	resources, err := mcp.ParseFilters(serveIncludeResources, serveExcludeResources)
+	if err != nil {
+		return fmt.Errorf("invalid resource filter: %w", err)
+	}
+
+	naming := serveNaming
+	if naming == "" {
+		naming = mcpConfig.ToolNaming
+	}
+	if naming == "" {
+		naming = mcp.ToolNamingNamespace
+	}
+
+	manager := newManager()
+	defer manager.Close()
+
+	if err := manager.AddToolFilters(serveIncludeTools, serveExcludeTools); err != nil {
+		return fmt.Errorf("invalid tool filter: %w", err)
+	}
+	manager.SetStartupWorkers(serveStartupWorkers)
+	if err := manager.InitializeServers(mcpConfig, serveServers); err != nil {
+		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	}
+	// InitializeServers applies the naming of mcp.json, which the flag overrides
+	if err := manager.SetToolNaming(naming); err != nil {
+		return err
+	}
+
+	g := gateway.New(manager, gateway.Options{Naming: naming, Prompts: prompts, Resources: resources}, logrus.StandardLogger())
+	manager.SetListChangedHandler(g.HandleListChanged)
+	manager.SetStateHandler(g.HandleStateChange)
+
+	ctx := context.Background()
+	if err := g.Refresh(ctx); err != nil {
+		return err
+	}
+
+	// Logs go to stderr, so they never mix with the stdio transport
+	switch serveTransport {
+	case "http":
+		mux := http.NewServeMux()
+		mux.Handle(servePath, sdkmcp.NewStreamableHTTPHandler(func(*http.Request) *sdkmcp.Server { return g.Server() }, nil))
+		logrus.Infof("Serving %d MCP servers at http://%s%s", len(manager.GetAllClients()), serveListen, servePath)
+		return http.ListenAndServe(serveListen, mux)
+	default:
+		logrus.Debugf("Serving %d MCP servers on stdio", len(manager.GetAllClients()))
+		return g.Server().Run(ctx, sdkmcp.NewStdioTransport())
+	}
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/bench"
+	"mcp_tstr/internal/config"
+	toolparams "mcp_tstr/internal/params"
+	"mcp_tstr/internal/soak"
+)
+
+var (
+	soakDuration     time.Duration
+	soakInterval     time.Duration
+	soakWarmup       time.Duration
+	soakConcurrency  int
+	soakRate         float64
+	soakTimeout      time.Duration
+	soakMethods      []string
+	soakCalls        []string
+	soakPID          int
+	soakMinGrowth    float64
+	soakFailOnGrowth bool
+	soakOutput       string
+)
+
+// soakCmd represents the soak command
+var soakCmd = &cobra.Command{
+	Use:   "soak",
+	Short: "Run a long mixed workload and watch the server for leaks and latency drift",
+	Long: `Send a mixed workload to a server for --duration, taking turns between the --methods and the
+tool calls given with --call. At the end of every --interval the resident memory, CPU usage
+and open file descriptors of the server process and its children are read from /proc, along
+with the window's request count, error rate and latencies.
+
+Afterwards every metric is checked for monotonic growth over the windows after --warmup: it
+must rise in most windows (a Kendall rank correlation of at least 0.6) and grow by at least
+--min-growth between the first and last windows. With --fail-on-growth, growth makes the
+command fail. Stdio servers are sampled automatically; for an HTTP server pass its --pid.
+Interrupting the test still prints the report.
+
+Examples:
+  mcp_tstr soak -s weather --duration 4h --interval 5m --call 'forecast={"city":"Oslo"}'
+  mcp_tstr soak -s weather --duration 10m --interval 30s --methods ping,resources/list -o json`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSoak()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(soakCmd)
+	soakCmd.Flags().DurationVarP(&soakDuration, "duration", "d", time.Hour, "how long to run the workload")
+	soakCmd.Flags().DurationVar(&soakInterval, "interval", time.Minute, "length of a window; resources are sampled after every window")
+	soakCmd.Flags().DurationVar(&soakWarmup, "warmup", time.Minute, "windows starting before this are left out of the trends")
+	soakCmd.Flags().IntVarP(&soakConcurrency, "concurrency", "c", 1, "number of requests in flight at once")
+	soakCmd.Flags().Float64Var(&soakRate, "rate", 10, "maximum requests started per second (0 for no limit)")
+	soakCmd.Flags().DurationVar(&soakTimeout, "timeout", 30*time.Second, "timeout of each request")
+	soakCmd.Flags().StringSliceVar(&soakMethods, "methods", []string{"ping", "tools/list"}, "requests in the workload: ping, tools/list, resources/list or prompts/list")
+	soakCmd.Flags().StringArrayVar(&soakCalls, "call", nil, "tool call in the workload as name or name=JSON parameters (repeatable)")
+	soakCmd.Flags().IntVar(&soakPID, "pid", 0, "process to sample instead of the stdio server's own")
+	soakCmd.Flags().Float64Var(&soakMinGrowth, "min-growth", soak.DefaultMinGrowth, "smallest relative increase that counts as growth")
+	soakCmd.Flags().BoolVar(&soakFailOnGrowth, "fail-on-growth", false, "exit non-zero when a metric grows")
+	soakCmd.Flags().StringVarP(&soakOutput, "output", "o", "text", "report format: text or json")
+}
+
+func runSoak() error {
+	if soakOutput != "text" && soakOutput != "json" {
+		return fmt.Errorf("invalid output format %q (expected text or json)", soakOutput)
+	}
+	if soakDuration <= 0 || soakInterval <= 0 {
+		return fmt.Errorf("--duration and --interval must be positive")
+	}
+	if soakConcurrency < 1 {
+		return fmt.Errorf("--concurrency must be at least 1")
+	}
+	if soakRate < 0 || soakTimeout < 0 {
+		return fmt.Errorf("--rate and --timeout cannot be negative")
+	}
+
+	target, err := soakWorkload()
+	if err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	targetServer := serverName
+	if targetServer == "" {
+		targetServer = cfg.DefaultServer
+	}
+	if targetServer == "" {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+
+	manager := newManager()
+	defer manager.Close()
+	if err := manager.InitializeServers(mcpConfig, []string{targetServer}); err != nil {
+		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	}
+	client, err := manager.GetClient(targetServer)
+	if err != nil {
+		return fmt.Errorf("failed to get client: %w", err)
+	}
+
+	var pid soak.PIDFunc
+	switch {
+	case soakPID > 0:
+		pid = func() (int, error) { return soakPID, nil }
+	case client.GetConfig().Transport.Type == "stdio":
+		pid = func() (int, error) { return manager.ServerProcess(targetServer) }
+	default:
+		logrus.Warnf("Server %s is not a stdio server; pass --pid to sample its process", targetServer)
+	}
+
+	// An interrupt ends the test early, with a report of the windows so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logrus.Infof("Soaking server %s with %s for %s", targetServer, target.Name, soakDuration)
+	report := soak.Run(ctx, client, target, pid, soak.Proc{Root: "/proc"}, soak.Options{
+		Duration:    soakDuration,
+		Interval:    soakInterval,
+		Warmup:      soakWarmup,
+		Concurrency: soakConcurrency,
+		Rate:        soakRate,
+		Timeout:     soakTimeout,
+		MinGrowth:   soakMinGrowth,
+	}, func(w soak.Window) {
+		entry := logrus.WithFields(logrus.Fields{"elapsed": w.Elapsed.Round(time.Second), "requests": w.Requests, "errors": w.Errors, "p99": w.Latency.P99})
+		if p := w.Process; p != nil {
+			entry = entry.WithFields(logrus.Fields{"rss_mib": p.RSS >> 20, "cpu": fmt.Sprintf("%.1f%%", p.CPU), "fds": p.FDs})
+		}
+		entry.Info("Window finished")
+	})
+
+	if soakOutput == "json" {
+		err = report.WriteJSON(os.Stdout)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		return err
+	}
+
+	if growing := report.Growing(); soakFailOnGrowth && len(growing) > 0 {
+		metrics := make([]string, len(growing))
+		for i, t := range growing {
+			metrics[i] = t.Metric
+		}
+		return fmt.Errorf("growth detected in %s", strings.Join(metrics, ", "))
+	}
+	return nil
+}
+
+// soakWorkload builds the mixed workload from the flags
+func soakWorkload() (bench.Target, error) {
+	var targets []bench.Target
+	for _, method := range soakMethods {
+		switch method {
+		case "ping":
+			targets = append(targets, bench.Ping())
+		case "tools/list":
+			targets = append(targets, bench.ListTools())
+		case "resources/list":
+			targets = append(targets, bench.ListResources())
+		case "prompts/list":
+			targets = append(targets, bench.ListPrompts())
+		default:
+			return bench.Target{}, fmt.Errorf("invalid method %q (expected ping, tools/list, resources/list or prompts/list)", method)
+		}
+	}
+
+	for _, call := range soakCalls {
+		name, params, hasParams := strings.Cut(call, "=")
+		arguments := map[string]interface{}{}
+		if hasParams {
+			var err error
+			arguments, err = toolparams.Parse([]byte(params), false)
+			if err != nil {
+				return bench.Target{}, fmt.Errorf("invalid parameters of call %s: %w", name, err)
+			}
+		}
+		targets = append(targets, bench.CallTool(name, arguments))
+	}
+
+	if len(targets) == 0 {
+		return bench.Target{}, fmt.Errorf("the workload is empty; set --methods or --call")
+	}
+	return bench.Mixed(targets...), nil
+}
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/suite"
+)
+
+var testRun string
+
+// testCmd represents the test command
+var testCmd = &cobra.Command{
+	Use:   "test <file or directory>...",
+	Short: "Run YAML test suites against MCP servers",
+	Long: `Run declarative test suites against MCP servers and report pass/fail per case.
+
+Each suite is a YAML file whose cases are sequences of steps. A step calls a tool, reads a
+resource or gets a prompt, then checks the result with assertions: equals, contains, regex,
+a JSONPath selecting part of the result, a JSON Schema, the expected isError value and a
+maximum latency. Directories run every .yaml and .yml file they contain.
+
+Example suite:
+
+  name: weather
+  server: weather
+  cases:
+    - name: reports the temperature
+      steps:
+        - call_tool: get_forecast
+          arguments: {location: Paris}
+          expect:
+            - contains: Paris
+            - path: $.structuredContent.temperature
+              schema: {type: number}
+            - max_latency: 2s
+
+The command exits non-zero when any case fails.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runTest(args)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(testCmd)
+	testCmd.Flags().StringVar(&testRun, "run", "", "only run cases whose name matches this regular expression")
+}
+
+func runTest(paths []string) error {
+	// Load configurations
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load MCP config: %w", err)
+	}
+
+	defaultServer := serverName
+	if defaultServer == "" {
+		defaultServer = cfg.DefaultServer
+	}
+
+	suites, err := loadSuites(paths)
+	if err != nil {
+		return err
+	}
+
+	var servers []string
+	seen := make(map[string]bool)
+	for _, s := range suites {
+		for _, server := range s.Servers(defaultServer) {
+			if !seen[server] {
+				seen[server] = true
+				servers = append(servers, server)
+			}
+		}
+	}
+	if len(servers) == 0 {
+		return fmt.Errorf("no server specified and no default server configured")
+	}
+
+	// Initialize MCP manager
+	manager := newManager()
+	defer manager.Close()
+
+	if err := manager.InitializeServers(mcpConfig, servers); err != nil {
+		return fmt.Errorf("failed to initialize MCP servers: %w", err)
+	}
+
+	runner := suite.NewRunner(func(server string) (suite.Client, error) {
+		return manager.GetClient(server)
+	}, defaultServer)
+
+	start := time.Now()
+	total, failed := 0, 0
+	for _, s := range suites {
+		runner.Run(context.Background(), s, func(result suite.CaseResult) {
+			total++
+			status := "PASS"
+			if !result.Passed() {
+				failed++
+				status = "FAIL"
+			}
+			fmt.Printf("%s  %s / %s (%s)\n", status, result.Suite, result.Name, result.Duration.Round(time.Millisecond))
+			for _, failure := range result.Failures {
+				fmt.Printf("      %s\n", failure)
+			}
+		})
+	}
+
+	fmt.Printf("\n%d passed, %d failed in %s\n", total-failed, failed, time.Since(start).Round(time.Millisecond))
+	if failed > 0 {
+		return fmt.Errorf("%d of %d test cases failed", failed, total)
+	}
+	return nil
+}
+
+// loadSuites reads every suite file, keeping only the cases selected by --run
+func loadSuites(paths []string) ([]*suite.Suite, error) {
+	files, err := suite.Files(paths)
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no test suites found")
+	}
+
+	var filter *regexp.Regexp
+	if testRun != "" {
+		if filter, err = regexp.Compile(testRun); err != nil {
+			return nil, fmt.Errorf("invalid --run pattern: %w", err)
+		}
+	}
+
+	var suites []*suite.Suite
+	for _, file := range files {
+		s, err := suite.Load(file)
+		if err != nil {
+			return nil, err
+		}
+		if filter != nil {
+			cases := s.Cases[:0]
+			for _, c := range s.Cases {
+				if filter.MatchString(c.Name) {
+					cases = append(cases, c)
+				}
+			}
+			s.Cases = cases
+		}
+		if len(s.Cases) > 0 {
+			suites = append(suites, s)
+		}
+	}
+	if len(suites) == 0 {
+		return nil, fmt.Errorf("no test cases match %q", testRun)
+	}
+	return suites, nil
+}
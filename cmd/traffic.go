@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sirupsen/logrus"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/mcp"
+)
+
+var (
+	// recorder captures server traffic when --record is set
+	recorder *mcp.Recorder
+	// tracer logs server traffic when --trace or --trace-file is set
+	tracer *mcp.Tracer
+)
+
+// initTraffic opens the --record file and sets up --trace
+func initTraffic() error {
+	if recordFile != "" {
+		r, err := mcp.CreateRecorder(recordFile)
+		if err != nil {
+			return err
+		}
+		recorder = r
+		logrus.WithField("file", recordFile).Debug("Recording JSON-RPC traffic")
+	}
+
+	opts := mcp.TraceOptions{MaxPayload: traceMaxBytes, Compact: traceCompact}
+	switch {
+	case traceFile != "":
+		t, err := mcp.CreateTracer(traceFile, opts)
+		if err != nil {
+			return err
+		}
+		tracer = t
+	case traceEnabled:
+		// Without a file the trace goes wherever the logs go
+		tracer = mcp.NewTracer(logrus.StandardLogger().Out, opts)
+	}
+	return nil
+}
+
+// closeTraffic flushes and closes the --record and --trace-file files
+func closeTraffic() error {
+	var errs []error
+	if recorder != nil {
+		errs = append(errs, recorder.Close())
+	}
+	if tracer != nil {
+		errs = append(errs, tracer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// newManager creates an MCP manager that records and traces its traffic as requested
+func newManager() *mcp.Manager {
+	manager := mcp.NewManager(logrus.StandardLogger())
+	if recorder != nil {
+		manager.SetRecorder(recorder)
+	}
+	if tracer != nil {
+		manager.SetTracer(tracer)
+	}
+	return manager
+}
+
+// dialConn opens a raw JSON-RPC connection to a stdio or streamable HTTP server that records
+// and traces its traffic as requested. A stdio server's stderr goes to stderr, or is discarded if nil.
+func dialConn(server string, serverConfig config.MCPServer, stderr io.Writer) (conformance.Conn, error) {
+	var conn conformance.Conn
+	var err error
+	switch serverConfig.Transport.Type {
+	case "stdio":
+		conn, err = conformance.DialStdio(serverConfig, stderr)
+	case "http":
+		conn, err = conformance.DialHTTP(serverConfig.Transport.URL())
+	default:
+		return nil, fmt.Errorf("only stdio and http servers are supported, not %s", serverConfig.Transport.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if recorder != nil || tracer != nil {
+		conformance.Observe(conn, func(direction string, data []byte) {
+			if recorder != nil {
+				recorder.RecordRaw(server, direction, data)
+			}
+			if tracer != nil {
+				tracer.TraceRaw(server, direction, data)
+			}
+		})
+	}
+	return conn, nil
+}
@@ -0,0 +1,251 @@
+// Package bench measures the throughput, error rate and latency of requests to an MCP server
+package bench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/mcp"
+)
+
+// DefaultRequests is the number of requests sent when neither a count nor a duration is set
+const DefaultRequests = 100
+
+// Target is the request a benchmark sends over and over
+type Target struct {
+	// Name describes the request in reports, such as "tools/call weather"
+	Name string
+	// Do sends the request once and returns an error if it failed
+	Do func(ctx context.Context, client *mcp.Client) error
+}
+
+// Ping targets the ping request
+func Ping() Target {
+	return Target{Name: "ping", Do: func(ctx context.Context, client *mcp.Client) error {
+		return client.Ping(ctx)
+	}}
+}
+
+// ListTools targets the first page of tools/list
+func ListTools() Target {
+	return Target{Name: "tools/list", Do: func(ctx context.Context, client *mcp.Client) error {
+		_, err := client.ListTools(ctx)
+		return err
+	}}
+}
+
+// ListResources targets resources/list
+func ListResources() Target {
+	return Target{Name: "resources/list", Do: func(ctx context.Context, client *mcp.Client) error {
+		_, err := client.ListResources(ctx)
+		return err
+	}}
+}
+
+// ListPrompts targets prompts/list
+func ListPrompts() Target {
+	return Target{Name: "prompts/list", Do: func(ctx context.Context, client *mcp.Client) error {
+		_, err := client.ListPrompts(ctx)
+		return err
+	}}
+}
+
+// CallTool targets a tool. Results flagged as errors count as failed requests.
+func CallTool(name string, arguments map[string]interface{}) Target {
+	return Target{Name: "tools/call " + name, Do: func(ctx context.Context, client *mcp.Client) error {
+		result, err := client.CallTool(ctx, name, arguments)
+		if err != nil {
+			return err
+		}
+		if result.IsError {
+			return fmt.Errorf("tool error: %s", toolErrorText(result))
+		}
+		return nil
+	}}
+}
+
+// Mixed takes turns sending each of the targets
+func Mixed(targets ...Target) Target {
+	names := make([]string, len(targets))
+	for i, target := range targets {
+		names[i] = target.Name
+	}
+	var next atomic.Uint64
+	return Target{Name: "mixed: " + strings.Join(names, ", "), Do: func(ctx context.Context, client *mcp.Client) error {
+		target := targets[(next.Add(1)-1)%uint64(len(targets))]
+		return target.Do(ctx, client)
+	}}
+}
+
+// toolErrorText returns the text of an error result
+func toolErrorText(result *sdk.CallToolResult) string {
+	var parts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*sdk.TextContent); ok {
+			parts = append(parts, text.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Options controls how hard a benchmark pushes
+type Options struct {
+	// Concurrency is the number of requests in flight at once
+	Concurrency int
+	// Requests stops the benchmark after this many requests and Duration after this much time;
+	// with both set, whichever comes first wins
+	Requests int
+	Duration time.Duration
+	// Rate caps the requests started per second across all workers; zero means no cap
+	Rate float64
+	// Timeout bounds each request; zero means no bound
+	Timeout time.Duration
+}
+
+// sample is the outcome of one request
+type sample struct {
+	latency time.Duration
+	err     error
+}
+
+// Run sends the target to the clients until the request count or duration is reached and
+// reports the results. Workers are spread over the clients, so a single client means every
+// worker shares one session.
+func Run(ctx context.Context, clients []*mcp.Client, target Target, opts Options) *Result {
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 1
+	}
+	if opts.Requests <= 0 && opts.Duration <= 0 {
+		opts.Requests = DefaultRequests
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	start := time.Now()
+	tokens := make(chan struct{})
+	go issue(ctx, tokens, start, opts)
+
+	samples := make([][]sample, opts.Concurrency)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			client := clients[w%len(clients)]
+			for range tokens {
+				samples[w] = append(samples[w], send(ctx, client, target, opts.Timeout))
+			}
+		}(w)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	var all []sample
+	for _, s := range samples {
+		all = append(all, s...)
+	}
+	result := summarize(all, elapsed)
+	result.Target = target.Name
+	result.Sessions = len(clients)
+	result.Concurrency = opts.Concurrency
+	return result
+}
+
+// issue hands out one token per request until the count or duration is reached, pacing them
+// when there is a rate cap, then closes the channel
+func issue(ctx context.Context, tokens chan<- struct{}, start time.Time, opts Options) {
+	defer close(tokens)
+
+	var deadline <-chan time.Time
+	if opts.Duration > 0 {
+		timer := time.NewTimer(opts.Duration - time.Since(start))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var tick <-chan time.Time
+	if opts.Rate > 0 {
+		// Rates above one request per nanosecond are paced as fast as a ticker can go
+		ticker := time.NewTicker(max(time.Duration(float64(time.Second)/opts.Rate), time.Nanosecond))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for sent := 0; opts.Requests <= 0 || sent < opts.Requests; sent++ {
+		// The first request goes out at once; later ones wait for their tick
+		if tick != nil && sent > 0 {
+			select {
+			case <-tick:
+			case <-deadline:
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+		select {
+		case tokens <- struct{}{}:
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// send times one request
+func send(ctx context.Context, client *mcp.Client, target Target, timeout time.Duration) sample {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := target.Do(ctx, client)
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	return sample{latency: time.Since(start), err: err}
+}
+
+// summarize computes the statistics of the samples. Latencies only cover successful requests.
+func summarize(samples []sample, elapsed time.Duration) *Result {
+	r := &Result{Requests: len(samples), Duration: elapsed}
+
+	var latencies []time.Duration
+	errorCounts := make(map[string]int)
+	for _, s := range samples {
+		if s.err != nil {
+			r.Errors++
+			errorCounts[s.err.Error()]++
+			continue
+		}
+		latencies = append(latencies, s.latency)
+	}
+
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Errors) / float64(r.Requests)
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(r.Requests) / elapsed.Seconds()
+	}
+	for message, count := range errorCounts {
+		r.ErrorMessages = append(r.ErrorMessages, ErrorCount{Message: message, Count: count})
+	}
+	sort.Slice(r.ErrorMessages, func(i, j int) bool {
+		if r.ErrorMessages[i].Count != r.ErrorMessages[j].Count {
+			return r.ErrorMessages[i].Count > r.ErrorMessages[j].Count
+		}
+		return r.ErrorMessages[i].Message < r.ErrorMessages[j].Message
+	})
+
+	r.Latency = Summarize(latencies)
+	r.Histogram = NewHistogram(latencies)
+	return r
+}
@@ -0,0 +1,133 @@
+package bench
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/mcptest"
+)
+
+func TestRunRequests(t *testing.T) {
+	clients := []*mcp.Client{mcptest.MockClient(t), mcptest.MockClient(t)}
+	result := Run(context.Background(), clients, CallTool("echo", map[string]interface{}{"text": "hi"}), Options{Concurrency: 4, Requests: 50})
+
+	assert.Equal(t, "tools/call echo", result.Target)
+	assert.Equal(t, 2, result.Sessions)
+	assert.Equal(t, 50, result.Requests)
+	assert.Zero(t, result.Errors)
+	assert.Greater(t, result.Throughput, 0.0)
+	assert.Greater(t, result.Latency.P50, time.Duration(0))
+	assert.LessOrEqual(t, result.Latency.P50, result.Latency.P99)
+
+	counted := 0
+	for _, bucket := range result.Histogram {
+		counted += bucket.Count
+	}
+	assert.Equal(t, 50, counted)
+}
+
+func TestRunErrors(t *testing.T) {
+	client := mcptest.MockClient(t)
+
+	result := Run(context.Background(), []*mcp.Client{client}, CallTool("broken", nil), Options{Requests: 5})
+	assert.Equal(t, 5, result.Errors)
+	assert.Equal(t, 1.0, result.ErrorRate)
+	require.Len(t, result.ErrorMessages, 1)
+	assert.Equal(t, ErrorCount{Message: "tool error: out of order", Count: 5}, result.ErrorMessages[0])
+	assert.Empty(t, result.Histogram)
+
+	result = Run(context.Background(), []*mcp.Client{client}, ListPrompts(), Options{Requests: 3})
+	assert.Equal(t, 3, result.Requests)
+}
+
+func TestRunDurationAndRate(t *testing.T) {
+	client := mcptest.MockClient(t)
+
+	result := Run(context.Background(), []*mcp.Client{client}, Ping(), Options{Concurrency: 2, Duration: 300 * time.Millisecond, Rate: 20})
+	// Requests start at 0, 50, 100 ... 250ms
+	assert.InDelta(t, 6, result.Requests, 1)
+	assert.Zero(t, result.Errors)
+	assert.GreaterOrEqual(t, result.Duration, 250*time.Millisecond)
+}
+
+func TestRunRateAboveTickerResolution(t *testing.T) {
+	result := Run(context.Background(), []*mcp.Client{mcptest.MockClient(t)}, Ping(), Options{Requests: 3, Rate: 2e9})
+	assert.Equal(t, 3, result.Requests)
+	assert.Zero(t, result.Errors)
+}
+
+func TestSummarize(t *testing.T) {
+	var latencies []time.Duration
+	for i := 100; i >= 1; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	l := Summarize(latencies)
+	assert.Equal(t, time.Millisecond, l.Min)
+	assert.Equal(t, 50*time.Millisecond, l.P50)
+	assert.Equal(t, 90*time.Millisecond, l.P90)
+	assert.Equal(t, 99*time.Millisecond, l.P99)
+	assert.Equal(t, 100*time.Millisecond, l.Max)
+	assert.Equal(t, 50500*time.Microsecond, l.Mean)
+
+	assert.Equal(t, Latency{}, Summarize(nil))
+}
+
+func TestNewHistogram(t *testing.T) {
+	buckets := NewHistogram([]time.Duration{
+		900 * time.Microsecond, time.Millisecond, 1100 * time.Microsecond, 2 * time.Millisecond, 5 * time.Minute,
+	})
+
+	require.NotEmpty(t, buckets)
+	assert.Equal(t, Bucket{UpperBound: time.Millisecond, Count: 2}, buckets[0])
+	assert.Equal(t, Bucket{UpperBound: 1250 * time.Microsecond, Count: 1}, buckets[1])
+	// The slowest latency lands in the unbounded bucket at the end
+	assert.Equal(t, Bucket{Count: 1}, buckets[len(buckets)-1])
+}
+
+func TestResultOutput(t *testing.T) {
+	result := summarize([]sample{
+		{latency: 2 * time.Millisecond},
+		{latency: 4 * time.Millisecond},
+		{latency: time.Second, err: assert.AnError},
+	}, time.Second)
+	result.Target = "ping"
+
+	var buf bytes.Buffer
+	require.NoError(t, result.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Requests:    3 in 1s, 3.0 req/s")
+	assert.Contains(t, buf.String(), "Errors:      1 (33.33%)")
+	assert.Contains(t, buf.String(), "p50 2ms")
+
+	buf.Reset()
+	require.NoError(t, result.WriteJSON(&buf))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 1.0, decoded["duration_seconds"])
+	assert.Equal(t, 4.0, decoded["latency"].(map[string]interface{})["max_ms"])
+	assert.Equal(t, 2.0, decoded["histogram"].([]interface{})[0].(map[string]interface{})["le_ms"])
+}
+
+func TestMixed(t *testing.T) {
+	var calls []string
+	record := func(name string) Target {
+		return Target{Name: name, Do: func(ctx context.Context, client *mcp.Client) error {
+			calls = append(calls, name)
+			return nil
+		}}
+	}
+
+	target := Mixed(record("a"), record("b"))
+	assert.Equal(t, "mixed: a, b", target.Name)
+	for i := 0; i < 3; i++ {
+		require.NoError(t, target.Do(context.Background(), nil))
+	}
+	assert.Equal(t, []string{"a", "b", "a"}, calls)
+}
@@ -0,0 +1,217 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of a benchmark
+type Result struct {
+	Target        string        `json:"target"`
+	Sessions      int           `json:"sessions"`
+	Concurrency   int           `json:"concurrency"`
+	Requests      int           `json:"requests"`
+	Errors        int           `json:"errors"`
+	ErrorRate     float64       `json:"error_rate"`
+	Duration      time.Duration `json:"-"`
+	Throughput    float64       `json:"throughput"`
+	Latency       Latency       `json:"latency"`
+	Histogram     []Bucket      `json:"histogram"`
+	ErrorMessages []ErrorCount  `json:"error_messages,omitempty"`
+}
+
+// MarshalJSON encodes the duration in seconds, which is easier to track than nanoseconds
+func (r *Result) MarshalJSON() ([]byte, error) {
+	type plain Result
+	return json.Marshal(struct {
+		*plain
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{(*plain)(r), r.Duration.Seconds()})
+}
+
+// ErrorCount is how often a request failed with the same message
+type ErrorCount struct {
+	Message string `json:"message"`
+	Count   int    `json:"count"`
+}
+
+// Latency summarizes a set of request latencies
+type Latency struct {
+	Min  time.Duration
+	Mean time.Duration
+	P50  time.Duration
+	P90  time.Duration
+	P99  time.Duration
+	Max  time.Duration
+}
+
+// MarshalJSON encodes the latencies in milliseconds
+func (l Latency) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]float64{
+		"min_ms":  millis(l.Min),
+		"mean_ms": millis(l.Mean),
+		"p50_ms":  millis(l.P50),
+		"p90_ms":  millis(l.P90),
+		"p99_ms":  millis(l.P99),
+		"max_ms":  millis(l.Max),
+	})
+}
+
+// Summarize computes the latency statistics of the samples; nearest-rank percentiles are used
+func Summarize(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, latency := range sorted {
+		total += latency
+	}
+	percentile := func(p float64) time.Duration {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		return sorted[max(rank, 1)-1]
+	}
+	return Latency{
+		Min:  sorted[0],
+		Mean: total / time.Duration(len(sorted)),
+		P50:  percentile(50),
+		P90:  percentile(90),
+		P99:  percentile(99),
+		Max:  sorted[len(sorted)-1],
+	}
+}
+
+// Bucket counts the latencies up to an upper bound and above the previous bucket's bound. The
+// last bucket of a histogram may be unbounded, with an upper bound of zero.
+type Bucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+// MarshalJSON encodes the upper bound in milliseconds, or as null when unbounded
+func (b Bucket) MarshalJSON() ([]byte, error) {
+	var bound *float64
+	if b.UpperBound > 0 {
+		ms := millis(b.UpperBound)
+		bound = &ms
+	}
+	return json.Marshal(struct {
+		UpperBound *float64 `json:"le_ms"`
+		Count      int      `json:"count"`
+	}{bound, b.Count})
+}
+
+// bucketBounds are ten round values per decade from 100µs to 100s, so histograms of different
+// runs line up
+var bucketBounds = func() []time.Duration {
+	steps := []float64{1, 1.25, 1.5, 2, 2.5, 3, 4, 5, 6, 8}
+	var bounds []time.Duration
+	for decade := 100 * time.Microsecond; decade < 100*time.Second; decade *= 10 {
+		for _, step := range steps {
+			bounds = append(bounds, time.Duration(step*float64(decade)))
+		}
+	}
+	return append(bounds, 100*time.Second)
+}()
+
+// NewHistogram counts the latencies into buckets, leaving out empty buckets at both ends
+func NewHistogram(latencies []time.Duration) []Bucket {
+	if len(latencies) == 0 {
+		return nil
+	}
+	buckets := make([]Bucket, len(bucketBounds)+1)
+	for i, bound := range bucketBounds {
+		buckets[i].UpperBound = bound
+	}
+	for _, latency := range latencies {
+		i := sort.Search(len(bucketBounds), func(i int) bool { return bucketBounds[i] >= latency })
+		buckets[i].Count++
+	}
+
+	first, last := 0, len(buckets)-1
+	for buckets[first].Count == 0 {
+		first++
+	}
+	for buckets[last].Count == 0 {
+		last--
+	}
+	return buckets[first : last+1]
+}
+
+// WriteText writes the result as a human-readable summary with a histogram
+func (r *Result) WriteText(w io.Writer) error {
+	sessions := "1 shared session"
+	if r.Sessions > 1 {
+		sessions = fmt.Sprintf("%d sessions", r.Sessions)
+	}
+	fmt.Fprintf(w, "Target:      %s (%s, concurrency %d)\n", r.Target, sessions, r.Concurrency)
+	fmt.Fprintf(w, "Requests:    %d in %s, %.1f req/s\n", r.Requests, r.Duration.Round(time.Millisecond), r.Throughput)
+	fmt.Fprintf(w, "Errors:      %d (%.2f%%)\n", r.Errors, r.ErrorRate*100)
+	l := r.Latency
+	fmt.Fprintf(w, "Latency:     min %s  p50 %s  p90 %s  p99 %s  max %s  mean %s\n",
+		formatLatency(l.Min), formatLatency(l.P50), formatLatency(l.P90), formatLatency(l.P99), formatLatency(l.Max), formatLatency(l.Mean))
+
+	if len(r.Histogram) > 0 {
+		fmt.Fprintln(w)
+		writeHistogram(w, r.Histogram)
+	}
+
+	if len(r.ErrorMessages) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Errors by message:")
+		for _, e := range r.ErrorMessages {
+			fmt.Fprintf(w, "  %6d  %s\n", e.Count, e.Message)
+		}
+	}
+	return nil
+}
+
+// histogramWidth is the length of the longest histogram bar
+const histogramWidth = 40
+
+// writeHistogram draws one bar per bucket, scaled to the fullest bucket
+func writeHistogram(w io.Writer, buckets []Bucket) {
+	most := 0
+	for _, b := range buckets {
+		most = max(most, b.Count)
+	}
+	for _, b := range buckets {
+		label := "> " + formatLatency(bucketBounds[len(bucketBounds)-1])
+		if b.UpperBound > 0 {
+			label = "<= " + formatLatency(b.UpperBound)
+		}
+		bar := strings.Repeat("#", int(math.Ceil(float64(b.Count)/float64(most)*histogramWidth)))
+		fmt.Fprintf(w, "  %-10s %8d  %s\n", label, b.Count, bar)
+	}
+}
+
+// WriteJSON writes the result as indented JSON
+func (r *Result) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// formatLatency rounds a latency to three significant digits or so
+func formatLatency(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	default:
+		return d.Round(time.Microsecond).String()
+	}
+}
+
+// millis converts a duration to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
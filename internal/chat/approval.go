@@ -0,0 +1,166 @@
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/mcp"
+)
+
+// ApprovalPolicy decides which tool calls need confirmation from the user
+type ApprovalPolicy string
+
+const (
+	// ApprovalAlways asks before every tool call
+	ApprovalAlways ApprovalPolicy = "always"
+	// ApprovalDestructive asks before tools that may modify their environment
+	ApprovalDestructive ApprovalPolicy = "destructive"
+	// ApprovalNever runs every tool call without asking
+	ApprovalNever ApprovalPolicy = "never"
+)
+
+// errToolDenied is reported to the model when the user refuses a tool call
+var errToolDenied = errors.New("the user denied this tool call")
+
+// ParseApprovalPolicy converts a configuration value into an ApprovalPolicy
+func ParseApprovalPolicy(value string) (ApprovalPolicy, error) {
+	switch policy := ApprovalPolicy(strings.ToLower(value)); policy {
+	case ApprovalAlways, ApprovalDestructive, ApprovalNever:
+		return policy, nil
+	}
+	return "", fmt.Errorf("invalid approval policy %q (expected %s, %s or %s)", value, ApprovalAlways, ApprovalDestructive, ApprovalNever)
+}
+
+// SetApprovalPolicy sets which tool calls need confirmation from the user
+func (s *Session) SetApprovalPolicy(policy ApprovalPolicy) {
+	s.approval = policy
+}
+
+// requiresApproval applies the policy to a tool's annotations. Following the MCP spec defaults,
+// a tool without annotations is assumed to be destructive.
+func requiresApproval(policy ApprovalPolicy, tool *sdk.Tool) bool {
+	switch policy {
+	case ApprovalNever:
+		return false
+	case ApprovalDestructive:
+		return isDestructive(tool)
+	default:
+		return true
+	}
+}
+
+// isDestructive reports whether a tool may modify its environment destructively
+func isDestructive(tool *sdk.Tool) bool {
+	annotations := tool.Annotations
+	if annotations == nil {
+		return true
+	}
+	if annotations.ReadOnlyHint {
+		return false
+	}
+	return annotations.DestructiveHint == nil || *annotations.DestructiveHint
+}
+
+// approveToolCall asks the user to confirm a tool call when the policy requires it and returns
+// the arguments to call the tool with, which the user may have edited
+func (s *Session) approveToolCall(entry mcp.ToolEntry, arguments map[string]interface{}) (map[string]interface{}, error) {
+	if s.allowedTools[entry.Name] || !requiresApproval(s.approval, entry.Tool) {
+		return arguments, nil
+	}
+
+	for {
+		pretty, err := json.MarshalIndent(arguments, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("failed to format arguments: %w", err)
+		}
+
+		fmt.Println()
+		fmt.Printf("[Tool approval] server: %s  tool: %s", entry.Server, entry.Tool.Name)
+		if isDestructive(entry.Tool) {
+			fmt.Print("  (may modify data)")
+		}
+		fmt.Println()
+		fmt.Printf("Arguments:\n%s\n", pretty)
+		fmt.Print("Approve? [y]es / [n]o / [e]dit / [a]lways allow for this session: ")
+
+		if !s.input.Scan() {
+			return nil, errToolDenied
+		}
+
+		switch strings.ToLower(strings.TrimSpace(s.input.Text())) {
+		case "y", "yes":
+			return arguments, nil
+		case "a", "always":
+			s.allowedTools[entry.Name] = true
+			return arguments, nil
+		case "n", "no":
+			return nil, errToolDenied
+		case "e", "edit":
+			edited, err := editArguments(arguments)
+			if err != nil {
+				fmt.Printf("Error: %v\n", err)
+				continue
+			}
+			arguments = edited
+			if err := mcp.ValidateToolArguments(entry.Tool, arguments); err != nil {
+				fmt.Printf("Warning: %v\n", err)
+			}
+		default:
+			fmt.Println("Please answer y, n, e or a.")
+		}
+	}
+}
+
+// editArguments opens the arguments as JSON in the user's editor and parses the result
+func editArguments(arguments map[string]interface{}) (map[string]interface{}, error) {
+	file, err := os.CreateTemp("", "mcp_tstr-args-*.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	pretty, err := json.MarshalIndent(arguments, "", "  ")
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to format arguments: %w", err)
+	}
+	_, err = file.Write(append(pretty, '\n'))
+	file.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to write temporary file: %w", err)
+	}
+
+	editor := strings.Fields(editorCommand())
+	cmd := exec.Command(editor[0], append(editor[1:], file.Name())...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("editor failed: %w", err)
+	}
+
+	data, err := os.ReadFile(file.Name())
+	if err != nil {
+		return nil, fmt.Errorf("failed to read edited arguments: %w", err)
+	}
+
+	var edited map[string]interface{}
+	if err := json.Unmarshal(data, &edited); err != nil {
+		return nil, fmt.Errorf("edited arguments are not a JSON object: %w", err)
+	}
+	return edited, nil
+}
+
+// editorCommand returns the user's preferred editor
+func editorCommand() string {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if editor := strings.TrimSpace(os.Getenv(env)); editor != "" {
+			return editor
+		}
+	}
+	return "vi"
+}
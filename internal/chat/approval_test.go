@@ -0,0 +1,155 @@
+package chat
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcp"
+)
+
+func TestParseApprovalPolicy(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ApprovalPolicy
+		wantErr bool
+	}{
+		{value: "always", want: ApprovalAlways},
+		{value: "destructive", want: ApprovalDestructive},
+		{value: "never", want: ApprovalNever},
+		{value: "NEVER", want: ApprovalNever},
+		{value: "", wantErr: true},
+		{value: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			policy, err := ParseApprovalPolicy(tt.value)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestApprovalRules(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name        string
+		annotations *sdk.ToolAnnotations
+		destructive bool
+	}{
+		{name: "no annotations", annotations: nil, destructive: true},
+		{name: "empty annotations", annotations: &sdk.ToolAnnotations{}, destructive: true},
+		{name: "read only", annotations: &sdk.ToolAnnotations{ReadOnlyHint: true}, destructive: false},
+		{name: "read only wins over destructive", annotations: &sdk.ToolAnnotations{ReadOnlyHint: true, DestructiveHint: &yes}, destructive: false},
+		{name: "destructive", annotations: &sdk.ToolAnnotations{DestructiveHint: &yes}, destructive: true},
+		{name: "not destructive", annotations: &sdk.ToolAnnotations{DestructiveHint: &no}, destructive: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tool := &sdk.Tool{Name: "tool", Annotations: tt.annotations}
+			assert.Equal(t, tt.destructive, isDestructive(tool))
+			assert.True(t, requiresApproval(ApprovalAlways, tool))
+			assert.Equal(t, tt.destructive, requiresApproval(ApprovalDestructive, tool))
+			assert.False(t, requiresApproval(ApprovalNever, tool))
+		})
+	}
+}
+
+// scriptedSession returns a session that reads the given answers as user input
+func scriptedSession(policy ApprovalPolicy, answers ...string) *Session {
+	return &Session{
+		approval:     policy,
+		allowedTools: make(map[string]bool),
+		input:        bufio.NewScanner(strings.NewReader(strings.Join(answers, "\n") + "\n")),
+	}
+}
+
+// deleteEntry is a destructive tool as the tool index returns it
+var deleteEntry = mcp.ToolEntry{Name: "delete", Server: "files", Tool: &sdk.Tool{Name: "delete"}}
+
+func TestApproveToolCall(t *testing.T) {
+	arguments := map[string]interface{}{"path": "/tmp/a"}
+
+	tests := []struct {
+		name    string
+		answers []string
+		wantErr bool
+	}{
+		{name: "yes", answers: []string{"y"}},
+		{name: "no", answers: []string{"n"}, wantErr: true},
+		{name: "unknown answer asks again", answers: []string{"maybe", "yes"}},
+		{name: "end of input denies", answers: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := scriptedSession(ApprovalDestructive, tt.answers...)
+			approved, err := session.approveToolCall(deleteEntry, arguments)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, errToolDenied)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, arguments, approved)
+		})
+	}
+}
+
+func TestApproveToolCallWithoutPrompt(t *testing.T) {
+	readOnly := mcp.ToolEntry{Name: "read", Server: "files", Tool: &sdk.Tool{Name: "read", Annotations: &sdk.ToolAnnotations{ReadOnlyHint: true}}}
+
+	// No input is read, so any prompt would deny the call
+	session := scriptedSession(ApprovalDestructive)
+	_, err := session.approveToolCall(readOnly, nil)
+	assert.NoError(t, err)
+
+	session.approval = ApprovalNever
+	_, err = session.approveToolCall(deleteEntry, nil)
+	assert.NoError(t, err)
+}
+
+func TestApproveToolCallAlways(t *testing.T) {
+	session := scriptedSession(ApprovalAlways, "a")
+	arguments := map[string]interface{}{"path": "/tmp/a"}
+
+	_, err := session.approveToolCall(deleteEntry, arguments)
+	require.NoError(t, err)
+
+	// The input is used up, so the later calls only pass because the tool stays allowed
+	for i := 0; i < 2; i++ {
+		approved, err := session.approveToolCall(deleteEntry, arguments)
+		require.NoError(t, err)
+		assert.Equal(t, arguments, approved)
+	}
+
+	other := mcp.ToolEntry{Name: "move", Server: "files", Tool: &sdk.Tool{Name: "move"}}
+	_, err = session.approveToolCall(other, arguments)
+	assert.ErrorIs(t, err, errToolDenied)
+}
+
+func TestApproveToolCallEdit(t *testing.T) {
+	// The editor replaces the arguments with its own
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	script := "#!/bin/sh\nprintf '{\"path\": \"/tmp/b\"}' > \"$1\"\n"
+	require.NoError(t, os.WriteFile(editor, []byte(script), 0755))
+	t.Setenv("VISUAL", editor)
+
+	session := scriptedSession(ApprovalAlways, "e", "y")
+	approved, err := session.approveToolCall(deleteEntry, map[string]interface{}{"path": "/tmp/a"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"path": "/tmp/b"}, approved)
+
+	// An edit can still be refused
+	session = scriptedSession(ApprovalAlways, "e", "n")
+	_, err = session.approveToolCall(deleteEntry, map[string]interface{}{"path": "/tmp/a"})
+	assert.ErrorIs(t, err, errToolDenied)
+}
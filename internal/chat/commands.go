@@ -0,0 +1,196 @@
+package chat
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"unicode/utf8"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/providers"
+)
+
+// isCommand checks if the input is a chat slash command
+func isCommand(input string) bool {
+	return strings.HasPrefix(input, "/")
+}
+
+// handleCommand runs a chat slash command
+func (s *Session) handleCommand(input string) error {
+	fields := strings.Fields(input)
+	command, args := fields[0], fields[1:]
+
+	switch command {
+	case "/help":
+		s.printHelp()
+		return nil
+	case "/servers":
+		return s.printServers()
+	case "/connect":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: /connect <server>")
+		}
+		return s.connectServer(args[0])
+	case "/disconnect":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: /disconnect <server>")
+		}
+		return s.disconnectServer(args[0])
+	case "/reload":
+		return s.reloadServers(args)
+	case "/attach":
+		if len(args) == 0 {
+			return fmt.Errorf("usage: /attach <file>")
+		}
+		// File names may contain spaces
+		return s.attachFile(strings.TrimSpace(strings.TrimPrefix(input, command)))
+	default:
+		return fmt.Errorf("unknown command %s, type /help for a list of commands", command)
+	}
+}
+
+// printHelp lists the available slash commands
+func (s *Session) printHelp() {
+	fmt.Println("Commands:")
+	fmt.Println("  /servers              list MCP servers and their connection state")
+	fmt.Println("  /connect <server>     connect a server from mcp.json or retry a failed one")
+	fmt.Println("  /disconnect <server>  disconnect a server (use /connect to bring it back)")
+	fmt.Println("  /reload [server...]   re-read mcp.json and restart servers")
+	fmt.Println("  /attach <file>        send an image or text file with your next message")
+	fmt.Println("  /help                 show this help")
+}
+
+// printServers prints every server known to the manager
+func (s *Session) printServers() error {
+	servers := s.mcpManager.Servers()
+	if len(servers) == 0 {
+		fmt.Println("No MCP servers connected")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tTRANSPORT\tSTATE")
+	for _, server := range servers {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", server.Name, server.Transport, server.State)
+	}
+	return tw.Flush()
+}
+
+// connectServer connects a server, re-enabling it if it was disconnected during this session
+func (s *Session) connectServer(name string) error {
+	for _, server := range s.mcpManager.Servers() {
+		if server.Name != name {
+			continue
+		}
+		var err error
+		switch {
+		case !server.Enabled:
+			err = s.mcpManager.EnableServer(name)
+		case server.State == mcp.StateFailed:
+			// The server gave up reconnecting on its own, so try again from scratch
+			err = s.mcpManager.RetryServer(name)
+		case server.State == mcp.StateConnected:
+			return fmt.Errorf("server %s is already connected", name)
+		default:
+			return fmt.Errorf("server %s is %s", name, server.State)
+		}
+		if err != nil {
+			return err
+		}
+		s.serversChanged("Connected", name)
+		return nil
+	}
+
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return err
+	}
+	serverConfig, exists := mcpConfig.Servers[name]
+	if !exists {
+		return fmt.Errorf("server %s not found in configuration", name)
+	}
+
+	if err := s.mcpManager.AddServer(name, serverConfig); err != nil {
+		return err
+	}
+	s.serversChanged("Connected", name)
+	return nil
+}
+
+// disconnectServer disables a server for the rest of the session
+func (s *Session) disconnectServer(name string) error {
+	if err := s.mcpManager.DisableServer(name); err != nil {
+		return err
+	}
+	s.serversChanged("Disconnected", name)
+	return nil
+}
+
+// reloadServers re-reads mcp.json and restarts the given servers, or every known server if none are given
+func (s *Session) reloadServers(names []string) error {
+	mcpConfig, err := config.LoadMCPConfig()
+	if err != nil {
+		return err
+	}
+
+	if len(names) == 0 {
+		for _, server := range s.mcpManager.Servers() {
+			names = append(names, server.Name)
+		}
+	}
+
+	for _, name := range names {
+		serverConfig, exists := mcpConfig.Servers[name]
+		if !exists {
+			fmt.Printf("Server %s is no longer in %s, disconnecting\n", name, constants.MCPConfigFileName)
+			if err := s.mcpManager.RemoveServer(name); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			continue
+		}
+		if err := s.mcpManager.ReloadServer(name, serverConfig); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			continue
+		}
+		fmt.Printf("Reloaded server %s\n", name)
+	}
+
+	return nil
+}
+
+// attachFile queues an image or text file to be sent with the next user message
+func (s *Session) attachFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	mimeType := mime.TypeByExtension(filepath.Ext(path))
+	if mimeType == "" {
+		mimeType = http.DetectContentType(data)
+	}
+	mediaType, _, _ := mime.ParseMediaType(mimeType)
+
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		s.attachments = append(s.attachments, providers.ImagePart(mediaType, data))
+	case strings.HasPrefix(mediaType, "text/") || utf8.Valid(data):
+		s.attachments = append(s.attachments, providers.TextPart(fmt.Sprintf("Contents of %s:\n%s", filepath.Base(path), data)))
+	default:
+		return fmt.Errorf("cannot attach %s: only images and text files are supported", path)
+	}
+
+	fmt.Printf("Attached %s (%s, %d bytes); it will be sent with your next message\n", filepath.Base(path), mediaType, len(data))
+	return nil
+}
+
+// serversChanged reports a server change
+func (s *Session) serversChanged(action, name string) {
+	fmt.Printf("%s server %s\n", action, name)
+}
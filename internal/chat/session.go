@@ -3,26 +3,38 @@ package chat
 import (
 	"bufio"
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/sirupsen/logrus"
 
 	"mcp_tstr/internal/mcp"
 	"mcp_tstr/internal/providers"
+	"mcp_tstr/internal/render"
 )
 
 // Session represents a chat session
 type Session struct {
-	provider    providers.Provider
-	mcpManager  *mcp.Manager
-	messages    []providers.Message
-	tools       []providers.Tool
+	provider     providers.Provider
+	mcpManager   *mcp.Manager
+	messages     []providers.Message
+	tools        []providers.Tool
 	systemPrompt string
-	logger      *logrus.Entry
+	toolsVersion uint64
+	approval     ApprovalPolicy
+	allowedTools map[string]bool
+	input        *bufio.Scanner
+	renderer     *render.Renderer
+	attachments  []providers.ContentPart
+	logger       *logrus.Entry
 }
 
+// maxToolRounds bounds how many times the model may call tools in answer to a single message
+const maxToolRounds = 10
+
 // NewSession creates a new chat session
 func NewSession(provider providers.Provider, mcpManager *mcp.Manager) *Session {
 	return &Session{
@@ -33,7 +45,11 @@ func NewSession(provider providers.Provider, mcpManager *mcp.Manager) *Session {
 		systemPrompt: `You are a helpful AI assistant with access to various tools through MCP (Model Context Protocol) servers. 
 You can use these tools to help users with their requests. When you need to use a tool, make sure to call it with the appropriate parameters.
 Be helpful, accurate, and explain what you're doing when using tools.`,
-		logger: logrus.WithField("component", "chat"),
+		approval:     ApprovalDestructive,
+		allowedTools: make(map[string]bool),
+		input:        bufio.NewScanner(os.Stdin),
+		renderer:     render.New(os.Stdout, ""),
+		logger:       logrus.WithField("component", "chat"),
 	}
 }
 
@@ -44,56 +60,64 @@ func (s *Session) SetSystemPrompt(prompt string) {
 
 // LoadTools loads available tools from MCP servers
 func (s *Session) LoadTools(ctx context.Context) error {
-	s.tools = make([]providers.Tool, 0)
+	s.toolsVersion = s.mcpManager.ToolsVersion()
 
-	for _, client := range s.mcpManager.GetAllClients() {
-		toolsResult, err := client.ListTools(ctx)
-		if err != nil {
-			s.logger.WithError(err).Warnf("Failed to load tools from server %s", client.GetName())
+	entries, err := s.mcpManager.Tools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load tools: %w", err)
+	}
+
+	s.tools = make([]providers.Tool, 0, len(entries))
+	for _, entry := range entries {
+		if !s.mcpManager.ToolAllowed(entry.Server, entry.Tool.Name) {
 			continue
 		}
 
-		for _, tool := range toolsResult.Tools {
-			// Convert schema to map if available
-			var parameters map[string]interface{}
-			if tool.InputSchema != nil {
-				// For now, we'll create a simple representation
-				// In a full implementation, you'd properly convert the JSON schema
-				parameters = map[string]interface{}{
-					"type": "object",
-					"description": "Tool parameters",
-				}
-			}
-
-			s.tools = append(s.tools, providers.Tool{
-				Name:        tool.Name,
-				Description: tool.Description,
-				Parameters:  parameters,
-			})
+		parameters, err := toolParameters(entry.Tool)
+		if err != nil {
+			s.logger.WithError(err).Warnf("Failed to convert input schema of tool %s", entry.Name)
 		}
 
-		s.logger.Infof("Loaded %d tools from server %s", len(toolsResult.Tools), client.GetName())
+		s.tools = append(s.tools, providers.Tool{
+			Name:        entry.Name,
+			Description: entry.Tool.Description,
+			Parameters:  parameters,
+		})
 	}
 
 	s.logger.Infof("Total tools available: %d", len(s.tools))
 	return nil
 }
 
+// HandleStateChange reports server connection changes to the user
+func (s *Session) HandleStateChange(change mcp.StateChange) {
+	switch change.State {
+	case mcp.StateDisconnected:
+		fmt.Printf("\n[Server %s disconnected: %v]\n", change.Server, change.Err)
+	case mcp.StateReconnecting:
+		fmt.Printf("\n[Reconnecting to server %s in %s (attempt %d/%d)]\n",
+			change.Server, change.Delay, change.Attempt, change.MaxAttempts)
+	case mcp.StateConnected:
+		fmt.Printf("\n[Server %s reconnected, %d tools available]\n", change.Server, change.Tools)
+	case mcp.StateFailed:
+		fmt.Printf("\n[Server %s is unavailable: %v]\n", change.Server, change.Err)
+	}
+}
+
 // Start starts an interactive chat session
 func (s *Session) Start(ctx context.Context) error {
 	fmt.Println("Starting chat session. Type 'bye', 'exit', 'end', or 'quit' to end the session.")
+	fmt.Println("Type /help for session commands.")
 	fmt.Println("Available tools:", len(s.tools))
 	fmt.Println()
 
-	scanner := bufio.NewScanner(os.Stdin)
-
 	for {
 		fmt.Print("You: ")
-		if !scanner.Scan() {
+		if !s.input.Scan() {
 			break
 		}
 
-		input := strings.TrimSpace(scanner.Text())
+		input := strings.TrimSpace(s.input.Text())
 		if input == "" {
 			continue
 		}
@@ -104,11 +128,21 @@ func (s *Session) Start(ctx context.Context) error {
 			break
 		}
 
-		// Add user message
+		// Handle session commands
+		if isCommand(input) {
+			if err := s.handleCommand(input); err != nil {
+				fmt.Printf("Error: %v\n", err)
+			}
+			continue
+		}
+
+		// Add user message, with any files attached since the last one
 		s.messages = append(s.messages, providers.Message{
 			Role:    "user",
 			Content: input,
+			Parts:   s.attachments,
 		})
+		s.attachments = nil
 
 		// Send chat request
 		if err := s.processMessage(ctx); err != nil {
@@ -117,11 +151,48 @@ func (s *Session) Start(ctx context.Context) error {
 		}
 	}
 
-	return scanner.Err()
+	return s.input.Err()
 }
 
-// processMessage processes a user message and generates a response
+// processMessage processes a user message and generates a response, running tool calls and
+// feeding their results back to the model until it answers without calling a tool
 func (s *Session) processMessage(ctx context.Context) error {
+	// Pick up tool changes from servers that were restarted, added or changed since the last message
+	if s.mcpManager.ToolsVersion() != s.toolsVersion {
+		if err := s.LoadTools(ctx); err != nil {
+			s.logger.WithError(err).Warn("Failed to reload tools")
+		}
+	}
+
+	for round := 0; round < maxToolRounds; round++ {
+		content, toolCalls, err := s.streamResponse(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Add assistant response to conversation history
+		if content != "" || len(toolCalls) > 0 {
+			message := providers.Message{Role: "assistant", Content: content}
+			for _, toolCall := range toolCalls {
+				message.Parts = append(message.Parts, providers.ToolUsePart(toolCall))
+			}
+			s.messages = append(s.messages, message)
+		}
+
+		if len(toolCalls) == 0 {
+			return nil
+		}
+
+		for _, toolCall := range toolCalls {
+			s.messages = append(s.messages, s.runToolCall(ctx, toolCall))
+		}
+	}
+
+	return fmt.Errorf("stopped after %d rounds of tool calls", maxToolRounds)
+}
+
+// streamResponse sends the conversation to the provider and prints the streamed answer
+func (s *Session) streamResponse(ctx context.Context) (string, []providers.ToolCall, error) {
 	request := &providers.ChatRequest{
 		Messages:     s.messages,
 		Tools:        s.tools,
@@ -132,30 +203,22 @@ func (s *Session) processMessage(ctx context.Context) error {
 	// Use streaming for better user experience
 	responseChan, err := s.provider.ChatStream(ctx, request)
 	if err != nil {
-		return fmt.Errorf("failed to start chat stream: %w", err)
+		return "", nil, fmt.Errorf("failed to start chat stream: %w", err)
 	}
 
 	fmt.Print("Assistant: ")
 	var fullResponse strings.Builder
+	var toolCalls []providers.ToolCall
 
 	for response := range responseChan {
 		if response.Error != "" {
-			return fmt.Errorf("chat error: %s", response.Error)
+			return "", nil, fmt.Errorf("chat error: %s", response.Error)
 		}
 
 		// Print streaming content
 		fmt.Print(response.Content)
 		fullResponse.WriteString(response.Content)
-
-		// Handle tool calls if present
-		if len(response.ToolCalls) > 0 {
-			for _, toolCall := range response.ToolCalls {
-				if err := s.handleToolCall(ctx, toolCall); err != nil {
-					s.logger.WithError(err).Errorf("Failed to handle tool call: %s", toolCall.Name)
-					fmt.Printf("\n[Tool call failed: %v]", err)
-				}
-			}
-		}
+		toolCalls = append(toolCalls, response.ToolCalls...)
 
 		if response.Finished {
 			break
@@ -164,61 +227,126 @@ func (s *Session) processMessage(ctx context.Context) error {
 
 	fmt.Println() // New line after response
 
-	// Add assistant response to conversation history
-	if fullResponse.Len() > 0 {
-		s.messages = append(s.messages, providers.Message{
-			Role:    "assistant",
-			Content: fullResponse.String(),
-		})
+	return fullResponse.String(), toolCalls, nil
+}
+
+// runToolCall executes a tool call and turns the outcome, including failures, into a tool
+// message so the model can see what happened
+func (s *Session) runToolCall(ctx context.Context, toolCall providers.ToolCall) providers.Message {
+	toolResult := providers.ToolResult{ID: toolCall.ID, Name: toolCall.Name}
+	var images []providers.ContentPart
+
+	result, err := s.handleToolCall(ctx, toolCall)
+	if err != nil {
+		s.logger.WithError(err).Errorf("Failed to handle tool call: %s", toolCall.Name)
+		fmt.Printf("[Tool call failed: %v]\n", err)
+		toolResult.Error = err.Error()
+	} else {
+		var text string
+		text, images = toolResultContent(result)
+		if result.IsError {
+			toolResult.Error = text
+		} else {
+			toolResult.Content = text
+		}
 	}
 
-	return nil
+	return providers.Message{
+		Role:  "tool",
+		Parts: append([]providers.ContentPart{providers.ToolResultPart(toolResult)}, images...),
+	}
+}
+
+// toolParameters converts a tool's input schema into the generic form providers send to models
+func toolParameters(tool *sdk.Tool) (map[string]interface{}, error) {
+	if tool.InputSchema == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(tool.InputSchema)
+	if err != nil {
+		return nil, err
+	}
+	var parameters map[string]interface{}
+	if err := json.Unmarshal(data, &parameters); err != nil {
+		return nil, err
+	}
+	return parameters, nil
 }
 
 // handleToolCall executes a tool call through the appropriate MCP server
-func (s *Session) handleToolCall(ctx context.Context, toolCall providers.ToolCall) error {
+func (s *Session) handleToolCall(ctx context.Context, toolCall providers.ToolCall) (*sdk.CallToolResult, error) {
 	s.logger.WithFields(logrus.Fields{
 		"tool": toolCall.Name,
 		"args": toolCall.Arguments,
 	}).Info("Executing tool call")
 
 	// Find which server has this tool
-	var targetClient *mcp.Client
-	for _, client := range s.mcpManager.GetAllClients() {
-		toolsResult, err := client.ListTools(ctx)
-		if err != nil {
-			continue
-		}
+	targetClient, entry, err := s.mcpManager.ResolveTool(ctx, toolCall.Name)
+	if err != nil {
+		return nil, err
+	}
 
-		for _, tool := range toolsResult.Tools {
-			if tool.Name == toolCall.Name {
-				targetClient = client
-				break
-			}
-		}
+	// The model may name a filtered tool even though it was never offered
+	if !s.mcpManager.ToolAllowed(entry.Server, entry.Tool.Name) {
+		return nil, fmt.Errorf("tool %s is not allowed by the tool filters", toolCall.Name)
+	}
 
-		if targetClient != nil {
-			break
-		}
+	// Invalid arguments go back to the model so it can correct them
+	if err := mcp.ValidateToolArguments(entry.Tool, toolCall.Arguments); err != nil {
+		return nil, err
 	}
 
-	if targetClient == nil {
-		return fmt.Errorf("tool %s not found in any connected server", toolCall.Name)
+	arguments, err := s.approveToolCall(entry, toolCall.Arguments)
+	if err != nil {
+		return nil, err
 	}
 
 	// Execute the tool
-	result, err := targetClient.CallTool(ctx, toolCall.Name, toolCall.Arguments)
+	result, err := targetClient.CallTool(ctx, entry.Tool.Name, arguments)
 	if err != nil {
-		return fmt.Errorf("failed to call tool %s: %w", toolCall.Name, err)
+		return nil, fmt.Errorf("failed to call tool %s: %w", toolCall.Name, err)
 	}
 
 	// Display tool result
-	fmt.Printf("\n[Tool %s executed successfully]", toolCall.Name)
-	if len(result.Content) > 0 {
-		fmt.Printf("\n[Result: %v]", result.Content)
+	if !result.IsError {
+		fmt.Printf("[Tool %s executed successfully]\n", toolCall.Name)
+	}
+	if err := s.renderer.Result(result); err != nil {
+		s.logger.WithError(err).Warn("Failed to display tool result")
+	}
+	if err := mcp.ValidateToolOutput(entry.Tool, result); err != nil {
+		s.logger.WithError(err).Warn("Tool returned invalid structured content")
+		fmt.Printf("[Warning: %v]\n", err)
 	}
 
-	return nil
+	return result, nil
+}
+
+// toolResultContent flattens a tool result into the text sent back to the model, and collects
+// the images it returned so vision models can see them
+func toolResultContent(result *sdk.CallToolResult) (string, []providers.ContentPart) {
+	var texts []string
+	var images []providers.ContentPart
+	for _, content := range result.Content {
+		switch c := content.(type) {
+		case *sdk.ImageContent:
+			images = append(images, providers.ImagePart(c.MIMEType, c.Data))
+		case *sdk.EmbeddedResource:
+			if c.Resource != nil && c.Resource.Blob != nil && strings.HasPrefix(c.Resource.MIMEType, "image/") {
+				images = append(images, providers.ImagePart(c.Resource.MIMEType, c.Resource.Blob))
+			}
+		}
+		texts = append(texts, render.PlainText(content))
+	}
+
+	// Structured content is the machine-readable form of the result, so pass it on as JSON
+	if result.StructuredContent != nil {
+		if data, err := json.Marshal(result.StructuredContent); err == nil {
+			texts = append(texts, "Structured content: "+string(data))
+		}
+	}
+
+	return strings.Join(texts, "\n"), images
 }
 
 // isExitCommand checks if the input is an exit command
@@ -15,6 +15,7 @@ type Config struct {
 	DefaultServer   string                 `yaml:"default_server" mapstructure:"default_server"`
 	DefaultProvider string                 `yaml:"default_provider" mapstructure:"default_provider"`
 	DefaultModel    string                 `yaml:"default_model" mapstructure:"default_model"`
+	ToolApproval    string                 `yaml:"tool_approval" mapstructure:"tool_approval"` // always, destructive or never
 	Providers       map[string]interface{} `yaml:"providers" mapstructure:"providers"`
 	Logging         LoggingConfig          `yaml:"logging" mapstructure:"logging"`
 }
@@ -70,12 +71,17 @@ type AnthropicConfig struct {
 
 // MCPServer represents an MCP server configuration
 type MCPServer struct {
-	Name      string                 `json:"name"`
-	Command   []string               `json:"command,omitempty"`
-	Args      []string               `json:"args,omitempty"`
-	Env       map[string]string      `json:"env,omitempty"`
-	Transport MCPTransport           `json:"transport"`
-	Extra     map[string]interface{} `json:"extra,omitempty"`
+	Name           string                 `json:"name"`
+	Command        []string               `json:"command,omitempty"`
+	Args           []string               `json:"args,omitempty"`
+	Env            map[string]string      `json:"env,omitempty"`
+	Transport      MCPTransport           `json:"transport"`
+	StartupTimeout string                 `json:"startup_timeout,omitempty"` // e.g. "10s"
+	ToolPrefix     string                 `json:"tool_prefix,omitempty"`
+	IncludeTools   []string               `json:"include_tools,omitempty"` // glob patterns
+	ExcludeTools   []string               `json:"exclude_tools,omitempty"` // glob patterns
+	Reconnect      *MCPReconnect          `json:"reconnect,omitempty"`
+	Extra          map[string]interface{} `json:"extra,omitempty"`
 }
 
 // MCPTransport represents the transport configuration for an MCP server
@@ -86,9 +92,23 @@ type MCPTransport struct {
 	Path string `json:"path,omitempty"`
 }
 
+// URL returns the endpoint of an HTTP or SSE transport
+func (t MCPTransport) URL() string {
+	return fmt.Sprintf("http://%s:%d%s", t.Host, t.Port, t.Path)
+}
+
+// MCPReconnect controls how a server whose connection drops is restarted
+type MCPReconnect struct {
+	Disabled       bool   `json:"disabled,omitempty"`
+	MaxRetries     int    `json:"max_retries,omitempty"`
+	InitialBackoff string `json:"initial_backoff,omitempty"` // e.g. "500ms"
+	MaxBackoff     string `json:"max_backoff,omitempty"`     // e.g. "30s"
+}
+
 // MCPConfig represents the MCP servers configuration
 type MCPConfig struct {
-	Servers map[string]MCPServer `json:"servers"`
+	Servers    map[string]MCPServer `json:"servers"`
+	ToolNaming string               `json:"tool_naming,omitempty"` // "auto" or "namespace"
 }
 
 // Load loads the application configuration
@@ -99,6 +119,7 @@ func Load() (*Config, error) {
 	viper.SetDefault("default_server", "")
 	viper.SetDefault("default_provider", constants.DefaultProvider)
 	viper.SetDefault("default_model", constants.DefaultModel)
+	viper.SetDefault("tool_approval", constants.DefaultToolApproval)
 	viper.SetDefault("logging.level", constants.DefaultLogLevel)
 	viper.SetDefault("logging.to_file", false)
 
@@ -96,6 +96,11 @@ func TestMCPServerTransportTypes(t *testing.T) {
 	}
 }
 
+func TestMCPTransportURL(t *testing.T) {
+	transport := MCPTransport{Type: "http", Host: "localhost", Port: 8080, Path: "/mcp"}
+	assert.Equal(t, "http://localhost:8080/mcp", transport.URL())
+}
+
 func TestConfigDefaults(t *testing.T) {
 	config := &Config{
 		DefaultProvider: "ollama",
@@ -0,0 +1,439 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+
+	"mcp_tstr/internal/constants"
+	"mcp_tstr/internal/jsonrpc"
+)
+
+// jsonRPCSpec is the JSON-RPC 2.0 error object section MCP builds on
+const jsonRPCSpec = "https://www.jsonrpc.org/specification#error_object"
+
+// maxPages bounds how many pages a listing is followed for, in case cursors never end
+const maxPages = 100
+
+// Check IDs that other checks depend on
+const checkInitialize = "initialize"
+
+// check is one conformance test
+type check struct {
+	id    string
+	title string
+	level Level
+	// spec is a path below the spec version, or a full URL
+	spec string
+	run  func(ctx context.Context, s *session) (Status, string)
+}
+
+// initializeResult is what the server answered to initialize
+type initializeResult struct {
+	ProtocolVersion string                     `json:"protocolVersion"`
+	Capabilities    map[string]json.RawMessage `json:"capabilities"`
+	ServerInfo      *struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	} `json:"serverInfo"`
+}
+
+// session is the state checks share
+type session struct {
+	conn    Conn
+	dial    Dialer
+	timeout time.Duration
+	init    *initializeResult
+	// tools holds the tool definitions gathered while checking pagination
+	tools []map[string]interface{}
+}
+
+// checks run in this order; later checks rely on the session set up by earlier ones
+var checks = []check{
+	{checkInitialize, "initialize returns protocolVersion, capabilities and serverInfo", LevelMust, "basic/lifecycle#initialization", checkInitializeHandshake},
+	{"version-supported", "negotiated protocol version is a published revision", LevelMust, "basic/lifecycle#version-negotiation", checkVersionSupported},
+	{"version-negotiation", "an unsupported requested version is answered with a supported one", LevelMust, "basic/lifecycle#version-negotiation", checkVersionNegotiation},
+	{"ping", "ping is answered with an empty result", LevelMust, "basic/utilities/ping", checkPing},
+	{"unknown-method", "unknown methods fail with -32601 (method not found)", LevelMust, jsonRPCSpec, checkUnknownMethod},
+	{"invalid-params", "malformed params fail with -32602 (invalid params)", LevelShould, jsonRPCSpec, checkInvalidParams},
+	{"capability-tools", "tools/list works if and only if tools are declared", LevelMust, "basic/lifecycle#capability-negotiation", capabilityCheck("tools", "tools/list", nil)},
+	{"capability-resources", "resources/list works if and only if resources are declared", LevelMust, "basic/lifecycle#capability-negotiation", capabilityCheck("resources", "resources/list", nil)},
+	{"capability-prompts", "prompts/list works if and only if prompts are declared", LevelMust, "basic/lifecycle#capability-negotiation", capabilityCheck("prompts", "prompts/list", nil)},
+	{"capability-logging", "logging/setLevel works if and only if logging is declared", LevelMust, "basic/lifecycle#capability-negotiation", capabilityCheck("logging", "logging/setLevel", map[string]string{"level": "info"})},
+	{"pagination", "list cursors are strings, end, and never repeat items", LevelMust, "server/utilities/pagination#response-format", checkPagination},
+	{"invalid-cursor", "an invalid cursor fails with -32602", LevelShould, "server/utilities/pagination#error-handling", checkInvalidCursor},
+	{"unknown-tool", "calling an unknown tool fails with -32602", LevelShould, "server/tools#error-handling", unknownCheck("tools", "tools/call", map[string]interface{}{"name": "mcp_tstr_no_such_tool", "arguments": map[string]interface{}{}}, jsonrpc.CodeInvalidParams)},
+	{"unknown-prompt", "getting an unknown prompt fails with -32602", LevelShould, "server/prompts#error-handling", unknownCheck("prompts", "prompts/get", map[string]interface{}{"name": "mcp_tstr_no_such_prompt"}, jsonrpc.CodeInvalidParams)},
+	{"unknown-resource", "reading an unknown resource fails with -32002", LevelShould, "server/resources#error-handling", unknownCheck("resources", "resources/read", map[string]interface{}{"uri": "mcp-tstr://no/such/resource"}, jsonrpc.CodeResourceNotFound)},
+	{"notifications", "notifications get no response and do not disturb the session", LevelMust, "basic#notifications", checkNotifications},
+	{"tool-schemas", "tools have unique names and object input/output schemas", LevelMust, "server/tools#tool", checkToolSchemas},
+	{"jsonrpc-framing", "every server message is well-formed JSON-RPC 2.0", LevelMust, "basic#messages", checkFraming},
+}
+
+// pass, fail and skip build check outcomes
+func pass() (Status, string) { return StatusPass, "" }
+
+func fail(format string, args ...interface{}) (Status, string) {
+	return StatusFail, fmt.Sprintf(format, args...)
+}
+
+func skip(reason string) (Status, string) { return StatusSkip, reason }
+
+// initializeParams builds an initialize request for a protocol version
+func initializeParams(version string) map[string]interface{} {
+	return map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]string{"name": constants.AppName, "version": constants.AppVersion},
+	}
+}
+
+// versionSetter is implemented by transports that send the negotiated version with each request
+type versionSetter interface {
+	SetProtocolVersion(version string)
+}
+
+func checkInitializeHandshake(ctx context.Context, s *session) (Status, string) {
+	resp, err := s.conn.Call(ctx, "initialize", initializeParams(jsonrpc.SpecVersion))
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.Error != nil {
+		return fail("initialize failed: %v", resp.Error)
+	}
+
+	var result initializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return fail("initialize result is not an object: %v", err)
+	}
+	var problems []string
+	if result.ProtocolVersion == "" {
+		problems = append(problems, "missing protocolVersion")
+	}
+	if result.Capabilities == nil {
+		problems = append(problems, "missing capabilities")
+	}
+	if result.ServerInfo == nil || result.ServerInfo.Name == "" || result.ServerInfo.Version == "" {
+		problems = append(problems, "serverInfo needs a name and a version")
+	}
+
+	// Carry on with the other checks even if the result is incomplete
+	s.init = &result
+	if setter, ok := s.conn.(versionSetter); ok {
+		setter.SetProtocolVersion(result.ProtocolVersion)
+	}
+	if err := s.conn.Notify(ctx, "notifications/initialized", nil); err != nil {
+		problems = append(problems, fmt.Sprintf("sending notifications/initialized failed: %v", err))
+	}
+
+	if len(problems) > 0 {
+		return fail("%s", strings.Join(problems, "; "))
+	}
+	return pass()
+}
+
+func checkVersionSupported(ctx context.Context, s *session) (Status, string) {
+	if !slices.Contains(jsonrpc.KnownVersions, s.init.ProtocolVersion) {
+		return fail("server chose %q, which is not one of %s", s.init.ProtocolVersion, strings.Join(jsonrpc.KnownVersions, ", "))
+	}
+	return pass()
+}
+
+func checkVersionNegotiation(ctx context.Context, s *session) (Status, string) {
+	const unsupported = "1999-01-01"
+
+	conn, err := s.dial()
+	if err != nil {
+		return skip(fmt.Sprintf("could not open a second connection: %v", err))
+	}
+	defer conn.Close()
+
+	resp, err := conn.Call(ctx, "initialize", initializeParams(unsupported))
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.Error != nil {
+		return fail("server answered with an error instead of proposing a version: %v", resp.Error)
+	}
+	var result initializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return fail("initialize result is not an object: %v", err)
+	}
+	if result.ProtocolVersion == unsupported {
+		return fail("server accepted the made-up version %s", unsupported)
+	}
+	if !slices.Contains(jsonrpc.KnownVersions, result.ProtocolVersion) {
+		return fail("server proposed %q, which is not a published version", result.ProtocolVersion)
+	}
+	return pass()
+}
+
+func checkPing(ctx context.Context, s *session) (Status, string) {
+	resp, err := s.conn.Call(ctx, "ping", nil)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.Error != nil {
+		return fail("ping failed: %v", resp.Error)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(resp.Result, &result); err != nil || result == nil {
+		return fail("ping result %s is not an object", resp.Result)
+	}
+	return pass()
+}
+
+func checkUnknownMethod(ctx context.Context, s *session) (Status, string) {
+	return expectError(ctx, s.conn, "mcp_tstr/no_such_method", map[string]interface{}{}, jsonrpc.CodeMethodNotFound)
+}
+
+func checkInvalidParams(ctx context.Context, s *session) (Status, string) {
+	for _, capability := range []struct{ name, method string }{
+		{"tools", "tools/call"},
+		{"prompts", "prompts/get"},
+		{"resources", "resources/read"},
+	} {
+		if s.declares(capability.name) {
+			return expectError(ctx, s.conn, capability.method, "not an object", jsonrpc.CodeInvalidParams)
+		}
+	}
+	return skip("server declares no tools, prompts or resources")
+}
+
+// capabilityCheck checks that a method works exactly when its capability is declared
+func capabilityCheck(capability, method string, params interface{}) func(context.Context, *session) (Status, string) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	return func(ctx context.Context, s *session) (Status, string) {
+		resp, err := s.conn.Call(ctx, method, params)
+		if err != nil {
+			return fail("%v", err)
+		}
+
+		declared := s.declares(capability)
+		switch {
+		case declared && resp.Error != nil:
+			return fail("%s is declared but %s failed: %v", capability, method, resp.Error)
+		case !declared && resp.Error == nil:
+			return fail("%s succeeded although the %s capability is not declared", method, capability)
+		case !declared && resp.Error.Code != jsonrpc.CodeMethodNotFound:
+			return fail("%s without the %s capability should fail with %d, got %v", method, capability, jsonrpc.CodeMethodNotFound, resp.Error)
+		}
+		return pass()
+	}
+}
+
+// listings are the paginated list methods and the key their items are identified by
+var listings = []struct{ capability, method, field, key string }{
+	{"tools", "tools/list", "tools", "name"},
+	{"resources", "resources/list", "resources", "uri"},
+	{"prompts", "prompts/list", "prompts", "name"},
+}
+
+func checkPagination(ctx context.Context, s *session) (Status, string) {
+	checked := 0
+	for _, listing := range listings {
+		if !s.declares(listing.capability) {
+			continue
+		}
+		checked++
+		items, problem := s.listAll(ctx, listing.method, listing.field, listing.key)
+		if problem != "" {
+			return fail("%s: %s", listing.method, problem)
+		}
+		if listing.capability == "tools" {
+			s.tools = items
+		}
+	}
+	if checked == 0 {
+		return skip("server declares no tools, resources or prompts")
+	}
+	return pass()
+}
+
+// listAll follows nextCursor through every page, reporting malformed cursors, cursor loops
+// and duplicated items
+func (s *session) listAll(ctx context.Context, method, field, key string) ([]map[string]interface{}, string) {
+	var items []map[string]interface{}
+	seenItems := make(map[string]bool)
+	seenCursors := make(map[string]bool)
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		resp, err := s.conn.Call(ctx, method, params)
+		if err != nil {
+			return nil, err.Error()
+		}
+		if resp.Error != nil {
+			return nil, fmt.Sprintf("page %d failed: %v", page+1, resp.Error)
+		}
+
+		var result map[string]json.RawMessage
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return nil, fmt.Sprintf("result is not an object: %v", err)
+		}
+		var pageItems []map[string]interface{}
+		if err := json.Unmarshal(result[field], &pageItems); err != nil {
+			return nil, fmt.Sprintf("%q is not an array of objects", field)
+		}
+		for _, item := range pageItems {
+			id := fmt.Sprint(item[key])
+			if seenItems[id] {
+				return nil, fmt.Sprintf("%s %q is listed twice", key, id)
+			}
+			seenItems[id] = true
+			items = append(items, item)
+		}
+
+		next, ok := result["nextCursor"]
+		if !ok || string(next) == "null" {
+			return items, ""
+		}
+		if err := json.Unmarshal(next, &cursor); err != nil {
+			return nil, fmt.Sprintf("nextCursor %s is not a string", next)
+		}
+		if seenCursors[cursor] {
+			return nil, fmt.Sprintf("nextCursor %q repeats an earlier cursor", cursor)
+		}
+		seenCursors[cursor] = true
+	}
+	return nil, fmt.Sprintf("still returning nextCursor after %d pages", maxPages)
+}
+
+func checkInvalidCursor(ctx context.Context, s *session) (Status, string) {
+	for _, listing := range listings {
+		if s.declares(listing.capability) {
+			return expectError(ctx, s.conn, listing.method, map[string]interface{}{"cursor": "mcp_tstr-invalid-cursor"}, jsonrpc.CodeInvalidParams)
+		}
+	}
+	return skip("server declares no tools, resources or prompts")
+}
+
+// unknownCheck checks that a request for something that does not exist fails with code
+func unknownCheck(capability, method string, params interface{}, code int) func(context.Context, *session) (Status, string) {
+	return func(ctx context.Context, s *session) (Status, string) {
+		if !s.declares(capability) {
+			return skip(fmt.Sprintf("server does not declare %s", capability))
+		}
+		return expectError(ctx, s.conn, method, params, code)
+	}
+}
+
+func checkNotifications(ctx context.Context, s *session) (Status, string) {
+	before := len(s.conn.Violations())
+
+	if err := s.conn.Notify(ctx, "notifications/mcp_tstr/unknown", map[string]interface{}{}); err != nil {
+		return fail("%v", err)
+	}
+	if err := s.conn.Notify(ctx, "notifications/cancelled", map[string]interface{}{"requestId": 999999, "reason": "conformance check"}); err != nil {
+		return fail("%v", err)
+	}
+
+	resp, err := s.conn.Call(ctx, "ping", nil)
+	if err != nil {
+		return fail("session stopped working after notifications: %v", err)
+	}
+	if resp.Error != nil {
+		return fail("ping after notifications failed: %v", resp.Error)
+	}
+	if violations := s.conn.Violations(); len(violations) > before {
+		return fail("server responded to a notification: %s", violations[before])
+	}
+	return pass()
+}
+
+func checkToolSchemas(ctx context.Context, s *session) (Status, string) {
+	if !s.declares("tools") {
+		return skip("server does not declare tools")
+	}
+	if s.tools == nil {
+		return skip("tools could not be listed")
+	}
+
+	var problems []string
+	names := make(map[string]bool)
+	for i, tool := range s.tools {
+		name, _ := tool["name"].(string)
+		label := fmt.Sprintf("tool %q", name)
+		if name == "" {
+			label = fmt.Sprintf("tool #%d", i+1)
+			problems = append(problems, label+": missing name")
+		} else if names[name] {
+			problems = append(problems, label+": duplicate name")
+		}
+		names[name] = true
+
+		if _, ok := tool["inputSchema"]; !ok {
+			problems = append(problems, label+": missing inputSchema")
+		} else if problem := objectSchemaProblem(tool["inputSchema"]); problem != "" {
+			problems = append(problems, label+": inputSchema "+problem)
+		}
+		if outputSchema, ok := tool["outputSchema"]; ok {
+			if problem := objectSchemaProblem(outputSchema); problem != "" {
+				problems = append(problems, label+": outputSchema "+problem)
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return fail("%s", strings.Join(problems, "; "))
+	}
+	return pass()
+}
+
+// objectSchemaProblem describes why a value is not a JSON Schema for objects, or returns ""
+func objectSchemaProblem(value interface{}) string {
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return "is not an object"
+	}
+	if object["type"] != "object" {
+		return fmt.Sprintf("must have type \"object\", got %v", object["type"])
+	}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return err.Error()
+	}
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return fmt.Sprintf("is not a valid JSON Schema: %v", err)
+	}
+	return ""
+}
+
+func checkFraming(ctx context.Context, s *session) (Status, string) {
+	if violations := s.conn.Violations(); len(violations) > 0 {
+		return fail("%s", strings.Join(violations, "; "))
+	}
+	return pass()
+}
+
+// expectError sends a request that must fail with the given code
+func expectError(ctx context.Context, conn Conn, method string, params interface{}, code int) (Status, string) {
+	resp, err := conn.Call(ctx, method, params)
+	if err != nil {
+		return fail("%v", err)
+	}
+	if resp.Error == nil {
+		return fail("%s succeeded with %s, expected error %d", method, truncate(resp.Result), code)
+	}
+	if resp.Error.Code != code {
+		return fail("%s failed with %d (%s), expected %d", method, resp.Error.Code, resp.Error.Message, code)
+	}
+	return pass()
+}
+
+// declares reports whether the server declared a capability during initialization
+func (s *session) declares(capability string) bool {
+	_, ok := s.init.Capabilities[capability]
+	return ok
+}
@@ -0,0 +1,156 @@
+// Package conformance drives an MCP server through protocol checks and scores how closely it
+// follows the specification
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mcp_tstr/internal/jsonrpc"
+)
+
+// Level is how strongly the spec requires a behavior
+type Level string
+
+const (
+	// LevelMust is an absolute requirement of the spec
+	LevelMust Level = "MUST"
+	// LevelShould is a recommendation of the spec
+	LevelShould Level = "SHOULD"
+)
+
+// weight is how much a check of this level counts towards the score
+func (l Level) weight() int {
+	if l == LevelMust {
+		return 3
+	}
+	return 1
+}
+
+// Status is the outcome of a check
+type Status string
+
+// Check outcomes
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Finding is the outcome of one check with the part of the spec it tests
+type Finding struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Level   Level  `json:"level"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+	Spec    string `json:"spec"`
+}
+
+// Report is the scored result of a conformance run
+type Report struct {
+	Server          string    `json:"server"`
+	ProtocolVersion string    `json:"protocol_version,omitempty"`
+	Score           float64   `json:"score"`
+	Passed          int       `json:"passed"`
+	Failed          int       `json:"failed"`
+	Skipped         int       `json:"skipped"`
+	Findings        []Finding `json:"findings"`
+}
+
+// Dialer opens a new connection to the server under test
+type Dialer func() (Conn, error)
+
+// Run connects to a server and runs every check in order, giving each its own timeout. The
+// score is the weighted share of passed checks among those that applied, where MUST checks
+// weigh three times as much as SHOULD checks.
+func Run(ctx context.Context, server string, dial Dialer, timeout time.Duration) (*Report, error) {
+	conn, err := dial()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server %s: %w", server, err)
+	}
+	defer conn.Close()
+
+	s := &session{conn: conn, dial: dial, timeout: timeout}
+	report := &Report{Server: server}
+	for _, c := range checks {
+		finding := Finding{ID: c.id, Title: c.title, Level: c.level, Spec: specURL(c.spec)}
+		if c.id != checkInitialize && s.init == nil {
+			finding.Status, finding.Message = StatusSkip, "initialize failed"
+		} else {
+			checkCtx, cancel := context.WithTimeout(ctx, timeout)
+			finding.Status, finding.Message = c.run(checkCtx, s)
+			cancel()
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+
+	if s.init != nil {
+		report.ProtocolVersion = s.init.ProtocolVersion
+	}
+	report.score()
+	return report, nil
+}
+
+// score counts the findings and computes the weighted score
+func (r *Report) score() {
+	earned, possible := 0, 0
+	for _, finding := range r.Findings {
+		switch finding.Status {
+		case StatusPass:
+			r.Passed++
+			earned += finding.Level.weight()
+			possible += finding.Level.weight()
+		case StatusFail:
+			r.Failed++
+			possible += finding.Level.weight()
+		default:
+			r.Skipped++
+		}
+	}
+	if possible > 0 {
+		r.Score = float64(earned) * 100 / float64(possible)
+	}
+}
+
+// WriteText writes the report for people, with the message and spec reference of each failure
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Conformance of server %s", r.Server)
+	if r.ProtocolVersion != "" {
+		fmt.Fprintf(w, " (protocol %s)", r.ProtocolVersion)
+	}
+	fmt.Fprintf(w, ", checked against spec %s\n\n", jsonrpc.SpecVersion)
+
+	for _, finding := range r.Findings {
+		label := map[Status]string{StatusPass: "PASS", StatusFail: "FAIL", StatusSkip: "SKIP"}[finding.Status]
+		fmt.Fprintf(w, "%s  %-6s  %-26s %s\n", label, finding.Level, finding.ID, finding.Title)
+		if finding.Status != StatusPass && finding.Message != "" {
+			fmt.Fprintf(w, "      %s\n", finding.Message)
+		}
+		if finding.Status == StatusFail {
+			fmt.Fprintf(w, "      spec: %s\n", finding.Spec)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nScore: %.1f%% (%d passed, %d failed, %d skipped)\n", r.Score, r.Passed, r.Failed, r.Skipped)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// specURL links to a section of the spec; references starting with http are used as is
+func specURL(ref string) string {
+	if strings.HasPrefix(ref, "http") {
+		return ref
+	}
+	return fmt.Sprintf("https://modelcontextprotocol.io/specification/%s/%s", jsonrpc.SpecVersion, ref)
+}
@@ -0,0 +1,186 @@
+package conformance
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/jsonrpc"
+)
+
+// The test binary doubles as the server under test: an SDK server or a deliberately broken one
+const serverEnv = "MCP_TSTR_CONFORMANCE_SERVER"
+
+func TestMain(m *testing.M) {
+	switch os.Getenv(serverEnv) {
+	case "sdk":
+		if err := sdkServer().Run(context.Background(), mcp.NewStdioTransport()); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "broken":
+		runBrokenServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+type echoParams struct {
+	Message string `json:"message"`
+}
+
+// sdkServer is a well-behaved server built with the SDK
+func sdkServer() *mcp.Server {
+	server := mcp.NewServer("sdk", "0.0.1", nil)
+	server.AddTools(mcp.NewServerTool("echo", "Echo a message", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[echoParams]) (*mcp.CallToolResultFor[any], error) {
+		return &mcp.CallToolResultFor[any]{Content: []mcp.Content{&mcp.TextContent{Text: params.Arguments.Message}}}, nil
+	}))
+	return server
+}
+
+// runBrokenServer speaks line-delimited JSON-RPC on stdio and gets many details wrong
+func runBrokenServer() {
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		var msg message
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			continue
+		}
+		if len(msg.ID) == 0 {
+			// Answer notifications, which must never get a response
+			fmt.Println(`{"jsonrpc":"2.0","id":null,"result":{}}`)
+			continue
+		}
+
+		switch msg.Method {
+		case "initialize":
+			var params struct {
+				ProtocolVersion string `json:"protocolVersion"`
+			}
+			_ = json.Unmarshal(msg.Params, &params)
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,"result":{"protocolVersion":%q,"capabilities":{"tools":{}},"serverInfo":{"name":"broken"}}}`+"\n", msg.ID, params.ProtocolVersion)
+		case "ping":
+			fmt.Printf(`{"id":%s,"error":{"code":-32603,"message":"no"}}`+"\n", msg.ID)
+		case "tools/list":
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,"result":{"tools":[{"name":"a","inputSchema":{"type":"string"}},{"inputSchema":{"type":"object"}}]}}`+"\n", msg.ID)
+		default:
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,"error":{"code":-32600,"message":"bad"}}`+"\n", msg.ID)
+		}
+	}
+}
+
+// stdioDialer starts the test binary as the given kind of server
+func stdioDialer(t *testing.T, kind string) Dialer {
+	t.Helper()
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	serverConfig := config.MCPServer{
+		Command:   []string{executable},
+		Env:       map[string]string{serverEnv: kind},
+		Transport: config.MCPTransport{Type: "stdio"},
+	}
+	return func() (Conn, error) {
+		return DialStdio(serverConfig, nil)
+	}
+}
+
+// statuses maps check IDs to their outcome
+func statuses(report *Report) map[string]Status {
+	result := make(map[string]Status, len(report.Findings))
+	for _, finding := range report.Findings {
+		result[finding.ID] = finding.Status
+	}
+	return result
+}
+
+// assertSDKConformance checks the requirements the SDK server is known to meet
+func assertSDKConformance(t *testing.T, report *Report) {
+	t.Helper()
+	got := statuses(report)
+	for _, id := range []string{
+		"initialize", "version-supported", "version-negotiation", "ping", "unknown-method",
+		"capability-tools", "pagination", "invalid-cursor", "notifications", "tool-schemas", "jsonrpc-framing",
+	} {
+		assert.Equal(t, StatusPass, got[id], id)
+	}
+	assert.Contains(t, jsonrpc.KnownVersions, report.ProtocolVersion)
+	assert.Len(t, report.Findings, len(checks))
+	assert.Greater(t, report.Score, 50.0)
+}
+
+func TestRunStdioSDKServer(t *testing.T) {
+	report, err := Run(context.Background(), "sdk", stdioDialer(t, "sdk"), 5*time.Second)
+	require.NoError(t, err)
+	assertSDKConformance(t, report)
+}
+
+func TestRunHTTPSDKServer(t *testing.T) {
+	server := sdkServer()
+	httpServer := httptest.NewServer(mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return server }, nil))
+	defer httpServer.Close()
+
+	report, err := Run(context.Background(), "sdk", func() (Conn, error) {
+		return DialHTTP(httpServer.URL)
+	}, 5*time.Second)
+	require.NoError(t, err)
+	assertSDKConformance(t, report)
+}
+
+func TestRunBrokenServer(t *testing.T) {
+	report, err := Run(context.Background(), "broken", stdioDialer(t, "broken"), 5*time.Second)
+	require.NoError(t, err)
+
+	got := statuses(report)
+	for _, id := range []string{"initialize", "version-negotiation", "ping", "unknown-method", "notifications", "tool-schemas", "jsonrpc-framing"} {
+		assert.Equal(t, StatusFail, got[id], id)
+	}
+	assert.Equal(t, StatusPass, got["version-supported"])
+	assert.Equal(t, StatusSkip, got["unknown-prompt"])
+
+	messages := make(map[string]string)
+	for _, finding := range report.Findings {
+		messages[finding.ID] = finding.Message
+		assert.True(t, strings.HasPrefix(finding.Spec, "https://"), finding.Spec)
+	}
+	assert.Equal(t, "serverInfo needs a name and a version", messages["initialize"])
+	assert.Equal(t, "server accepted the made-up version 1999-01-01", messages["version-negotiation"])
+	assert.Equal(t, `tool "a": inputSchema must have type "object", got string; tool #2: missing name`, messages["tool-schemas"])
+	assert.Contains(t, messages["jsonrpc-framing"], `message without "jsonrpc": "2.0"`)
+	assert.Less(t, report.Score, 50.0)
+}
+
+func TestRunInitializeFailure(t *testing.T) {
+	report, err := Run(context.Background(), "gone", func() (Conn, error) {
+		return nil, fmt.Errorf("refused")
+	}, time.Second)
+	assert.Nil(t, report)
+	assert.EqualError(t, err, "failed to connect to server gone: refused")
+}
+
+func TestReportScore(t *testing.T) {
+	report := &Report{Findings: []Finding{
+		{Level: LevelMust, Status: StatusPass},
+		{Level: LevelMust, Status: StatusFail},
+		{Level: LevelShould, Status: StatusPass},
+		{Level: LevelShould, Status: StatusSkip},
+	}}
+	report.score()
+
+	assert.Equal(t, 2, report.Passed)
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, 1, report.Skipped)
+	assert.InDelta(t, 4.0*100/7, report.Score, 0.001)
+}
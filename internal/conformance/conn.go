@@ -0,0 +1,281 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcp"
+)
+
+// Response is the server's answer to a request: exactly one of Result and Error is set
+type Response struct {
+	Result json.RawMessage
+	Error  *jsonrpc.Error
+}
+
+// message is any JSON-RPC message on the wire
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// Conn is a raw JSON-RPC connection to a server. Unlike the SDK client it sends exactly what it
+// is asked to, so malformed and out-of-order requests can be tested.
+type Conn interface {
+	// Call sends a request and waits for its response. params may be any JSON value, including
+	// ones the spec does not allow.
+	Call(ctx context.Context, method string, params interface{}) (*Response, error)
+	// Notify sends a notification
+	Notify(ctx context.Context, method string, params interface{}) error
+	// Violations returns the JSON-RPC framing problems seen in server messages so far
+	Violations() []string
+	// Notifications returns the methods of the notifications the server has sent so far
+	Notifications() []string
+	Close() error
+}
+
+// Observer is called with every message sent or received on a connection, in its wire form.
+// The direction is mcp.DirectionSend or mcp.DirectionReceive.
+type Observer func(direction string, data []byte)
+
+// Observe passes every later message of a connection opened by DialStdio or DialHTTP to an
+// observer, such as a recorder or tracer
+func Observe(conn Conn, observe Observer) {
+	if p, ok := conn.(interface{ setObserver(Observer) }); ok {
+		p.setObserver(observe)
+	}
+}
+
+// peer matches responses to requests and answers requests from the server. Transports feed it
+// every message they receive and give it a function to send messages.
+type peer struct {
+	transmit func(ctx context.Context, data []byte) error
+
+	mu            sync.Mutex
+	observe       Observer
+	nextID        int
+	pending       map[string]chan *message
+	abandoned     map[string]bool
+	violations    []string
+	notifications []string
+	closed        error
+}
+
+func newPeer(send func(ctx context.Context, data []byte) error) *peer {
+	return &peer{transmit: send, nextID: 1, pending: make(map[string]chan *message), abandoned: make(map[string]bool)}
+}
+
+// setObserver sets the observer of every later message
+func (p *peer) setObserver(observe Observer) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observe = observe
+}
+
+// notify passes a message to the observer, if there is one
+func (p *peer) notify(direction string, data []byte) {
+	p.mu.Lock()
+	observe := p.observe
+	p.mu.Unlock()
+	if observe != nil {
+		observe(direction, data)
+	}
+}
+
+// send observes a message and sends it
+func (p *peer) send(ctx context.Context, data []byte) error {
+	p.notify(mcp.DirectionSend, data)
+	return p.transmit(ctx, data)
+}
+
+// Call sends a request with the next integer ID and waits for the matching response
+func (p *peer) Call(ctx context.Context, method string, params interface{}) (*Response, error) {
+	p.mu.Lock()
+	if p.closed != nil {
+		p.mu.Unlock()
+		return nil, p.closed
+	}
+	id := strconv.Itoa(p.nextID)
+	p.nextID++
+	reply := make(chan *message, 1)
+	p.pending[id] = reply
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+
+	data, err := encodeMessage(json.RawMessage(id), method, params)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.send(ctx, data); err != nil {
+		return nil, fmt.Errorf("failed to send %s: %w", method, err)
+	}
+
+	select {
+	case msg, ok := <-reply:
+		if !ok {
+			return nil, p.closedErr()
+		}
+		return &Response{Result: msg.Result, Error: msg.Error}, nil
+	case <-ctx.Done():
+		// A late response is dropped quietly instead of being reported as unknown
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.abandoned[id] = true
+		p.mu.Unlock()
+		return nil, fmt.Errorf("no response to %s: %w", method, ctx.Err())
+	}
+}
+
+// Notify sends a notification, which has no ID and gets no response
+func (p *peer) Notify(ctx context.Context, method string, params interface{}) error {
+	data, err := encodeMessage(nil, method, params)
+	if err != nil {
+		return err
+	}
+	if err := p.send(ctx, data); err != nil {
+		return fmt.Errorf("failed to send %s: %w", method, err)
+	}
+	return nil
+}
+
+// Violations returns the framing problems seen so far
+func (p *peer) Violations() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.violations...)
+}
+
+// Notifications returns the notification methods received so far
+func (p *peer) Notifications() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.notifications...)
+}
+
+// receive handles one message from the server
+func (p *peer) receive(data []byte) {
+	p.notify(mcp.DirectionReceive, data)
+	var msg message
+	if err := json.Unmarshal(data, &msg); err != nil {
+		p.violation(fmt.Sprintf("server sent invalid JSON: %v", err))
+		return
+	}
+	if msg.JSONRPC != "2.0" {
+		p.violation(fmt.Sprintf("message without \"jsonrpc\": \"2.0\": %s", truncate(data)))
+	}
+
+	switch {
+	case msg.Method != "" && len(msg.ID) > 0:
+		p.answer(msg)
+	case msg.Method != "":
+		p.mu.Lock()
+		p.notifications = append(p.notifications, msg.Method)
+		p.mu.Unlock()
+	default:
+		if msg.Result != nil && msg.Error != nil {
+			p.violation(fmt.Sprintf("response has both result and error: %s", truncate(data)))
+		}
+		if msg.Result == nil && msg.Error == nil {
+			p.violation(fmt.Sprintf("response has neither result nor error: %s", truncate(data)))
+		}
+
+		p.mu.Lock()
+		reply, ok := p.pending[string(msg.ID)]
+		late := p.abandoned[string(msg.ID)]
+		delete(p.pending, string(msg.ID))
+		delete(p.abandoned, string(msg.ID))
+		p.mu.Unlock()
+		if late {
+			return
+		}
+		if !ok {
+			p.violation(fmt.Sprintf("response to unknown request id %s", msg.ID))
+			return
+		}
+		reply <- &msg
+	}
+}
+
+// answer replies to a request from the server: pings succeed and anything else is unsupported
+func (p *peer) answer(request message) {
+	response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+	if request.Method == "ping" {
+		response["result"] = struct{}{}
+	} else {
+		response["error"] = jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: "method not supported by the conformance client"}
+	}
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	go func() { _ = p.send(context.Background(), data) }()
+}
+
+// close fails every pending call with err
+func (p *peer) close(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed != nil {
+		return
+	}
+	p.closed = err
+	for id, reply := range p.pending {
+		close(reply)
+		delete(p.pending, id)
+	}
+}
+
+// closedErr returns why the connection was closed
+func (p *peer) closedErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed != nil {
+		return p.closed
+	}
+	return fmt.Errorf("connection closed")
+}
+
+// violation records a framing problem
+func (p *peer) violation(problem string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.violations = append(p.violations, problem)
+}
+
+// encodeMessage builds a request, or a notification when id is nil
+func encodeMessage(id json.RawMessage, method string, params interface{}) ([]byte, error) {
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		msg["id"] = id
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s: %w", method, err)
+	}
+	return data, nil
+}
+
+// truncate shortens a message for reports
+func truncate(data []byte) string {
+	const limit = 200
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
@@ -0,0 +1,25 @@
+package conformance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPeerForgetsAbandonedCalls(t *testing.T) {
+	p := newPeer(func(context.Context, []byte) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := p.Call(ctx, "tools/list", nil)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, p.pending)
+	assert.Len(t, p.abandoned, 1)
+
+	// The late response is dropped without a violation, and the call is forgotten
+	p.receive([]byte(`{"jsonrpc": "2.0", "id": 1, "result": {}}`))
+	assert.Empty(t, p.abandoned)
+	assert.Empty(t, p.Violations())
+}
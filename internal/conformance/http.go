@@ -0,0 +1,139 @@
+package conformance
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+
+	"mcp_tstr/internal/streamable"
+)
+
+// httpConn talks to a server over the streamable HTTP transport: every message is POSTed and
+// responses come back as JSON or as a server-sent event stream
+type httpConn struct {
+	*peer
+	url    string
+	client *http.Client
+
+	mu              sync.Mutex
+	sessionID       string
+	protocolVersion string
+}
+
+// DialHTTP connects to a streamable HTTP endpoint
+func DialHTTP(url string) (Conn, error) {
+	c := &httpConn{url: url, client: &http.Client{}}
+	c.peer = newPeer(c.post)
+	return c, nil
+}
+
+// SetProtocolVersion sets the version sent in the MCP-Protocol-Version header after initialization
+func (c *httpConn) SetProtocolVersion(version string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.protocolVersion = version
+}
+
+// post sends a message and feeds whatever the server answers with to the peer
+func (c *httpConn) post(ctx context.Context, data []byte) error {
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	c.mu.Lock()
+	if c.sessionID != "" {
+		req.Header.Set(streamable.HeaderSessionID, c.sessionID)
+	}
+	if c.protocolVersion != "" {
+		req.Header.Set(streamable.HeaderProtocolVersion, c.protocolVersion)
+	}
+	c.mu.Unlock()
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	if id := resp.Header.Get(streamable.HeaderSessionID); id != "" {
+		c.mu.Lock()
+		c.sessionID = id
+		c.mu.Unlock()
+	}
+
+	if resp.StatusCode == http.StatusAccepted {
+		resp.Body.Close()
+		return nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return fmt.Errorf("HTTP %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	go func() {
+		defer resp.Body.Close()
+		if mediaType == "text/event-stream" {
+			c.readEvents(resp.Body)
+			return
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			c.violation(fmt.Sprintf("failed to read response body: %v", err))
+			return
+		}
+		if len(bytes.TrimSpace(body)) > 0 {
+			c.receive(body)
+		}
+	}()
+	return nil
+}
+
+// readEvents passes the data of every server-sent event to the peer
+func (c *httpConn) readEvents(body io.Reader) {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	var data []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if len(data) > 0 {
+				c.receive([]byte(strings.Join(data, "\n")))
+				data = nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if len(data) > 0 {
+		c.receive([]byte(strings.Join(data, "\n")))
+	}
+}
+
+// Close ends the session with a DELETE request
+func (c *httpConn) Close() error {
+	c.mu.Lock()
+	sessionID := c.sessionID
+	c.mu.Unlock()
+
+	if sessionID != "" {
+		req, err := http.NewRequest(http.MethodDelete, c.url, nil)
+		if err == nil {
+			req.Header.Set(streamable.HeaderSessionID, sessionID)
+			if resp, err := c.client.Do(req); err == nil {
+				resp.Body.Close()
+			}
+		}
+	}
+	c.close(fmt.Errorf("connection closed"))
+	c.client.CloseIdleConnections()
+	return nil
+}
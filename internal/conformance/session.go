@@ -0,0 +1,66 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"mcp_tstr/internal/jsonrpc"
+)
+
+// Initialize performs the initialize handshake on a connection, for commands that use the raw
+// connection as an ordinary client
+func Initialize(ctx context.Context, conn Conn) error {
+	resp, err := conn.Call(ctx, "initialize", initializeParams(jsonrpc.SpecVersion))
+	if err == nil && resp.Error != nil {
+		err = resp.Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	var result struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if setter, ok := conn.(versionSetter); ok && json.Unmarshal(resp.Result, &result) == nil {
+		setter.SetProtocolVersion(result.ProtocolVersion)
+	}
+	if err := conn.Notify(ctx, "notifications/initialized", nil); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	return nil
+}
+
+// ListTools follows tools/list through every page and returns the tools undecoded, so one
+// malformed tool does not hide the others
+func ListTools(ctx context.Context, conn Conn) ([]json.RawMessage, error) {
+	var tools []json.RawMessage
+	cursor := ""
+	for page := 0; page < maxPages; page++ {
+		params := map[string]interface{}{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		resp, err := conn.Call(ctx, "tools/list", params)
+		if err == nil && resp.Error != nil {
+			err = resp.Error
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+
+		var result struct {
+			Tools      []json.RawMessage `json:"tools"`
+			NextCursor string            `json:"nextCursor"`
+		}
+		if err := json.Unmarshal(resp.Result, &result); err != nil {
+			return nil, fmt.Errorf("failed to decode tools: %w", err)
+		}
+		tools = append(tools, result.Tools...)
+		if result.NextCursor == "" {
+			return tools, nil
+		}
+		cursor = result.NextCursor
+	}
+	return nil, fmt.Errorf("still returning nextCursor after %d pages", maxPages)
+}
@@ -0,0 +1,49 @@
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcp"
+)
+
+func TestInitializeAndListTools(t *testing.T) {
+	conn, err := stdioDialer(t, "sdk")()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var mu sync.Mutex
+	var observed []string
+	Observe(conn, func(direction string, data []byte) {
+		var msg message
+		assert.NoError(t, json.Unmarshal(data, &msg))
+		mu.Lock()
+		defer mu.Unlock()
+		observed = append(observed, direction+" "+msg.Method)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, Initialize(ctx, conn))
+	tools, err := ListTools(ctx, conn)
+	require.NoError(t, err)
+
+	require.Len(t, tools, 1)
+	assert.Contains(t, string(tools[0]), `"name":"echo"`)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{
+		mcp.DirectionSend + " initialize",
+		mcp.DirectionReceive + " ",
+		mcp.DirectionSend + " notifications/initialized",
+		mcp.DirectionSend + " tools/list",
+		mcp.DirectionReceive + " ",
+	}, observed)
+}
@@ -0,0 +1,94 @@
+package conformance
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"mcp_tstr/internal/config"
+)
+
+// closeTimeout is how long a server gets to exit after its stdin is closed
+const closeTimeout = 2 * time.Second
+
+// ErrServerExited is returned by calls on a stdio connection whose server closed its output,
+// which it does when it exits
+var ErrServerExited = errors.New("server closed its output")
+
+// stdioConn talks to a server process over newline-delimited JSON on stdin and stdout
+type stdioConn struct {
+	*peer
+	cmd   *exec.Cmd
+	stdin io.WriteCloser
+
+	writeMu sync.Mutex
+	done    chan struct{}
+}
+
+// DialStdio starts the server's command and connects to it. The server's stderr goes to stderr,
+// or is discarded if that is nil.
+func DialStdio(serverConfig config.MCPServer, stderr io.Writer) (Conn, error) {
+	if len(serverConfig.Command) == 0 {
+		return nil, fmt.Errorf("command is required for stdio transport")
+	}
+
+	cmd := exec.Command(serverConfig.Command[0], serverConfig.Command[1:]...)
+	cmd.Env = cmd.Environ()
+	for key, value := range serverConfig.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, value))
+	}
+	cmd.Stderr = stderr
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start server: %w", err)
+	}
+
+	c := &stdioConn{cmd: cmd, stdin: stdin, done: make(chan struct{})}
+	c.peer = newPeer(c.write)
+
+	go func() {
+		defer close(c.done)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for scanner.Scan() {
+			if line := scanner.Bytes(); len(line) > 0 {
+				c.receive(append([]byte(nil), line...))
+			}
+		}
+		c.close(ErrServerExited)
+	}()
+	return c, nil
+}
+
+// write sends one message followed by a newline
+func (c *stdioConn) write(ctx context.Context, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err := c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// Close closes the server's stdin and waits for it to exit, killing it if it does not
+func (c *stdioConn) Close() error {
+	_ = c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(closeTimeout):
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.cmd.Wait()
+	return nil
+}
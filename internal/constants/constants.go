@@ -1,24 +1,44 @@
 package constants
 
+import "time"
+
 const (
 	// AppName is the name of the application
 	AppName = "mcp_tstr"
-	
+
 	// AppVersion is the current version of the application
 	AppVersion = "1.0.0"
-	
+
 	// ConfigFileName is the default configuration file name
 	ConfigFileName = "mcp_tstr.config"
-	
+
 	// MCPConfigFileName is the MCP servers configuration file name
 	MCPConfigFileName = "mcp.json"
-	
+
 	// DefaultLogLevel is the default logging level
 	DefaultLogLevel = "info"
-	
+
 	// DefaultProvider is the default AI provider
 	DefaultProvider = "ollama"
-	
+
 	// DefaultModel is the default AI model
 	DefaultModel = "llama2"
+
+	// DefaultToolApproval asks before running tools that may modify data
+	DefaultToolApproval = "destructive"
+
+	// DefaultStartupTimeout bounds connecting to and initializing a single server
+	DefaultStartupTimeout = 30 * time.Second
+
+	// DefaultStartupWorkers is the number of servers initialized concurrently
+	DefaultStartupWorkers = 4
+
+	// DefaultReconnectRetries is the number of reconnect attempts made for a dropped server
+	DefaultReconnectRetries = 5
+
+	// DefaultReconnectInitialBackoff is the delay before the first reconnect attempt
+	DefaultReconnectInitialBackoff = 500 * time.Millisecond
+
+	// DefaultReconnectMaxBackoff caps the exponential delay between reconnect attempts
+	DefaultReconnectMaxBackoff = 30 * time.Second
 )
@@ -15,6 +15,7 @@ func TestConstants(t *testing.T) {
 	assert.Equal(t, "info", DefaultLogLevel)
 	assert.Equal(t, "ollama", DefaultProvider)
 	assert.Equal(t, "llama2", DefaultModel)
+	assert.Equal(t, "destructive", DefaultToolApproval)
 }
 
 func TestConstantsNotEmpty(t *testing.T) {
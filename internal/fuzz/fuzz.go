@@ -0,0 +1,305 @@
+// Package fuzz calls a tool with valid, boundary and invalid arguments generated from its input
+// schema and reports the inputs that crash, hang or confuse the server
+package fuzz
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcp"
+)
+
+// Call outcomes. The first three are how a server should answer; the others are findings.
+const (
+	OutcomeOK = "ok"
+	// OutcomeToolError is a result flagged isError
+	OutcomeToolError = "tool_error"
+	// OutcomeRejected is a JSON-RPC invalid params error
+	OutcomeRejected = "rejected"
+	// OutcomeProtocolError is any other JSON-RPC error, a malformed response, or a failed request
+	// the server survived
+	OutcomeProtocolError = "protocol_error"
+	// OutcomeTimeout means no response arrived in time
+	OutcomeTimeout = "timeout"
+	// OutcomeCrash means a stdio server exited
+	OutcomeCrash = "crash"
+	// OutcomeDisconnect means the server stopped answering for another reason
+	OutcomeDisconnect = "disconnect"
+)
+
+// Outcomes lists every outcome in report order
+var Outcomes = []string{OutcomeOK, OutcomeToolError, OutcomeRejected, OutcomeProtocolError, OutcomeTimeout, OutcomeCrash, OutcomeDisconnect}
+
+// IsFinding reports whether an outcome is a problem with the server
+func IsFinding(outcome string) bool {
+	switch outcome {
+	case OutcomeProtocolError, OutcomeTimeout, OutcomeCrash, OutcomeDisconnect:
+		return true
+	}
+	return false
+}
+
+// Defaults for Options
+const (
+	DefaultValid          = 20
+	DefaultStringLength   = 100000
+	DefaultDepth          = 1000
+	DefaultShrinkAttempts = 50
+)
+
+// stderrLimit is how much of a stdio server's stderr is kept for crash reports
+const stderrLimit = 4096
+
+// Options controls a fuzzing run
+type Options struct {
+	// Seed makes the generated inputs reproducible
+	Seed int64
+	// Valid is the number of random valid inputs
+	Valid int
+	// Timeout bounds each call
+	Timeout time.Duration
+	// StringLength is the length of the huge strings
+	StringLength int
+	// Depth is how deep the deeply nested values go
+	Depth int
+	// ShrinkAttempts bounds the calls spent minimizing each finding
+	ShrinkAttempts int
+}
+
+// Dialer opens a new raw connection to the server. A stdio server's stderr goes to stderr.
+type Dialer func(stderr io.Writer) (conformance.Conn, error)
+
+// Result is the outcome of calling the tool with one case
+type Result struct {
+	Case
+	Outcome  string        `json:"outcome"`
+	Message  string        `json:"message,omitempty"`
+	Duration time.Duration `json:"-"`
+	// Stderr is the end of a crashed stdio server's stderr
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// Run connects to a server, looks up the tool and calls it with every generated case,
+// reconnecting whenever the server crashes, hangs or drops the connection. Findings are then
+// shrunk to the smallest input that still gives the same outcome. progress, if set, is called
+// after every case. When ctx is cancelled, the report covers the cases run so far.
+func Run(ctx context.Context, dial Dialer, tool string, opts Options, progress func(Result)) (*Report, error) {
+	r := &runner{dial: dial, tool: tool, timeout: opts.Timeout}
+	defer r.reset()
+
+	if err := r.connect(ctx); err != nil {
+		return nil, err
+	}
+	inputSchema, err := r.inputSchema(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	report := newReport(tool, opts.Seed)
+	var results []Result
+	for _, c := range Generate(inputSchema, opts) {
+		result, err := r.run(ctx, c)
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return nil, err
+		}
+		report.count(result)
+		results = append(results, result)
+		if progress != nil {
+			progress(result)
+		}
+	}
+
+	for _, finding := range group(results) {
+		if ctx.Err() == nil {
+			if err := r.shrink(ctx, &finding, opts.ShrinkAttempts); err != nil && ctx.Err() == nil {
+				return nil, err
+			}
+		}
+		report.Findings = append(report.Findings, finding)
+	}
+	sortFindings(report.Findings)
+	return report, nil
+}
+
+// runner calls the tool, keeping a live connection to the server
+type runner struct {
+	dial    Dialer
+	tool    string
+	timeout time.Duration
+
+	conn   conformance.Conn
+	stderr *mcp.StderrTail
+	// crashStderr is the end of the stderr of the server that crashed last
+	crashStderr string
+}
+
+// connect dials the server and initializes the session
+func (r *runner) connect(ctx context.Context) error {
+	stderr := mcp.NewStderrTail(stderrLimit)
+	conn, err := r.dial(stderr)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	r.conn, r.stderr = conn, stderr
+
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	if err := conformance.Initialize(callCtx, conn); err != nil {
+		r.reset()
+		return err
+	}
+	return nil
+}
+
+// reset closes the connection, waiting for a stdio server to exit
+func (r *runner) reset() {
+	if r.conn != nil {
+		_ = r.conn.Close()
+		r.conn = nil
+	}
+}
+
+// inputSchema finds the tool in tools/list and decodes its input schema
+func (r *runner) inputSchema(ctx context.Context) (*jsonschema.Schema, error) {
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	tools, err := conformance.ListTools(callCtx, r.conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, data := range tools {
+		var tool struct {
+			Name        string          `json:"name"`
+			InputSchema json.RawMessage `json:"inputSchema"`
+		}
+		if json.Unmarshal(data, &tool) != nil || tool.Name != r.tool {
+			continue
+		}
+		var s jsonschema.Schema
+		if len(tool.InputSchema) > 0 {
+			if err := json.Unmarshal(tool.InputSchema, &s); err != nil {
+				return nil, fmt.Errorf("invalid input schema of tool %s: %w", r.tool, err)
+			}
+		}
+		return &s, nil
+	}
+	return nil, fmt.Errorf("tool %s not found", r.tool)
+}
+
+// run calls the tool with a case
+func (r *runner) run(ctx context.Context, c Case) (Result, error) {
+	start := time.Now()
+	outcome, message, err := r.call(ctx, c.Arguments)
+	result := Result{Case: c, Outcome: outcome, Message: message, Duration: time.Since(start)}
+	if outcome == OutcomeCrash {
+		result.Stderr = r.crashStderr
+	}
+	return result, err
+}
+
+// call calls the tool and classifies the response. The connection is dropped after a crash,
+// disconnect or timeout, so the next call starts from a fresh server. An error means the
+// server could not be reached at all.
+func (r *runner) call(ctx context.Context, arguments interface{}) (string, string, error) {
+	if r.conn == nil {
+		if err := r.connect(ctx); err != nil {
+			return "", "", fmt.Errorf("failed to reconnect after the previous input: %w", err)
+		}
+	}
+
+	params := map[string]interface{}{"name": r.tool}
+	if arguments != nil {
+		params["arguments"] = arguments
+	}
+	violations := len(r.conn.Violations())
+	callCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	resp, err := r.conn.Call(callCtx, "tools/call", params)
+	cancel()
+
+	switch {
+	case err != nil && ctx.Err() != nil:
+		return "", "", ctx.Err()
+	case errors.Is(err, context.DeadlineExceeded):
+		r.reset()
+		return OutcomeTimeout, fmt.Sprintf("no response within %s", r.timeout), nil
+	case err != nil:
+		if r.exited(ctx, err) {
+			// Once the process is reaped its stderr is complete
+			r.reset()
+			r.crashStderr = r.stderr.String()
+			return OutcomeCrash, err.Error(), nil
+		}
+		if r.alive(ctx) {
+			return OutcomeProtocolError, err.Error(), nil
+		}
+		r.reset()
+		return OutcomeDisconnect, err.Error(), nil
+	}
+
+	if problems := r.conn.Violations(); len(problems) > violations {
+		return OutcomeProtocolError, problems[violations], nil
+	}
+	if resp.Error != nil {
+		if resp.Error.Code == jsonrpc.CodeInvalidParams {
+			return OutcomeRejected, resp.Error.Error(), nil
+		}
+		return OutcomeProtocolError, resp.Error.Error(), nil
+	}
+
+	var result struct {
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		IsError bool `json:"isError"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		return OutcomeProtocolError, fmt.Sprintf("malformed tools/call result: %v", err), nil
+	}
+	if result.Content == nil {
+		return OutcomeProtocolError, "tools/call result has no content array", nil
+	}
+	if result.IsError {
+		message := ""
+		if len(result.Content) > 0 {
+			message = result.Content[0].Text
+		}
+		return OutcomeToolError, message, nil
+	}
+	return OutcomeOK, "", nil
+}
+
+// exited reports whether a stdio server exited. A write to a server that just died can fail
+// before the connection sees its output close, so a failed call is followed up with pings.
+func (r *runner) exited(ctx context.Context, err error) bool {
+	for i := 0; i < 10 && !errors.Is(err, conformance.ErrServerExited); i++ {
+		time.Sleep(50 * time.Millisecond)
+		pingCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		_, err = r.conn.Call(pingCtx, "ping", nil)
+		cancel()
+		if err == nil {
+			return false
+		}
+	}
+	return errors.Is(err, conformance.ErrServerExited)
+}
+
+// alive reports whether the server still answers pings
+func (r *runner) alive(ctx context.Context) bool {
+	pingCtx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+	resp, err := r.conn.Call(pingCtx, "ping", nil)
+	return err == nil && resp.Error == nil
+}
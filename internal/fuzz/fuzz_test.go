@@ -0,0 +1,208 @@
+package fuzz
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/conformance"
+)
+
+// The test binary doubles as a fragile server under test
+const serverEnv = "MCP_TSTR_FUZZ_SERVER"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(serverEnv) != "" {
+		runFragileServer()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// lookupSchema is the input schema of the fragile server's tool
+const lookupSchema = `{
+	"type": "object",
+	"required": ["name"],
+	"properties": {
+		"name": {"type": "string", "maxLength": 50},
+		"count": {"type": "integer", "minimum": 0},
+		"tags": {"type": "array", "items": {"type": "string"}}
+	}
+}`
+
+// runFragileServer serves a lookup tool over stdio that exits on names longer than 1000
+// characters, hangs on negative counts and answers empty tags with a malformed result
+func runFragileServer() {
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				Arguments json.RawMessage `json:"arguments"`
+			} `json:"params"`
+		}
+		if json.Unmarshal(line, &msg) != nil || len(msg.ID) == 0 {
+			continue
+		}
+		respond := func(body string) {
+			fmt.Printf(`{"jsonrpc":"2.0","id":%s,%s}`+"\n", msg.ID, body)
+		}
+
+		switch msg.Method {
+		case "initialize":
+			respond(`"result":{"protocolVersion":"2025-06-18","capabilities":{"tools":{}},"serverInfo":{"name":"fragile","version":"1.0.0"}}`)
+		case "ping":
+			respond(`"result":{}`)
+		case "tools/list":
+			var compact bytes.Buffer
+			_ = json.Compact(&compact, []byte(lookupSchema))
+			respond(`"result":{"tools":[{"name":"lookup","inputSchema":` + compact.String() + `}]}`)
+		case "tools/call":
+			var args map[string]interface{}
+			if json.Unmarshal(msg.Params.Arguments, &args) != nil || args == nil {
+				respond(`"error":{"code":-32602,"message":"arguments must be an object"}`)
+				continue
+			}
+			if count, ok := args["count"].(float64); ok && count < 0 {
+				continue
+			}
+			if name, ok := args["name"].(string); ok && len(name) > 1000 {
+				fmt.Fprintln(os.Stderr, "panic: name too long")
+				os.Exit(2)
+			}
+			if tags, ok := args["tags"].([]interface{}); ok && len(tags) > 0 && tags[0] == "" {
+				respond(`"result":{"isError":false}`)
+				continue
+			}
+			if _, ok := args["name"].(string); !ok {
+				respond(`"result":{"content":[{"type":"text","text":"name must be a string"}],"isError":true}`)
+				continue
+			}
+			respond(`"result":{"content":[{"type":"text","text":"found"}]}`)
+		default:
+			respond(`"error":{"code":-32601,"message":"method not found"}`)
+		}
+	}
+}
+
+// fragileDialer starts the fragile server from the test binary
+func fragileDialer(t *testing.T) Dialer {
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	serverConfig := config.MCPServer{
+		Command: []string{executable},
+		Env:     map[string]string{serverEnv: "1"},
+	}
+	return func(stderr io.Writer) (conformance.Conn, error) {
+		return conformance.DialStdio(serverConfig, stderr)
+	}
+}
+
+// findingAt returns the finding for a path and outcome
+func findingAt(t *testing.T, report *Report, path, outcome string) Finding {
+	t.Helper()
+	for _, f := range report.Findings {
+		if f.Path == path && f.Outcome == outcome {
+			return f
+		}
+	}
+	require.Failf(t, "finding not reported", "no %s at %s", outcome, path)
+	return Finding{}
+}
+
+func TestRun(t *testing.T) {
+	opts := Options{Seed: 1, Valid: 5, Timeout: 300 * time.Millisecond, StringLength: 2000, Depth: 50, ShrinkAttempts: 30}
+	var progress int
+	report, err := Run(context.Background(), fragileDialer(t), "lookup", opts, func(Result) { progress++ })
+	require.NoError(t, err)
+
+	assert.Equal(t, "lookup", report.Tool)
+	assert.Equal(t, int64(1), report.Seed)
+	assert.Equal(t, report.Cases, progress)
+	assert.Equal(t, 7, report.Outcomes[KindValid][OutcomeOK])
+	assert.Greater(t, report.Outcomes[KindInvalid][OutcomeRejected], 0)
+	assert.Greater(t, report.Outcomes[KindInvalid][OutcomeToolError], 0)
+
+	crash := findingAt(t, report, "$.name", OutcomeCrash)
+	assert.Equal(t, "huge string (2000 characters)", crash.Mutation)
+	assert.Contains(t, crash.Stderr, "panic: name too long")
+	require.IsType(t, map[string]interface{}{}, crash.Minimal)
+	minimal := crash.Minimal.(map[string]interface{})
+	assert.Len(t, minimal, 1)
+	assert.Greater(t, len(minimal["name"].(string)), 1000)
+
+	hang := findingAt(t, report, "$.count", OutcomeTimeout)
+	assert.Equal(t, "negative", hang.Mutation)
+	assert.Contains(t, hang.Similar, "huge negative")
+	assert.Equal(t, map[string]interface{}{"count": -1.0}, hang.Minimal)
+
+	malformed := findingAt(t, report, "$.tags[0]", OutcomeProtocolError)
+	assert.Equal(t, "tools/call result has no content array", malformed.Message)
+	assert.Equal(t, map[string]interface{}{"tags": []interface{}{""}}, malformed.Minimal)
+
+	// The most severe findings come first
+	assert.Equal(t, OutcomeCrash, report.Findings[0].Outcome)
+
+	// The same seed gives the same report
+	again, err := Run(context.Background(), fragileDialer(t), "lookup", opts, nil)
+	require.NoError(t, err)
+	assert.Equal(t, report, again)
+}
+
+func TestRunUnknownTool(t *testing.T) {
+	_, err := Run(context.Background(), fragileDialer(t), "missing", Options{Timeout: time.Second}, nil)
+	assert.EqualError(t, err, "tool missing not found")
+}
+
+func TestReportOutput(t *testing.T) {
+	report := newReport("lookup", 42)
+	report.count(Result{Case: Case{Kind: KindValid}, Outcome: OutcomeOK})
+	report.count(Result{Case: Case{Kind: KindInvalid}, Outcome: OutcomeCrash})
+	report.Findings = append(report.Findings, Finding{
+		Outcome:     OutcomeCrash,
+		Kind:        KindInvalid,
+		Path:        "$.name",
+		Mutation:    "huge string (100000 characters)",
+		Message:     "server closed its output",
+		Minimal:     map[string]interface{}{"name": "xx"},
+		ShrinkCalls: 12,
+		Reproduced:  3,
+		Stderr:      "panic: name too long\n",
+		Similar:     []string{"unicode"},
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Fuzzed tool lookup with 2 inputs (seed 42)")
+	assert.Regexp(t, `invalid\s+0\s+0\s+0\s+0\s+0\s+1\s+0`, buf.String())
+	assert.Contains(t, buf.String(), "1. CRASH with invalid input $.name: huge string (100000 characters)")
+	assert.Contains(t, buf.String(), "Also: unicode")
+	assert.Contains(t, buf.String(), `Minimal input (shrunk in 12 calls): {"name":"xx"}`)
+	assert.Contains(t, buf.String(), "| panic: name too long")
+
+	buf.Reset()
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 1.0, decoded["outcomes"].(map[string]interface{})["invalid"].(map[string]interface{})["crash"])
+	assert.Equal(t, "xx", decoded["findings"].([]interface{})[0].(map[string]interface{})["minimal"].(map[string]interface{})["name"])
+
+	assert.Equal(t, `no arguments`, formatInput(nil))
+	assert.Contains(t, formatInput(map[string]interface{}{"a": strings.Repeat("x", 1000)}), "(1008 bytes; use -o json for the full input)")
+}
@@ -0,0 +1,642 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+
+	"mcp_tstr/internal/schema"
+)
+
+// Input kinds
+const (
+	// KindValid inputs are random values the schema accepts
+	KindValid = "valid"
+	// KindBoundary inputs are edge cases the schema still accepts, such as empty strings
+	KindBoundary = "boundary"
+	// KindInvalid inputs violate the schema
+	KindInvalid = "invalid"
+)
+
+const (
+	// maxValueDepth stops generated values from following recursive schemas forever
+	maxValueDepth = 4
+	// maxMutationDepth bounds how deep into the base input values are mutated
+	maxMutationDepth = 4
+	// hugeArrayItems is the length of the huge arrays
+	hugeArrayItems = 10000
+	// unknownProperty is the name of the property no schema declares
+	unknownProperty = "mcp_tstr_unknown"
+)
+
+// unicodeString mixes scripts, emoji, combining marks, zero-width and right-to-left characters
+const unicodeString = "h\u00e9llo w\u00f6rld \u0395\u03bb\u03bb\u03b7\u03bd\u03b9\u03ba\u03ac \u041a\u0438\u0440\u0438\u043b\u043b\u0438\u0446\u0430 \u4e2d\u6587 \u65e5\u672c\u8a9e \ud55c\uad6d\uc5b4 \u0645\u0631\u062d\u0628\u0627 \u05e2\u05d1\u05e8\u05d9\u05ea \U0001F980\U0001F469\u200d\U0001F469\u200d\U0001F467 e\u0301 \u200b\u200e\u202e\ufeff"
+
+// controlString holds NUL, newlines and other control characters
+const controlString = "line1\nline2\r\n\ttab \x00 nul \x1b[31m escape \x7f"
+
+// Case is one input to call the tool with
+type Case struct {
+	Kind string `json:"kind"`
+	// Path is where the input differs from the base input, such as $.query; it is $ for
+	// the random valid inputs
+	Path string `json:"path"`
+	// Mutation says how the input was made, such as "empty string"
+	Mutation  string      `json:"mutation"`
+	Arguments interface{} `json:"arguments"`
+}
+
+// Description names the case in reports
+func (c Case) Description() string {
+	return c.Path + ": " + c.Mutation
+}
+
+// generator builds inputs for one schema from a seeded random source
+type generator struct {
+	root *jsonschema.Schema
+	rand *rand.Rand
+	opts Options
+}
+
+// Generate returns the cases for a tool's input schema: opts.Valid random valid inputs, then
+// boundary and invalid variants of a base input for the arguments as a whole and for every
+// property within them. Cases are classified by validating them against the schema, so a
+// mutation the schema happens to allow counts as a boundary case. The same seed gives the same
+// cases.
+func Generate(s *jsonschema.Schema, opts Options) []Case {
+	if s == nil {
+		s = &jsonschema.Schema{Type: "object"}
+	}
+	g := &generator{root: s, rand: rand.New(rand.NewSource(opts.Seed)), opts: opts}
+
+	var cases []Case
+	add := func(random bool, path, mutation string, arguments interface{}) {
+		kind := KindBoundary
+		if random {
+			kind = KindValid
+		}
+		if err := schema.Validate(s, arguments); err != nil {
+			kind = KindInvalid
+		}
+		cases = append(cases, Case{Kind: kind, Path: path, Mutation: mutation, Arguments: arguments})
+	}
+
+	add(true, "$", "required properties only", g.value(s, 0, fillRequired))
+	for i := 0; i < opts.Valid; i++ {
+		add(true, "$", fmt.Sprintf("random input %d", i+1), g.value(s, 0, fillRandom))
+	}
+
+	base := g.value(s, 0, fillAll)
+	add(true, "$", "every property set", base)
+
+	add(false, "$", "no arguments", nil)
+	add(false, "$", "empty object", map[string]interface{}{})
+	add(false, "$", "arguments are an array", []interface{}{base})
+	add(false, "$", "arguments are a string", "mcp_tstr")
+	if object, ok := base.(map[string]interface{}); ok {
+		for _, m := range []struct {
+			name  string
+			value interface{}
+		}{
+			{"unknown property", "mcp_tstr"},
+			{fmt.Sprintf("unknown property nested %d objects deep", opts.Depth), nestedObject(opts.Depth)},
+			{fmt.Sprintf("unknown property nested %d arrays deep", opts.Depth), nestedArray(opts.Depth)},
+		} {
+			arguments := copyValue(object).(map[string]interface{})
+			arguments[unknownProperty] = m.value
+			add(false, "$", m.name, arguments)
+		}
+	}
+
+	g.walk(s, base, nil, 0, func(at []interface{}, mutation string, value interface{}, remove bool) {
+		add(false, formatPath(at), mutation, replace(base, at, value, remove))
+	})
+	return cases
+}
+
+// fill says which optional properties generated objects set
+type fill int
+
+const (
+	fillRequired fill = iota
+	fillRandom
+	fillAll
+)
+
+// value generates a value the schema accepts, as far as the keywords it understands allow
+func (g *generator) value(s *jsonschema.Schema, depth int, mode fill) interface{} {
+	s = schema.Resolve(g.root, s)
+	if s == nil {
+		return "mcp_tstr"
+	}
+	if s.Const != nil {
+		return copyValue(*s.Const)
+	}
+	if len(s.Enum) > 0 {
+		return copyValue(s.Enum[g.rand.Intn(len(s.Enum))])
+	}
+	if branches := append(append([]*jsonschema.Schema{}, s.AnyOf...), s.OneOf...); len(branches) > 0 && typeOf(s) == "" {
+		return g.value(branches[g.rand.Intn(len(branches))], depth, mode)
+	}
+	if typeOf(s) == "" && len(s.AllOf) > 0 {
+		return g.value(s.AllOf[0], depth, mode)
+	}
+	if value, ok := defaultOf(s); ok && mode != fillAll && g.rand.Intn(2) == 0 {
+		return value
+	}
+
+	switch typeOf(s) {
+	case "object":
+		return g.object(s, depth, mode)
+	case "array":
+		return g.array(s, depth, mode)
+	case "integer":
+		lo, hi := bounds(s, true)
+		n := lo + float64(g.rand.Int63n(int64(hi-lo)+1))
+		return multipleOf(s, n, hi)
+	case "number":
+		lo, hi := bounds(s, false)
+		return multipleOf(s, math.Round((lo+g.rand.Float64()*(hi-lo))*100)/100, hi)
+	case "boolean":
+		return g.rand.Intn(2) == 0
+	case "null":
+		return nil
+	case "string":
+		return g.string(s)
+	}
+	return "mcp_tstr"
+}
+
+// object generates the properties of an object; beyond maxValueDepth only required ones
+func (g *generator) object(s *jsonschema.Schema, depth int, mode fill) map[string]interface{} {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+	object := make(map[string]interface{})
+	for _, name := range sortedKeys(s.Properties) {
+		set := required[name]
+		switch {
+		case depth >= maxValueDepth:
+		case mode == fillAll:
+			set = true
+		case mode == fillRandom:
+			set = set || g.rand.Intn(2) == 0
+		}
+		if set {
+			object[name] = g.value(s.Properties[name], depth+1, mode)
+		}
+	}
+	// Required properties without a schema still need a value
+	for _, name := range s.Required {
+		if _, ok := object[name]; !ok {
+			object[name] = "mcp_tstr"
+		}
+	}
+	return object
+}
+
+// array generates between minItems and a few more items, at least one unless maxItems is zero
+func (g *generator) array(s *jsonschema.Schema, depth int, mode fill) []interface{} {
+	lo := 1
+	if s.MinItems != nil {
+		// No more items than a huge array, whatever the schema asks for
+		lo = max(lo, min(*s.MinItems, hugeArrayItems))
+	}
+	hi := lo + 2
+	if s.MaxItems != nil {
+		hi = min(hi, *s.MaxItems)
+		lo = min(lo, hi)
+	}
+	if depth >= maxValueDepth {
+		hi = lo
+	}
+	n := lo + g.rand.Intn(hi-lo+1)
+
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item := s.Items
+		if i < len(s.PrefixItems) {
+			item = s.PrefixItems[i]
+		}
+		value := g.value(item, depth+1, mode)
+		if s.UniqueItems {
+			// Random values rarely repeat, but booleans and short enums do
+			if containsJSON(items, value) {
+				break
+			}
+		}
+		items = append(items, value)
+	}
+	return items
+}
+
+// string generates a string of a known format, or random letters within the length limits
+func (g *generator) string(s *jsonschema.Schema) string {
+	switch s.Format {
+	case "date-time":
+		return time.Unix(g.rand.Int63n(2e9), 0).UTC().Format(time.RFC3339)
+	case "date":
+		return time.Unix(g.rand.Int63n(2e9), 0).UTC().Format(time.DateOnly)
+	case "time":
+		return time.Unix(g.rand.Int63n(86400), 0).UTC().Format(time.TimeOnly)
+	case "email":
+		return g.letters(8) + "@example.com"
+	case "uri", "url":
+		return "https://example.com/" + g.letters(8)
+	case "uuid":
+		b := make([]byte, 16)
+		g.rand.Read(b)
+		return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+	}
+
+	lo, hi := 1, 12
+	if s.MinLength != nil {
+		// No longer than a huge string, whatever the schema asks for
+		lo = min(*s.MinLength, max(g.opts.StringLength, 1))
+		hi = max(hi, lo)
+	}
+	if s.MaxLength != nil {
+		hi = min(hi, *s.MaxLength)
+		lo = min(lo, hi)
+	}
+	return g.letters(lo + g.rand.Intn(hi-lo+1))
+}
+
+// letters returns n random lowercase letters
+func (g *generator) letters(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + g.rand.Intn(26))
+	}
+	return string(b)
+}
+
+// mutate is called with a path, a description of the mutation and the value to put at the
+// path, or with remove set to leave the property at the path out
+type mutate func(at []interface{}, mutation string, value interface{}, remove bool)
+
+// walk mutates the value at a path of the base input and recurses into its properties and items
+func (g *generator) walk(s *jsonschema.Schema, value interface{}, at []interface{}, depth int, emit mutate) {
+	s = schema.Resolve(g.root, s)
+	if s == nil || depth > maxMutationDepth {
+		return
+	}
+
+	if len(at) > 0 {
+		for _, m := range g.mutations(s) {
+			emit(at, m.name, m.value, false)
+		}
+	}
+
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := value[name]; ok {
+				emit(appendPath(at, name), "missing required property", nil, true)
+			}
+		}
+		for _, name := range sortedKeys(s.Properties) {
+			if child, ok := value[name]; ok {
+				g.walk(s.Properties[name], child, appendPath(at, name), depth+1, emit)
+			}
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			item := s.Items
+			if len(s.PrefixItems) > 0 {
+				item = s.PrefixItems[0]
+			}
+			g.walk(item, value[0], appendPath(at, 0), depth+1, emit)
+		}
+	}
+}
+
+// mutation is a replacement value with its description
+type mutation struct {
+	name  string
+	value interface{}
+}
+
+// mutations returns the boundary and invalid replacements for a value of a schema
+func (g *generator) mutations(s *jsonschema.Schema) []mutation {
+	var ms []mutation
+	add := func(name string, value interface{}) {
+		ms = append(ms, mutation{name, value})
+	}
+
+	valueType := typeOf(s)
+	switch valueType {
+	case "string":
+		add("empty string", "")
+		add(fmt.Sprintf("huge string (%d characters)", g.opts.StringLength), strings.Repeat("fuzz", g.opts.StringLength/4+1)[:g.opts.StringLength])
+		add("unicode", unicodeString)
+		add("control characters", controlString)
+		// Length boundaries beyond the huge string are left out rather than allocated
+		if s.MinLength != nil && *s.MinLength > 0 && *s.MinLength <= g.opts.StringLength {
+			add(fmt.Sprintf("minLength %d", *s.MinLength), strings.Repeat("x", *s.MinLength))
+			add(fmt.Sprintf("minLength %d - 1", *s.MinLength), strings.Repeat("x", *s.MinLength-1))
+		}
+		if s.MaxLength != nil && *s.MaxLength <= g.opts.StringLength {
+			add(fmt.Sprintf("maxLength %d", *s.MaxLength), strings.Repeat("x", *s.MaxLength))
+			add(fmt.Sprintf("maxLength %d + 1", *s.MaxLength), strings.Repeat("x", *s.MaxLength+1))
+		}
+		if s.Pattern != "" {
+			add("does not match the pattern", "mcp_tstr does not match")
+		}
+		if s.Format != "" {
+			add("invalid "+s.Format, "not a "+s.Format)
+		}
+	case "integer", "number":
+		add("zero", 0.0)
+		add("negative", -1.0)
+		add("huge", 1e308)
+		add("huge negative", -1e308)
+		add("largest int64", float64(math.MaxInt64))
+		add("smallest int64", float64(math.MinInt64))
+		if valueType == "integer" {
+			add("fraction", 1.5)
+		} else {
+			add("smallest positive number", math.SmallestNonzeroFloat64)
+		}
+		step := 1.0
+		if valueType == "number" {
+			step = 0.001
+		}
+		if s.Minimum != nil {
+			add(fmt.Sprintf("minimum %v", *s.Minimum), *s.Minimum)
+			add(fmt.Sprintf("below the minimum %v", *s.Minimum), *s.Minimum-step)
+		}
+		if s.Maximum != nil {
+			add(fmt.Sprintf("maximum %v", *s.Maximum), *s.Maximum)
+			add(fmt.Sprintf("above the maximum %v", *s.Maximum), *s.Maximum+step)
+		}
+		if s.ExclusiveMinimum != nil {
+			add(fmt.Sprintf("exclusive minimum %v", *s.ExclusiveMinimum), *s.ExclusiveMinimum)
+		}
+		if s.ExclusiveMaximum != nil {
+			add(fmt.Sprintf("exclusive maximum %v", *s.ExclusiveMaximum), *s.ExclusiveMaximum)
+		}
+	case "array":
+		add("empty array", []interface{}{})
+		item := g.value(s.Items, maxValueDepth, fillRequired)
+		huge := make([]interface{}, hugeArrayItems)
+		for i := range huge {
+			huge[i] = copyValue(item)
+		}
+		add(fmt.Sprintf("huge array (%d items)", hugeArrayItems), huge)
+		add("null item", []interface{}{nil})
+		if s.MaxItems != nil {
+			add(fmt.Sprintf("maxItems %d + 1", *s.MaxItems), huge[:min(*s.MaxItems+1, hugeArrayItems)])
+		}
+		if s.UniqueItems {
+			add("duplicate items", []interface{}{item, copyValue(item)})
+		}
+		add(fmt.Sprintf("nested %d arrays deep", g.opts.Depth), nestedArray(g.opts.Depth))
+	case "object":
+		add("empty object", map[string]interface{}{})
+		add("unknown property", map[string]interface{}{unknownProperty: "mcp_tstr"})
+		add(fmt.Sprintf("nested %d objects deep", g.opts.Depth), nestedObject(g.opts.Depth))
+	}
+	if len(s.Enum) > 0 {
+		add("not one of the enum values", "mcp_tstr_not_in_enum")
+	}
+
+	for _, wrong := range []struct {
+		jsonType string
+		value    interface{}
+	}{
+		{"string", "mcp_tstr"},
+		{"number", 12345.0},
+		{"boolean", true},
+		{"array", []interface{}{"mcp_tstr"}},
+		{"object", map[string]interface{}{"mcp_tstr": 1.0}},
+		{"null", nil},
+	} {
+		if !allowsType(s, wrong.jsonType) {
+			add("wrong type "+wrong.jsonType, wrong.value)
+		}
+	}
+	return ms
+}
+
+// typeOf returns the type a schema expects, ignoring null in type lists
+func typeOf(s *jsonschema.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case s.Properties != nil || len(s.Required) > 0:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// allowsType reports whether a schema allows values of a JSON type. Schemas without a type
+// allow everything.
+func allowsType(s *jsonschema.Schema, jsonType string) bool {
+	types := s.Types
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	if len(types) == 0 {
+		return typeOf(s) == "" || typeOf(s) == jsonType
+	}
+	for _, t := range types {
+		if t == jsonType || (t == "number" && jsonType == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// bounds returns the range random numbers are drawn from: the schema's limits, narrowed to
+// 0 to 100 where they are open
+func bounds(s *jsonschema.Schema, integer bool) (float64, float64) {
+	lo, hi := 0.0, 100.0
+	if s.Minimum != nil {
+		lo = *s.Minimum
+	}
+	if s.ExclusiveMinimum != nil {
+		lo = *s.ExclusiveMinimum + 0.01
+		if integer {
+			lo = math.Floor(*s.ExclusiveMinimum) + 1
+		}
+	}
+	if s.Maximum != nil {
+		hi = *s.Maximum
+	} else {
+		hi = max(hi, lo+100)
+	}
+	if s.ExclusiveMaximum != nil {
+		hi = *s.ExclusiveMaximum - 0.01
+		if integer {
+			hi = math.Ceil(*s.ExclusiveMaximum) - 1
+		}
+	}
+	if s.Minimum == nil && s.ExclusiveMinimum == nil {
+		lo = min(lo, hi-100)
+		if hi >= 0 {
+			lo = max(lo, 0)
+		}
+	}
+	// Keep random integers within range of int64
+	hi = min(hi, lo+1e6)
+	if integer {
+		lo, hi = math.Ceil(lo), math.Floor(hi)
+	}
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
+}
+
+// multipleOf rounds a number up to the schema's multipleOf, if it has one, or down to the largest
+// multiple not above hi when rounding up would pass it
+func multipleOf(s *jsonschema.Schema, n, hi float64) float64 {
+	if s.MultipleOf == nil || *s.MultipleOf <= 0 {
+		return n
+	}
+	rounded := math.Ceil(n / *s.MultipleOf) * *s.MultipleOf
+	if rounded > hi {
+		rounded = math.Floor(hi / *s.MultipleOf) * *s.MultipleOf
+	}
+	return rounded
+}
+
+// defaultOf decodes a schema's default value
+func defaultOf(s *jsonschema.Schema) (interface{}, bool) {
+	if len(s.Default) == 0 {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal(s.Default, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// nestedObject returns objects nested depth levels deep
+func nestedObject(depth int) interface{} {
+	var value interface{} = "mcp_tstr"
+	for i := 0; i < depth; i++ {
+		value = map[string]interface{}{"a": value}
+	}
+	return value
+}
+
+// nestedArray returns arrays nested depth levels deep
+func nestedArray(depth int) interface{} {
+	var value interface{} = "mcp_tstr"
+	for i := 0; i < depth; i++ {
+		value = []interface{}{value}
+	}
+	return value
+}
+
+// replace returns a copy of a value with the value at a path replaced, or removed
+func replace(root interface{}, at []interface{}, value interface{}, remove bool) interface{} {
+	if len(at) == 0 {
+		return value
+	}
+	copied := copyValue(root)
+	parent := copied
+	for _, step := range at[:len(at)-1] {
+		parent = child(parent, step)
+	}
+	switch last := at[len(at)-1].(type) {
+	case string:
+		object := parent.(map[string]interface{})
+		if remove {
+			delete(object, last)
+		} else {
+			object[last] = value
+		}
+	case int:
+		parent.([]interface{})[last] = value
+	}
+	return copied
+}
+
+// child returns a property or item of a value
+func child(value interface{}, step interface{}) interface{} {
+	switch step := step.(type) {
+	case string:
+		return value.(map[string]interface{})[step]
+	case int:
+		return value.([]interface{})[step]
+	}
+	return nil
+}
+
+// appendPath returns a copy of a path with a property name or item index appended
+func appendPath(at []interface{}, step interface{}) []interface{} {
+	return append(append([]interface{}{}, at...), step)
+}
+
+// formatPath formats a path the way validation errors do, such as $.items[0].name
+func formatPath(at []interface{}) string {
+	path := "$"
+	for _, step := range at {
+		switch step := step.(type) {
+		case string:
+			path = schema.PropertyPath(path, step)
+		case int:
+			path = fmt.Sprintf("%s[%d]", path, step)
+		}
+	}
+	return path
+}
+
+// copyValue deep-copies a generic JSON value
+func copyValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			copied[k] = copyValue(v)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(value))
+		for i, v := range value {
+			copied[i] = copyValue(v)
+		}
+		return copied
+	}
+	return value
+}
+
+// containsJSON reports whether a list holds a value equal to the given one
+func containsJSON(values []interface{}, value interface{}) bool {
+	encoded, _ := json.Marshal(value)
+	for _, v := range values {
+		if other, _ := json.Marshal(v); string(other) == string(encoded) {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedKeys returns the keys of a map in order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,147 @@
+package fuzz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/schema"
+)
+
+// caseFor returns the case for a path and mutation
+func caseFor(t *testing.T, cases []Case, path, mutation string) Case {
+	t.Helper()
+	for _, c := range cases {
+		if c.Path == path && c.Mutation == mutation {
+			return c
+		}
+	}
+	require.Failf(t, "case not generated", "%s: %s", path, mutation)
+	return Case{}
+}
+
+func TestGenerate(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"type": "object",
+		"required": ["query"],
+		"properties": {
+			"query": {"type": "string", "minLength": 1, "maxLength": 20},
+			"limit": {"type": "integer", "minimum": 1, "maximum": 50},
+			"units": {"enum": ["c", "f"]},
+			"when": {"type": "string", "format": "date-time"},
+			"filter": {"$ref": "#/$defs/filter"}
+		},
+		"$defs": {
+			"filter": {"type": "object", "required": ["tags"], "properties": {"tags": {"type": "array", "items": {"type": "string"}, "maxItems": 3}}}
+		}
+	}`)
+	opts := Options{Seed: 3, Valid: 10, StringLength: 100, Depth: 20}
+	cases := Generate(s, opts)
+
+	// Random inputs are valid and generated the same way for the same seed
+	for _, c := range cases {
+		if c.Kind == KindValid {
+			assert.NoError(t, schema.Validate(s, c.Arguments), c.Description())
+		}
+	}
+	assert.Equal(t, cases, Generate(s, opts))
+	assert.NotEqual(t, cases, Generate(s, Options{Seed: 4, Valid: 10, StringLength: 100, Depth: 20}))
+
+	tests := []struct {
+		path     string
+		mutation string
+		kind     string
+		value    interface{}
+	}{
+		{"$.query", "empty string", KindInvalid, ""},
+		{"$.query", "minLength 1", KindBoundary, "x"},
+		{"$.query", "maxLength 20 + 1", KindInvalid, "xxxxxxxxxxxxxxxxxxxxx"},
+		{"$.query", "unicode", KindInvalid, unicodeString},
+		{"$.query", "wrong type number", KindInvalid, 12345.0},
+		{"$.limit", "maximum 50", KindBoundary, 50.0},
+		{"$.limit", "below the minimum 1", KindInvalid, 0.0},
+		{"$.limit", "fraction", KindInvalid, 1.5},
+		{"$.units", "not one of the enum values", KindInvalid, "mcp_tstr_not_in_enum"},
+		{"$.when", "invalid date-time", KindBoundary, "not a date-time"},
+		{"$.filter.tags", "empty array", KindBoundary, []interface{}{}},
+		{"$.filter.tags[0]", "control characters", KindBoundary, controlString},
+	}
+	for _, tt := range tests {
+		c := caseFor(t, cases, tt.path, tt.mutation)
+		assert.Equal(t, tt.kind, c.Kind, c.Description())
+		value := c.Arguments
+		for _, step := range []string{"query", "limit", "units", "when", "filter"} {
+			if tt.path == "$."+step {
+				value = value.(map[string]interface{})[step]
+			}
+		}
+		if tt.path == "$.filter.tags" {
+			value = value.(map[string]interface{})["filter"].(map[string]interface{})["tags"]
+		}
+		if tt.path == "$.filter.tags[0]" {
+			value = value.(map[string]interface{})["filter"].(map[string]interface{})["tags"].([]interface{})[0]
+		}
+		assert.Equal(t, tt.value, value, c.Description())
+	}
+
+	missing := caseFor(t, cases, "$.query", "missing required property")
+	assert.NotContains(t, missing.Arguments, "query")
+	assert.Equal(t, KindInvalid, missing.Kind)
+
+	huge := caseFor(t, cases, "$.query", "huge string (100 characters)")
+	assert.Len(t, huge.Arguments.(map[string]interface{})["query"], 100)
+
+	nested := caseFor(t, cases, "$", "unknown property nested 20 objects deep")
+	assert.Equal(t, 20, chainLength(nested.Arguments.(map[string]interface{})[unknownProperty]))
+
+	assert.Nil(t, caseFor(t, cases, "$", "no arguments").Arguments)
+}
+
+func TestGenerateMultipleOfWithinMaximum(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"type": "object",
+		"required": ["count", "ratio"],
+		"properties": {
+			"count": {"type": "integer", "minimum": 1, "maximum": 10, "multipleOf": 4},
+			"ratio": {"type": "number", "minimum": 0, "exclusiveMaximum": 1, "multipleOf": 0.25}
+		}
+	}`)
+
+	// Rounding up to a multiple must not pass the maximum, or random inputs come out invalid
+	for _, c := range Generate(s, Options{Seed: 1, Valid: 50, StringLength: 10, Depth: 5}) {
+		if strings.HasPrefix(c.Mutation, "random input") {
+			assert.Equal(t, KindValid, c.Kind, c.Description())
+		}
+	}
+}
+
+func TestGenerateSkipsHugeLengthBoundaries(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"type": "object",
+		"properties": {
+			"name": {"type": "string", "minLength": 2147483647, "maxLength": 2147483647},
+			"tags": {"type": "array", "minItems": 2147483647}
+		}
+	}`)
+
+	cases := Generate(s, Options{Seed: 1, Valid: 1, StringLength: 10, Depth: 5})
+	caseFor(t, cases, "$.name", "huge string (10 characters)")
+	for _, c := range cases {
+		assert.NotContains(t, c.Mutation, "Length", c.Description())
+	}
+}
+
+func TestGenerateWithoutSchema(t *testing.T) {
+	cases := Generate(nil, Options{Valid: 2, StringLength: 10, Depth: 5})
+	require.NotEmpty(t, cases)
+	assert.Equal(t, map[string]interface{}{}, cases[0].Arguments)
+	assert.Equal(t, KindValid, cases[0].Kind)
+}
+
+func TestFormatPath(t *testing.T) {
+	assert.Equal(t, "$", formatPath(nil))
+	assert.Equal(t, `$.items[2]["display name"]`, formatPath([]interface{}{"items", 2, "display name"}))
+}
@@ -0,0 +1,105 @@
+package fuzz
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxInputText is the most of an input the text report prints
+const maxInputText = 300
+
+// Report is the outcome of a fuzzing run
+type Report struct {
+	Tool  string `json:"tool"`
+	Seed  int64  `json:"seed"`
+	Cases int    `json:"cases"`
+	// Outcomes counts the outcomes of each kind of input
+	Outcomes map[string]map[string]int `json:"outcomes"`
+	Findings []Finding                 `json:"findings"`
+}
+
+// newReport creates an empty report
+func newReport(tool string, seed int64) *Report {
+	return &Report{Tool: tool, Seed: seed, Outcomes: make(map[string]map[string]int), Findings: []Finding{}}
+}
+
+// count records the outcome of a case
+func (r *Report) count(result Result) {
+	r.Cases++
+	if r.Outcomes[result.Kind] == nil {
+		r.Outcomes[result.Kind] = make(map[string]int)
+	}
+	r.Outcomes[result.Kind][result.Outcome]++
+}
+
+// WriteText writes a table of outcomes by kind of input followed by the findings
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Fuzzed tool %s with %d inputs (seed %d)\n\n", r.Tool, r.Cases, r.Seed)
+
+	fmt.Fprintf(w, "  %-10s", "INPUT")
+	for _, outcome := range Outcomes {
+		fmt.Fprintf(w, " %*s", len(outcome), strings.ToUpper(outcome))
+	}
+	fmt.Fprintln(w)
+	for _, kind := range []string{KindValid, KindBoundary, KindInvalid} {
+		counts := r.Outcomes[kind]
+		if counts == nil {
+			continue
+		}
+		fmt.Fprintf(w, "  %-10s", kind)
+		for _, outcome := range Outcomes {
+			fmt.Fprintf(w, " %*d", len(outcome), counts[outcome])
+		}
+		fmt.Fprintln(w)
+	}
+
+	if len(r.Findings) == 0 {
+		fmt.Fprintln(w, "\nNo findings.")
+		return nil
+	}
+
+	fmt.Fprintf(w, "\nFindings: %d\n", len(r.Findings))
+	for i, f := range r.Findings {
+		fmt.Fprintf(w, "\n%d. %s with %s input %s\n", i+1, strings.ToUpper(f.Outcome), f.Kind, f.Path+": "+f.Mutation)
+		if len(f.Similar) > 0 {
+			fmt.Fprintf(w, "   Also: %s\n", strings.Join(f.Similar, ", "))
+		}
+		fmt.Fprintf(w, "   %s\n", f.Message)
+		label := "Input"
+		if f.Reproduced > 0 {
+			label = fmt.Sprintf("Minimal input (shrunk in %d calls)", f.ShrinkCalls)
+		}
+		fmt.Fprintf(w, "   %s: %s\n", label, formatInput(f.Minimal))
+		if stderr := strings.TrimSpace(f.Stderr); stderr != "" {
+			fmt.Fprintln(w, "   Server stderr:")
+			for _, line := range strings.Split(stderr, "\n") {
+				fmt.Fprintf(w, "     | %s\n", line)
+			}
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// formatInput encodes an input, shortening long ones
+func formatInput(input interface{}) string {
+	if input == nil {
+		return "no arguments"
+	}
+	data, err := json.Marshal(input)
+	if err != nil {
+		return fmt.Sprintf("%v", input)
+	}
+	if len(data) > maxInputText {
+		return fmt.Sprintf("%s... (%d bytes; use -o json for the full input)", data[:maxInputText], len(data))
+	}
+	return string(data)
+}
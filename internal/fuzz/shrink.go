@@ -0,0 +1,223 @@
+package fuzz
+
+import (
+	"context"
+	"sort"
+	"unicode/utf8"
+)
+
+// maxShrinkDepth bounds how deep into an input candidates are made; deeper nesting is cut down
+// by hoisting values up instead
+const maxShrinkDepth = 32
+
+// Finding is an input that the server handled badly, with the smallest input found that does
+// the same
+type Finding struct {
+	Outcome  string `json:"outcome"`
+	Kind     string `json:"kind"`
+	Path     string `json:"path"`
+	Mutation string `json:"mutation"`
+	Message  string `json:"message"`
+	// Input is the generated input; Minimal reproduces the same outcome with less
+	Input   interface{} `json:"input"`
+	Minimal interface{} `json:"minimal"`
+	// ShrinkCalls is the number of calls spent shrinking, of which Reproduced gave the outcome again
+	ShrinkCalls int    `json:"shrink_calls"`
+	Reproduced  int    `json:"reproduced"`
+	Stderr      string `json:"stderr,omitempty"`
+	// Similar lists the other cases at the same path with the same outcome and message
+	Similar []string `json:"similar,omitempty"`
+}
+
+// group turns the results with problem outcomes into findings, one per path, outcome and
+// message, in the order they were first seen
+func group(results []Result) []Finding {
+	var findings []Finding
+	index := make(map[string]int)
+	for _, result := range results {
+		if !IsFinding(result.Outcome) {
+			continue
+		}
+		key := result.Path + "\x00" + result.Outcome + "\x00" + result.Message
+		if i, ok := index[key]; ok {
+			findings[i].Similar = append(findings[i].Similar, result.Mutation)
+			continue
+		}
+		index[key] = len(findings)
+		findings = append(findings, Finding{
+			Outcome:  result.Outcome,
+			Kind:     result.Kind,
+			Path:     result.Path,
+			Mutation: result.Mutation,
+			Message:  result.Message,
+			Input:    result.Arguments,
+			Minimal:  result.Arguments,
+			Stderr:   result.Stderr,
+		})
+	}
+	return findings
+}
+
+// shrink looks for a smaller input with the same outcome, greedily taking the first smaller
+// candidate that reproduces it until none does or the attempts run out
+func (r *runner) shrink(ctx context.Context, f *Finding, attempts int) error {
+	for f.ShrinkCalls < attempts {
+		improved := false
+		for _, candidate := range shrinkCandidates(f.Minimal, 0) {
+			if f.ShrinkCalls >= attempts || ctx.Err() != nil {
+				return nil
+			}
+			f.ShrinkCalls++
+			outcome, _, err := r.call(ctx, candidate)
+			if err != nil {
+				return err
+			}
+			if outcome == f.Outcome {
+				f.Minimal = candidate
+				f.Reproduced++
+				improved = true
+				break
+			}
+		}
+		if !improved {
+			return nil
+		}
+	}
+	return nil
+}
+
+// shrinkCandidates returns smaller variants of a value, the biggest cuts first
+func shrinkCandidates(value interface{}, depth int) []interface{} {
+	if depth > maxShrinkDepth {
+		return nil
+	}
+
+	var candidates []interface{}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		keys := sortedKeys(value)
+		for _, key := range keys {
+			without := copyValue(value).(map[string]interface{})
+			delete(without, key)
+			candidates = append(candidates, without)
+		}
+		if depth > 0 {
+			candidates = append(candidates, hoisted(value)...)
+		}
+		for _, key := range keys {
+			for _, smaller := range shrinkCandidates(value[key], depth+1) {
+				replaced := copyValue(value).(map[string]interface{})
+				replaced[key] = smaller
+				candidates = append(candidates, replaced)
+			}
+		}
+	case []interface{}:
+		if len(value) > 0 {
+			candidates = append(candidates, []interface{}{})
+		}
+		if len(value) > 1 {
+			half := len(value) / 2
+			candidates = append(candidates, copyValue(value[:half]), copyValue(value[half:]))
+		}
+		if depth > 0 {
+			candidates = append(candidates, hoisted(value)...)
+		}
+		// Removing single items and shrinking each one is only worth it for short arrays
+		if len(value) <= 8 {
+			for i := range value {
+				without := append(copyValue(value[:i]).([]interface{}), copyValue(value[i+1:]).([]interface{})...)
+				if len(value) > 2 {
+					candidates = append(candidates, without)
+				}
+				for _, smaller := range shrinkCandidates(value[i], depth+1) {
+					replaced := copyValue(value).([]interface{})
+					replaced[i] = smaller
+					candidates = append(candidates, replaced)
+				}
+			}
+		}
+	case string:
+		if value != "" {
+			candidates = append(candidates, "")
+		}
+		if n := utf8.RuneCountInString(value); n > 1 {
+			runes := []rune(value)
+			candidates = append(candidates, string(runes[:n/2]), string(runes[n/2:]))
+		}
+	case float64:
+		if value != 0 {
+			candidates = append(candidates, 0.0)
+		}
+	}
+	return candidates
+}
+
+// hoisted returns values to replace a container with: the value halfway down a chain of
+// single-child containers, which cuts deep nesting quickly, then the container's children
+func hoisted(value interface{}) []interface{} {
+	var candidates []interface{}
+	if chain := chainLength(value); chain >= 4 {
+		candidates = append(candidates, descend(value, chain/2))
+	}
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(value) {
+			candidates = append(candidates, value[key])
+		}
+	case []interface{}:
+		for _, item := range value {
+			candidates = append(candidates, item)
+		}
+	}
+	// A huge container has too many children to try each one
+	if len(candidates) > 8 {
+		candidates = candidates[:8]
+	}
+	return candidates
+}
+
+// chainLength counts the single-child containers nested in each other from a value down
+func chainLength(value interface{}) int {
+	n := 0
+	for {
+		next, ok := onlyChild(value)
+		if !ok {
+			return n
+		}
+		value = next
+		n++
+	}
+}
+
+// descend follows a chain of single-child containers for n levels
+func descend(value interface{}, n int) interface{} {
+	for i := 0; i < n; i++ {
+		value, _ = onlyChild(value)
+	}
+	return value
+}
+
+// onlyChild returns the child of an object or array that has exactly one
+func onlyChild(value interface{}) (interface{}, bool) {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		if len(value) == 1 {
+			for _, child := range value {
+				return child, true
+			}
+		}
+	case []interface{}:
+		if len(value) == 1 {
+			return value[0], true
+		}
+	}
+	return nil, false
+}
+
+// sortFindings orders findings by severity, keeping the order they were seen in otherwise
+func sortFindings(findings []Finding) {
+	severity := map[string]int{OutcomeCrash: 0, OutcomeDisconnect: 1, OutcomeTimeout: 2, OutcomeProtocolError: 3}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return severity[findings[i].Outcome] < severity[findings[j].Outcome]
+	})
+}
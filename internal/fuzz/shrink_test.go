@@ -0,0 +1,88 @@
+package fuzz
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShrinkCandidates(t *testing.T) {
+	candidates := shrinkCandidates(map[string]interface{}{"a": "xyzw", "b": 2.0}, 0)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"b": 2.0},
+		map[string]interface{}{"a": "xyzw"},
+		map[string]interface{}{"a": "", "b": 2.0},
+		map[string]interface{}{"a": "xy", "b": 2.0},
+		map[string]interface{}{"a": "zw", "b": 2.0},
+		map[string]interface{}{"a": "xyzw", "b": 0.0},
+	}, candidates)
+
+	assert.Empty(t, shrinkCandidates(map[string]interface{}{}, 0))
+	assert.Empty(t, shrinkCandidates(0.0, 0))
+	assert.Empty(t, shrinkCandidates(nil, 0))
+	// Strings are cut between characters, not bytes
+	assert.Contains(t, shrinkCandidates("🦀🦀", 0), "🦀")
+
+	candidates = shrinkCandidates([]interface{}{1.0, 2.0, 3.0}, 1)
+	assert.Contains(t, candidates, []interface{}{})
+	assert.Contains(t, candidates, []interface{}{1.0})
+	assert.Contains(t, candidates, []interface{}{2.0, 3.0})
+	assert.Contains(t, candidates, []interface{}{1.0, 3.0})
+	assert.Contains(t, candidates, 2.0)
+}
+
+func TestHoistedHalvesDeepNesting(t *testing.T) {
+	candidates := hoisted(nestedArray(100))
+	assert.Equal(t, 50, chainLength(candidates[0]))
+	assert.Equal(t, 99, chainLength(candidates[1]))
+}
+
+func TestShrinkFindsMinimalInput(t *testing.T) {
+	// Greedy shrinking against a predicate needs no server: a string over 100 characters in
+	// a deeply nested value reproduces
+	reproduces := func(value interface{}) bool {
+		var long func(v interface{}) bool
+		long = func(v interface{}) bool {
+			switch v := v.(type) {
+			case string:
+				return len(v) > 100
+			case map[string]interface{}:
+				for _, child := range v {
+					if long(child) {
+						return true
+					}
+				}
+			case []interface{}:
+				for _, child := range v {
+					if long(child) {
+						return true
+					}
+				}
+			}
+			return false
+		}
+		return long(value)
+	}
+
+	var current interface{} = map[string]interface{}{
+		"keep":  []interface{}{strings.Repeat("x", 1000), "y"},
+		"other": nestedObject(40),
+	}
+	for calls := 0; calls < 200; {
+		improved := false
+		for _, candidate := range shrinkCandidates(current, 0) {
+			calls++
+			if reproduces(candidate) {
+				current, improved = candidate, true
+				break
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+
+	// The array is hoisted away and the string halved until it would be too short
+	assert.Equal(t, map[string]interface{}{"keep": strings.Repeat("x", 125)}, current)
+}
@@ -0,0 +1,378 @@
+// Package gateway serves the tools, prompts and resources of every connected server as a single
+// MCP server, so hosts only need to configure one server
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+
+	"mcp_tstr/internal/constants"
+	"mcp_tstr/internal/mcp"
+)
+
+// Options controls what the gateway exposes
+type Options struct {
+	// Naming is the tool naming mode of the manager, which prompt and resource names follow too
+	Naming string
+	// Prompts and Resources filter prompts and resources by their server-side names, keyed by
+	// server as returned by mcp.ParseFilters
+	Prompts   map[string]mcp.ToolFilter
+	Resources map[string]mcp.ToolFilter
+}
+
+// Gateway keeps an MCP server in sync with the servers of a manager
+type Gateway struct {
+	manager *mcp.Manager
+	opts    Options
+	server  *sdk.Server
+	logger  *logrus.Logger
+
+	// mu serializes refreshes; the maps hold a fingerprint of every exposed item, so a refresh
+	// only replaces what changed and hosts are not notified for nothing
+	mu        sync.Mutex
+	tools     map[string]string
+	prompts   map[string]string
+	resources map[string]string
+}
+
+// New creates a gateway for the servers of a manager. Nothing is exposed until Refresh is called.
+func New(manager *mcp.Manager, opts Options, logger *logrus.Logger) *Gateway {
+	if opts.Naming == "" {
+		opts.Naming = mcp.ToolNamingAuto
+	}
+
+	g := &Gateway{
+		manager:   manager,
+		opts:      opts,
+		server:    sdk.NewServer(constants.AppName, constants.AppVersion, nil),
+		logger:    logger,
+		tools:     make(map[string]string),
+		prompts:   make(map[string]string),
+		resources: make(map[string]string),
+	}
+	g.server.AddReceivingMiddleware(g.withInstructions)
+	return g
+}
+
+// withInstructions fills in the instructions of every initialize result, so each host session
+// is told about the servers connected when it starts
+func (g *Gateway) withInstructions(next sdk.MethodHandler[*sdk.ServerSession]) sdk.MethodHandler[*sdk.ServerSession] {
+	return func(ctx context.Context, session *sdk.ServerSession, method string, params sdk.Params) (sdk.Result, error) {
+		result, err := next(ctx, session, method, params)
+		if initialized, ok := result.(*sdk.InitializeResult); ok && err == nil {
+			initialized.Instructions = g.instructions()
+		}
+		return result, err
+	}
+}
+
+// instructions describes the manager's servers and how their names are prefixed
+func (g *Gateway) instructions() string {
+	servers := strings.Join(sortedNames(g.manager.GetAllClients()), ", ")
+	if g.opts.Naming == mcp.ToolNamingNamespace {
+		return fmt.Sprintf("Tools, prompts and resources of the MCP servers %s. Names are prefixed with the server name and %q.",
+			servers, mcp.ToolNamespaceSeparator)
+	}
+	return fmt.Sprintf("Tools, prompts and resources of the MCP servers %s. Names exported by several servers are prefixed with the server name and %q.",
+		servers, mcp.ToolNamespaceSeparator)
+}
+
+// sortedNames returns the names of servers in order
+func sortedNames(clients map[string]*mcp.Client) []string {
+	servers := make([]string, 0, len(clients))
+	for name := range clients {
+		servers = append(servers, name)
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// Server returns the MCP server hosts connect to
+func (g *Gateway) Server() *sdk.Server {
+	return g.server
+}
+
+// Refresh lists every server again and updates the exposed tools, prompts and resources. Hosts
+// are sent list_changed notifications for the lists that changed.
+func (g *Gateway) Refresh(ctx context.Context) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	entries, err := g.manager.Tools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+	g.syncTools(entries)
+
+	clients := g.manager.GetAllClients()
+	servers := sortedNames(clients)
+
+	g.syncPrompts(ctx, servers, clients)
+	g.syncResources(ctx, servers, clients)
+	return nil
+}
+
+// HandleListChanged refreshes the gateway when a server announces changed tools, prompts or
+// resources. It can be passed to Manager.SetListChangedHandler.
+func (g *Gateway) HandleListChanged(server, list string) {
+	g.logger.WithField("server", server).Debugf("Server %s changed, refreshing", list)
+	go g.refresh()
+}
+
+// HandleStateChange refreshes the gateway when a server connects or goes away. It can be passed
+// to Manager.SetStateHandler.
+func (g *Gateway) HandleStateChange(change mcp.StateChange) {
+	switch change.State {
+	case mcp.StateConnected, mcp.StateFailed, mcp.StateDisabled:
+		g.logger.WithField("server", change.Server).Debugf("Server %s, refreshing", change.State)
+		go g.refresh()
+	}
+}
+
+// refresh runs a refresh in the background, where there is no caller to return errors to
+func (g *Gateway) refresh() {
+	if err := g.Refresh(context.Background()); err != nil {
+		g.logger.WithError(err).Warn("Failed to refresh gateway")
+	}
+}
+
+// syncTools exposes the manager's tools under their unique names
+func (g *Gateway) syncTools(entries []mcp.ToolEntry) {
+	wanted := make(map[string]string, len(entries))
+	byName := make(map[string]mcp.ToolEntry, len(entries))
+	for _, entry := range entries {
+		wanted[entry.Name] = fingerprint(entry.Server, entry.Tool)
+		byName[entry.Name] = entry
+	}
+
+	removed, added := compare(g.tools, wanted)
+	if len(removed) > 0 {
+		g.server.RemoveTools(removed...)
+	}
+	tools := make([]*sdk.ServerTool, 0, len(added))
+	for _, name := range added {
+		entry := byName[name]
+		tool, err := serverTool(name, entry.Tool)
+		if err != nil {
+			g.logger.WithError(err).WithField("server", entry.Server).Warnf("Skipping tool %s", entry.Tool.Name)
+			delete(wanted, name)
+			continue
+		}
+		tools = append(tools, &sdk.ServerTool{Tool: tool, Handler: g.callTool(name)})
+	}
+	g.server.AddTools(tools...)
+	g.tools = wanted
+}
+
+// serverTool copies a tool to expose it under another name. The SDK resolves the input schema
+// in place when the tool is added, so the copy is deep and its schema is checked on a copy of
+// its own first, as AddTools panics on schemas it cannot resolve.
+func serverTool(name string, tool *sdk.Tool) (*sdk.Tool, error) {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy tool: %w", err)
+	}
+	var exposed, check sdk.Tool
+	if err := json.Unmarshal(data, &exposed); err != nil {
+		return nil, fmt.Errorf("failed to copy tool: %w", err)
+	}
+	if err := json.Unmarshal(data, &check); err != nil {
+		return nil, fmt.Errorf("failed to copy tool: %w", err)
+	}
+	if check.InputSchema != nil {
+		if _, err := check.InputSchema.Resolve(&jsonschema.ResolveOptions{ValidateDefaults: true}); err != nil {
+			return nil, fmt.Errorf("invalid input schema: %w", err)
+		}
+	}
+	exposed.Name = name
+	return &exposed, nil
+}
+
+// callTool forwards calls of an exposed tool to the server that owns it
+func (g *Gateway) callTool(name string) sdk.ToolHandler {
+	return func(ctx context.Context, ss *sdk.ServerSession, params *sdk.CallToolParamsFor[map[string]any]) (*sdk.CallToolResult, error) {
+		client, entry, err := g.manager.ResolveTool(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		return client.CallTool(ctx, entry.Tool.Name, params.Arguments)
+	}
+}
+
+// syncPrompts exposes the allowed prompts of every server
+func (g *Gateway) syncPrompts(ctx context.Context, servers []string, clients map[string]*mcp.Client) {
+	var listed []item
+	for _, server := range servers {
+		result, err := clients[server].ListPrompts(ctx)
+		if err != nil {
+			// Prompts are optional, so servers without them are not worth a warning
+			g.logger.WithError(err).WithField("server", server).Debug("Failed to list prompts")
+			continue
+		}
+		for _, prompt := range result.Prompts {
+			if allowed(g.opts.Prompts, server, prompt.Name) {
+				listed = append(listed, item{server: server, name: prompt.Name, value: prompt})
+			}
+		}
+	}
+
+	wanted := make(map[string]string, len(listed))
+	byName := make(map[string]item, len(listed))
+	for _, it := range namespace(listed, g.opts.Naming) {
+		wanted[it.exposed] = fingerprint(it.server, it.value)
+		byName[it.exposed] = it
+	}
+
+	removed, added := compare(g.prompts, wanted)
+	if len(removed) > 0 {
+		g.server.RemovePrompts(removed...)
+	}
+	prompts := make([]*sdk.ServerPrompt, 0, len(added))
+	for _, name := range added {
+		it := byName[name]
+		prompt := *it.value.(*sdk.Prompt)
+		prompt.Name = name
+		prompts = append(prompts, &sdk.ServerPrompt{Prompt: &prompt, Handler: g.getPrompt(it.server, it.name)})
+	}
+	g.server.AddPrompts(prompts...)
+	g.prompts = wanted
+}
+
+// getPrompt forwards requests for an exposed prompt to the server that owns it
+func (g *Gateway) getPrompt(server, name string) sdk.PromptHandler {
+	return func(ctx context.Context, ss *sdk.ServerSession, params *sdk.GetPromptParams) (*sdk.GetPromptResult, error) {
+		client, err := g.manager.GetClient(server)
+		if err != nil {
+			return nil, err
+		}
+		return client.GetPrompt(ctx, name, params.Arguments)
+	}
+}
+
+// syncResources exposes the allowed resources of every server under their own URIs. When
+// several servers offer the same URI, the first one in name order serves it.
+func (g *Gateway) syncResources(ctx context.Context, servers []string, clients map[string]*mcp.Client) {
+	var listed []item
+	owners := make(map[string]string)
+	for _, server := range servers {
+		result, err := clients[server].ListResources(ctx)
+		if err != nil {
+			g.logger.WithError(err).WithField("server", server).Debug("Failed to list resources")
+			continue
+		}
+		for _, resource := range result.Resources {
+			if !allowed(g.opts.Resources, server, resource.Name) {
+				continue
+			}
+			if owner, taken := owners[resource.URI]; taken {
+				g.logger.WithField("server", server).Warnf("Resource %s is already served by %s, skipping", resource.URI, owner)
+				continue
+			}
+			if u, err := url.Parse(resource.URI); err != nil || !u.IsAbs() {
+				g.logger.WithField("server", server).Warnf("Skipping resource %s without an absolute URI", resource.URI)
+				continue
+			}
+			owners[resource.URI] = server
+			listed = append(listed, item{server: server, name: resource.Name, value: resource})
+		}
+	}
+
+	wanted := make(map[string]string, len(listed))
+	byURI := make(map[string]item, len(listed))
+	for _, it := range namespace(listed, g.opts.Naming) {
+		resource := *it.value.(*sdk.Resource)
+		resource.Name = it.exposed
+		it.value = &resource
+		wanted[resource.URI] = fingerprint(it.server, it.value)
+		byURI[resource.URI] = it
+	}
+
+	removed, added := compare(g.resources, wanted)
+	if len(removed) > 0 {
+		g.server.RemoveResources(removed...)
+	}
+	resources := make([]*sdk.ServerResource, 0, len(added))
+	for _, uri := range added {
+		it := byURI[uri]
+		resources = append(resources, &sdk.ServerResource{Resource: it.value.(*sdk.Resource), Handler: g.readResource(it.server)})
+	}
+	g.server.AddResources(resources...)
+	g.resources = wanted
+}
+
+// readResource forwards reads of a resource to the server that owns it
+func (g *Gateway) readResource(server string) sdk.ResourceHandler {
+	return func(ctx context.Context, ss *sdk.ServerSession, params *sdk.ReadResourceParams) (*sdk.ReadResourceResult, error) {
+		client, err := g.manager.GetClient(server)
+		if err != nil {
+			return nil, err
+		}
+		return client.ReadResource(ctx, params.URI)
+	}
+}
+
+// item is a prompt or resource listed by a server
+type item struct {
+	server  string
+	name    string
+	exposed string
+	value   interface{}
+}
+
+// namespace assigns every item the name it is exposed under: server__name in namespace mode or
+// when several servers list the same name, and its own name otherwise
+func namespace(items []item, naming string) []item {
+	owners := make(map[string]int)
+	for _, it := range items {
+		owners[it.name]++
+	}
+	for i, it := range items {
+		items[i].exposed = it.name
+		if naming == mcp.ToolNamingNamespace || owners[it.name] > 1 {
+			items[i].exposed = it.server + mcp.ToolNamespaceSeparator + it.name
+		}
+	}
+	return items
+}
+
+// allowed reports whether the filters for every server and for this server let a name through
+func allowed(filters map[string]mcp.ToolFilter, server, name string) bool {
+	return filters[""].Allows(name) && filters[server].Allows(name)
+}
+
+// fingerprint identifies the definition of an item and the server it comes from
+func fingerprint(server string, definition interface{}) string {
+	data, err := json.Marshal(definition)
+	if err != nil {
+		// Unencodable definitions never match, so they are always replaced
+		return ""
+	}
+	return server + "\x00" + string(data)
+}
+
+// compare returns the names that are exposed but no longer wanted, and the wanted names that are
+// new or changed
+func compare(exposed, wanted map[string]string) (removed, added []string) {
+	for name := range exposed {
+		if _, ok := wanted[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	for name, fp := range wanted {
+		if current, ok := exposed[name]; !ok || current != fp || fp == "" {
+			added = append(added, name)
+		}
+	}
+	sort.Strings(removed)
+	sort.Strings(added)
+	return removed, added
+}
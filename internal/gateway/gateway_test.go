@@ -0,0 +1,227 @@
+package gateway
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/mockserver"
+)
+
+// quietLogger discards the logs of the gateway and its manager
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// indexTool is a tool only the docs server offers, so its name never collides
+var indexTool = mockserver.Tool{Name: "index", Responses: []mockserver.Response{{Text: "indexed"}}}
+
+// startGateway connects a manager to the docs and issues mock servers and returns a host session
+// of a refreshed gateway in front of them
+func startGateway(t *testing.T, naming string, opts Options, configure func(*mcp.Manager)) (*mcp.Manager, *sdk.ClientSession) {
+	manager := mcp.NewManager(quietLogger())
+	t.Cleanup(func() { manager.Close() })
+	if configure != nil {
+		configure(manager)
+	}
+
+	mcpConfig := &config.MCPConfig{ToolNaming: naming, Servers: map[string]config.MCPServer{
+		"docs":   mcptest.MockServerConfig(t, mcptest.SearchFixture("docs", indexTool), mockserver.Options{}),
+		"issues": mcptest.MockServerConfig(t, mcptest.SearchFixture("issues"), mockserver.Options{}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	opts.Naming = naming
+	_, session := connectHost(t, manager, opts, nil)
+	return manager, session
+}
+
+// connectHost refreshes a gateway for the manager's servers and connects a host to it in memory
+func connectHost(t *testing.T, manager *mcp.Manager, opts Options, hostOpts *sdk.ClientOptions) (*Gateway, *sdk.ClientSession) {
+	g := New(manager, opts, quietLogger())
+	manager.SetListChangedHandler(g.HandleListChanged)
+	manager.SetStateHandler(g.HandleStateChange)
+	require.NoError(t, g.Refresh(context.Background()))
+
+	serverTransport, hostTransport := sdk.NewInMemoryTransports()
+	serverSession, err := g.Server().Connect(context.Background(), serverTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { serverSession.Close() })
+
+	session, err := sdk.NewClient("host", "0.0.1", hostOpts).Connect(context.Background(), hostTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { session.Close() })
+	return g, session
+}
+
+// toolNames lists the names of the tools a host sees
+func toolNames(t *testing.T, session *sdk.ClientSession) []string {
+	t.Helper()
+	result, err := session.ListTools(context.Background(), &sdk.ListToolsParams{})
+	require.NoError(t, err)
+	var names []string
+	for _, tool := range result.Tools {
+		names = append(names, tool.Name)
+	}
+	return names
+}
+
+// text returns the text of the first content block
+func text(t *testing.T, content []sdk.Content) string {
+	t.Helper()
+	require.NotEmpty(t, content)
+	textContent, ok := content[0].(*sdk.TextContent)
+	require.True(t, ok)
+	return textContent.Text
+}
+
+func TestGatewayNamespacesEveryServer(t *testing.T) {
+	_, session := startGateway(t, mcp.ToolNamingNamespace, Options{}, nil)
+	ctx := context.Background()
+
+	assert.ElementsMatch(t, []string{"docs__index", "docs__search", "issues__search"}, toolNames(t, session))
+
+	result, err := session.CallTool(ctx, &sdk.CallToolParams{Name: "issues__search", Arguments: map[string]interface{}{"query": "bug"}})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+	assert.Equal(t, "issues found bug", text(t, result.Content))
+
+	prompts, err := session.ListPrompts(ctx, &sdk.ListPromptsParams{})
+	require.NoError(t, err)
+	require.Len(t, prompts.Prompts, 2)
+	prompt, err := session.GetPrompt(ctx, &sdk.GetPromptParams{Name: "docs__greet", Arguments: map[string]string{"who": "Ada"}})
+	require.NoError(t, err)
+	require.Len(t, prompt.Messages, 1)
+	assert.Equal(t, "docs says hello to Ada", prompt.Messages[0].Content.(*sdk.TextContent).Text)
+
+	resources, err := session.ListResources(ctx, &sdk.ListResourcesParams{})
+	require.NoError(t, err)
+	require.Len(t, resources.Resources, 2)
+	assert.Equal(t, "mem://docs", resources.Resources[0].URI)
+	assert.Equal(t, "docs__docs", resources.Resources[0].Name)
+	read, err := session.ReadResource(ctx, &sdk.ReadResourceParams{URI: "mem://issues"})
+	require.NoError(t, err)
+	require.Len(t, read.Contents, 1)
+	assert.Equal(t, "about issues", read.Contents[0].Text)
+}
+
+func TestGatewayAutoNaming(t *testing.T) {
+	_, session := startGateway(t, mcp.ToolNamingAuto, Options{}, nil)
+
+	assert.ElementsMatch(t, []string{"index", "docs__search", "issues__search"}, toolNames(t, session))
+
+	resources, err := session.ListResources(context.Background(), &sdk.ListResourcesParams{})
+	require.NoError(t, err)
+	require.Len(t, resources.Resources, 2)
+	assert.Equal(t, "docs", resources.Resources[0].Name)
+}
+
+func TestGatewayFilters(t *testing.T) {
+	prompts, err := mcp.ParseFilters(nil, []string{"issues:greet"})
+	require.NoError(t, err)
+	resources, err := mcp.ParseFilters(nil, []string{"docs"})
+	require.NoError(t, err)
+
+	_, session := startGateway(t, mcp.ToolNamingAuto, Options{Prompts: prompts, Resources: resources}, func(manager *mcp.Manager) {
+		require.NoError(t, manager.AddToolFilters(nil, []string{"issues:*"}))
+	})
+	ctx := context.Background()
+
+	// With the issues tools filtered out, search no longer collides
+	assert.ElementsMatch(t, []string{"index", "search"}, toolNames(t, session))
+
+	promptList, err := session.ListPrompts(ctx, &sdk.ListPromptsParams{})
+	require.NoError(t, err)
+	require.Len(t, promptList.Prompts, 1)
+	assert.Equal(t, "greet", promptList.Prompts[0].Name)
+
+	resourceList, err := session.ListResources(ctx, &sdk.ListResourcesParams{})
+	require.NoError(t, err)
+	require.Len(t, resourceList.Resources, 1)
+	assert.Equal(t, "mem://issues", resourceList.Resources[0].URI)
+}
+
+func TestGatewayUnknownTool(t *testing.T) {
+	manager, session := startGateway(t, mcp.ToolNamingNamespace, Options{}, nil)
+
+	// A tool that disappears between listing and calling is reported as a tool error
+	require.NoError(t, manager.DisableServer("issues"))
+	result, err := session.CallTool(context.Background(), &sdk.CallToolParams{Name: "issues__search", Arguments: map[string]interface{}{"query": "bug"}})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Contains(t, text(t, result.Content), "not found")
+}
+
+func TestGatewayPropagatesListChanged(t *testing.T) {
+	upstream := sdk.NewServer("live", "1.0.0", nil)
+	upstream.AddTools(sdk.NewServerTool("first", "The first tool", func(ctx context.Context, ss *sdk.ServerSession, params *sdk.CallToolParamsFor[struct{}]) (*sdk.CallToolResultFor[any], error) {
+		return &sdk.CallToolResultFor[any]{Content: []sdk.Content{&sdk.TextContent{Text: "first"}}}, nil
+	}))
+	handler := sdk.NewStreamableHTTPHandler(func(*http.Request) *sdk.Server { return upstream }, nil)
+
+	manager := mcp.NewManager(quietLogger())
+	t.Cleanup(func() { manager.Close() })
+	mcpConfig := &config.MCPConfig{ToolNaming: mcp.ToolNamingNamespace, Servers: map[string]config.MCPServer{"live": mcptest.ServerConfig(t, handler)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	changed := make(chan struct{}, 4)
+	g, session := connectHost(t, manager, Options{Naming: mcp.ToolNamingNamespace}, &sdk.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *sdk.ClientSession, *sdk.ToolListChangedParams) {
+			changed <- struct{}{}
+		},
+	})
+	assert.Equal(t, []string{"live__first"}, toolNames(t, session))
+
+	upstream.AddTools(sdk.NewServerTool("second", "The second tool", func(ctx context.Context, ss *sdk.ServerSession, params *sdk.CallToolParamsFor[struct{}]) (*sdk.CallToolResultFor[any], error) {
+		return &sdk.CallToolResultFor[any]{Content: []sdk.Content{&sdk.TextContent{Text: "second"}}}, nil
+	}))
+	// The SDK's streamable HTTP client has no stream for server notifications, so this does what
+	// the manager does when one arrives
+	manager.InvalidateTools()
+	g.HandleListChanged("live", mcp.ListTools)
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("host was not notified of the new tool")
+	}
+	assert.ElementsMatch(t, []string{"live__first", "live__second"}, toolNames(t, session))
+}
+
+func TestGatewayInstructionsFollowServers(t *testing.T) {
+	manager, _ := startGateway(t, mcp.ToolNamingNamespace, Options{}, nil)
+	g := New(manager, Options{Naming: mcp.ToolNamingNamespace}, quietLogger())
+	initialize := g.withInstructions(func(context.Context, *sdk.ServerSession, string, sdk.Params) (sdk.Result, error) {
+		return &sdk.InitializeResult{}, nil
+	})
+	instructions := func() string {
+		result, err := initialize(context.Background(), nil, "initialize", nil)
+		require.NoError(t, err)
+		return result.(*sdk.InitializeResult).Instructions
+	}
+
+	assert.Contains(t, instructions(), "servers docs, issues.")
+	require.NoError(t, manager.RemoveServer("issues"))
+	assert.Contains(t, instructions(), "servers docs.")
+}
+
+func TestCompare(t *testing.T) {
+	removed, added := compare(
+		map[string]string{"kept": "a", "changed": "b", "gone": "c"},
+		map[string]string{"kept": "a", "changed": "B", "new": "d"},
+	)
+	assert.Equal(t, []string{"gone"}, removed)
+	assert.Equal(t, []string{"changed", "new"}, added)
+}
@@ -0,0 +1,36 @@
+// Package jsonrpc holds the JSON-RPC error object, error codes and protocol versions shared by
+// the raw clients and the mock server
+package jsonrpc
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SpecVersion is the protocol revision the raw clients request and the mock server prefers
+const SpecVersion = "2025-06-18"
+
+// KnownVersions are the published protocol revisions a server may negotiate
+var KnownVersions = []string{"2024-11-05", "2025-03-26", "2025-06-18"}
+
+// JSON-RPC error codes used by MCP
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeResourceNotFound is MCP's code for reading an unknown resource
+	CodeResourceNotFound = -32002
+)
+
+// Error is a JSON-RPC error object
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("JSON-RPC error %d: %s", e.Code, e.Message)
+}
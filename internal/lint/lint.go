@@ -0,0 +1,413 @@
+// Package lint flags tool definitions that are invalid or likely to confuse models
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// Severity ranks how much an issue matters
+type Severity string
+
+const (
+	// SeverityError breaks tool use with some clients or providers
+	SeverityError Severity = "error"
+	// SeverityWarning is likely to make models use the tool badly
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is worth improving
+	SeverityInfo Severity = "info"
+)
+
+// rank orders severities from most to least severe
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	}
+	return 2
+}
+
+// AtLeast reports whether s is as severe as other
+func (s Severity) AtLeast(other Severity) bool {
+	return s.rank() <= other.rank()
+}
+
+// ParseSeverity converts a flag value into a Severity
+func ParseSeverity(value string) (Severity, error) {
+	switch severity := Severity(strings.ToLower(value)); severity {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return severity, nil
+	}
+	return "", fmt.Errorf("invalid severity %q (expected %s, %s or %s)", value, SeverityError, SeverityWarning, SeverityInfo)
+}
+
+// Rules
+const (
+	RuleInvalidTool         = "invalid-tool"
+	RuleInvalidName         = "invalid-name"
+	RuleDuplicateName       = "duplicate-name"
+	RuleMissingDescription  = "missing-description"
+	RuleLongDescription     = "long-description"
+	RuleMissingInputSchema  = "missing-input-schema"
+	RuleInvalidSchema       = "invalid-schema"
+	RuleUntypedProperty     = "untyped-property"
+	RuleRequiredNotDeclared = "required-not-in-properties"
+)
+
+// DefaultMaxDescription is the longest tool description OpenAI accepts
+const DefaultMaxDescription = 1024
+
+// openAIName is the pattern OpenAI requires function names to match
+var openAIName = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// jsonTypes are the type names JSON Schema defines
+var jsonTypes = map[string]bool{
+	"null": true, "boolean": true, "object": true, "array": true, "number": true, "string": true, "integer": true,
+}
+
+// Issue is one problem found in a tool definition
+type Issue struct {
+	Server   string   `json:"server"`
+	Tool     string   `json:"tool"`
+	Rule     string   `json:"rule"`
+	Severity Severity `json:"severity"`
+	// Path locates the problem inside the tool definition, such as inputSchema.properties.city
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+// Options tunes the linter
+type Options struct {
+	// MaxDescription is the longest acceptable tool or property description
+	MaxDescription int
+}
+
+// Linter collects issues in the tools of one or more servers
+type Linter struct {
+	opts   Options
+	issues []Issue
+	// servers and tools count what was linted
+	servers int
+	tools   int
+	// owners records which servers define each tool name
+	owners map[string][]string
+}
+
+// New creates a linter
+func New(opts Options) *Linter {
+	if opts.MaxDescription <= 0 {
+		opts.MaxDescription = DefaultMaxDescription
+	}
+	return &Linter{opts: opts, owners: make(map[string][]string)}
+}
+
+// AddServer lints the tools of a server as listed by tools/list. Each tool is decoded on its
+// own, so a malformed tool or schema is reported without hiding the others.
+func (l *Linter) AddServer(server string, tools []json.RawMessage) {
+	l.servers++
+	l.tools += len(tools)
+	listed := make(map[string]int)
+	var names []string
+	for i, data := range tools {
+		var tool rawTool
+		if err := json.Unmarshal(data, &tool); err != nil {
+			l.issues = append(l.issues, Issue{
+				Server:   server,
+				Tool:     fmt.Sprintf("#%d", i+1),
+				Rule:     RuleInvalidTool,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("tool is not a valid tool definition: %v", err),
+			})
+			continue
+		}
+		l.lintTool(server, tool)
+
+		if listed[tool.Name] == 0 {
+			names = append(names, tool.Name)
+		}
+		listed[tool.Name]++
+	}
+
+	for _, name := range names {
+		if count := listed[name]; count > 1 {
+			l.issues = append(l.issues, Issue{
+				Server:   server,
+				Tool:     name,
+				Rule:     RuleDuplicateName,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("tool is listed %d times; clients can only call one of them", count),
+			})
+		}
+		l.owners[name] = append(l.owners[name], server)
+	}
+}
+
+// rawTool is a tool from tools/list with its schemas left undecoded
+type rawTool struct {
+	Name         string          `json:"name"`
+	Description  string          `json:"description"`
+	InputSchema  json.RawMessage `json:"inputSchema"`
+	OutputSchema json.RawMessage `json:"outputSchema"`
+}
+
+// Issues returns every issue found, including tool names shared by several servers, sorted by
+// server, tool and severity
+func (l *Linter) Issues() []Issue {
+	issues := append([]Issue(nil), l.issues...)
+	for name, servers := range l.owners {
+		if len(servers) < 2 {
+			continue
+		}
+		for _, server := range servers {
+			issues = append(issues, Issue{
+				Server:   server,
+				Tool:     name,
+				Rule:     RuleDuplicateName,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("tool name is also used by %s; clients must rename one of them", strings.Join(others(servers, server), ", ")),
+			})
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		a, b := issues[i], issues[j]
+		if a.Server != b.Server {
+			return a.Server < b.Server
+		}
+		if a.Tool != b.Tool {
+			return a.Tool < b.Tool
+		}
+		return a.Severity.rank() < b.Severity.rank()
+	})
+	return issues
+}
+
+// lintTool checks a tool's name, description and schemas
+func (l *Linter) lintTool(server string, tool rawTool) {
+	add := func(rule string, severity Severity, path, format string, args ...interface{}) {
+		l.issues = append(l.issues, Issue{
+			Server:   server,
+			Tool:     tool.Name,
+			Rule:     rule,
+			Severity: severity,
+			Path:     path,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	if !openAIName.MatchString(tool.Name) {
+		add(RuleInvalidName, SeverityError, "name", "name %q does not match %s required by OpenAI", tool.Name, openAIName)
+	}
+	l.lintDescription(add, "description", "tool", tool.Description, SeverityWarning)
+
+	if isNull(tool.InputSchema) {
+		add(RuleMissingInputSchema, SeverityError, "inputSchema", "tool has no input schema")
+	} else {
+		l.lintSchema(add, "inputSchema", tool.InputSchema)
+	}
+	if !isNull(tool.OutputSchema) {
+		l.lintSchema(add, "outputSchema", tool.OutputSchema)
+	}
+}
+
+// addFunc records an issue for the tool being linted
+type addFunc func(rule string, severity Severity, path, format string, args ...interface{})
+
+// lintDescription flags missing and overly long descriptions
+func (l *Linter) lintDescription(add addFunc, path, subject, description string, missing Severity) {
+	switch length := utf8.RuneCountInString(strings.TrimSpace(description)); {
+	case length == 0:
+		add(RuleMissingDescription, missing, path, "%s has no description", subject)
+	case length > l.opts.MaxDescription:
+		add(RuleLongDescription, SeverityWarning, path, "%s description is %d characters, longer than %d", subject, length, l.opts.MaxDescription)
+	}
+}
+
+// lintSchema decodes a tool schema and checks that it is a well-formed object schema, then
+// checks its properties
+func (l *Linter) lintSchema(add addFunc, path string, data json.RawMessage) {
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		add(RuleInvalidSchema, SeverityError, path, "schema cannot be decoded: %v", err)
+		return
+	}
+	if _, err := s.Resolve(nil); err != nil {
+		add(RuleInvalidSchema, SeverityError, path, "invalid JSON Schema: %v", err)
+	}
+	if s.Type != "object" {
+		add(RuleInvalidSchema, SeverityError, path+".type", "schema type must be \"object\", got %s", describeType(&s))
+	}
+	l.lintObject(add, path, &s)
+}
+
+// lintObject checks the properties and required list of an object schema, recursing into
+// nested objects and array items
+func (l *Linter) lintObject(add addFunc, path string, s *jsonschema.Schema) {
+	l.lintTypes(add, path, s)
+
+	for _, name := range s.Required {
+		if _, ok := s.Properties[name]; !ok {
+			add(RuleRequiredNotDeclared, SeverityError, path+".required", "%q is required but not defined in properties", name)
+		}
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		property := s.Properties[name]
+		propertyPath := path + ".properties." + name
+		if property == nil {
+			continue
+		}
+		l.lintDescription(add, propertyPath+".description", fmt.Sprintf("property %q", name), property.Description, SeverityInfo)
+		if !typed(property) {
+			add(RuleUntypedProperty, SeverityWarning, propertyPath, "property %q has no type", name)
+		}
+		l.lintNested(add, propertyPath, property)
+	}
+}
+
+// lintNested descends into object properties and array items
+func (l *Linter) lintNested(add addFunc, path string, s *jsonschema.Schema) {
+	switch {
+	case s.Properties != nil || s.Required != nil:
+		l.lintObject(add, path, s)
+	case s.Items != nil:
+		l.lintTypes(add, path, s)
+		if !typed(s.Items) {
+			add(RuleUntypedProperty, SeverityWarning, path+".items", "array items have no type")
+		}
+		l.lintNested(add, path+".items", s.Items)
+	default:
+		l.lintTypes(add, path, s)
+	}
+}
+
+// lintTypes flags type names JSON Schema does not define
+func (l *Linter) lintTypes(add addFunc, path string, s *jsonschema.Schema) {
+	types := s.Types
+	if s.Type != "" {
+		types = []string{s.Type}
+	}
+	for _, t := range types {
+		if !jsonTypes[t] {
+			add(RuleInvalidSchema, SeverityError, path+".type", "unknown type %q", t)
+		}
+	}
+}
+
+// typed reports whether a schema constrains the type of its values
+func typed(s *jsonschema.Schema) bool {
+	return s.Type != "" || len(s.Types) > 0 || s.Enum != nil || s.Const != nil || s.Ref != "" ||
+		len(s.AnyOf) > 0 || len(s.OneOf) > 0 || len(s.AllOf) > 0
+}
+
+// describeType renders the declared type of a schema for messages
+func describeType(s *jsonschema.Schema) string {
+	switch {
+	case s.Type != "":
+		return fmt.Sprintf("%q", s.Type)
+	case len(s.Types) > 0:
+		return fmt.Sprintf("%q", s.Types)
+	}
+	return "none"
+}
+
+// isNull reports whether a field is missing or null
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
+
+// others returns the servers other than server
+func others(servers []string, server string) []string {
+	var result []string
+	for _, s := range servers {
+		if s != server {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// Report summarizes the issues found across every linted server
+type Report struct {
+	Servers  int     `json:"servers"`
+	Tools    int     `json:"tools"`
+	Errors   int     `json:"errors"`
+	Warnings int     `json:"warnings"`
+	Infos    int     `json:"infos"`
+	Issues   []Issue `json:"issues"`
+}
+
+// Report collects the issues found so far
+func (l *Linter) Report() *Report {
+	r := &Report{Servers: l.servers, Tools: l.tools, Issues: l.Issues()}
+	if r.Issues == nil {
+		r.Issues = []Issue{}
+	}
+	for _, issue := range r.Issues {
+		switch issue.Severity {
+		case SeverityError:
+			r.Errors++
+		case SeverityWarning:
+			r.Warnings++
+		default:
+			r.Infos++
+		}
+	}
+	return r
+}
+
+// Count returns the number of issues at least as severe as severity
+func (r *Report) Count(severity Severity) int {
+	count := 0
+	for _, issue := range r.Issues {
+		if issue.Severity.AtLeast(severity) {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteText writes the issues for people, grouped by server and tool
+func (r *Report) WriteText(w io.Writer) error {
+	var server, tool string
+	for _, issue := range r.Issues {
+		if issue.Server != server || issue.Tool != tool {
+			if server != "" {
+				fmt.Fprintln(w)
+			}
+			server, tool = issue.Server, issue.Tool
+			fmt.Fprintf(w, "%s/%s\n", server, tool)
+		}
+		fmt.Fprintf(w, "  %-7s  %-26s %s\n", issue.Severity, issue.Rule, issue.Message)
+		if issue.Path != "" {
+			fmt.Fprintf(w, "           at %s\n", issue.Path)
+		}
+	}
+	if len(r.Issues) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	_, err := fmt.Fprintf(w, "%d errors, %d warnings, %d infos in %d tools on %d servers\n", r.Errors, r.Warnings, r.Infos, r.Tools, r.Servers)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
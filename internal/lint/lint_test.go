@@ -0,0 +1,260 @@
+package lint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tool builds a tools/list entry from a JSON input schema, which is left out if empty
+func tool(t *testing.T, name, description, inputSchema string) json.RawMessage {
+	t.Helper()
+	entry := map[string]interface{}{"name": name, "description": description}
+	if inputSchema != "" {
+		entry["inputSchema"] = json.RawMessage(inputSchema)
+	}
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	return data
+}
+
+// rules returns the rule and path of each issue
+func rules(issues []Issue) []string {
+	var result []string
+	for _, issue := range issues {
+		result = append(result, issue.Rule+" "+issue.Path)
+	}
+	return result
+}
+
+func TestLintCleanTool(t *testing.T) {
+	l := New(Options{})
+	l.AddServer("weather", []json.RawMessage{tool(t, "get_forecast", "Get the forecast for a city", `{
+		"type": "object",
+		"properties": {
+			"city": {"type": "string", "description": "City name"},
+			"days": {"type": "integer", "description": "Number of days"},
+			"tags": {"type": "array", "description": "Filters", "items": {"type": "string"}}
+		},
+		"required": ["city"]
+	}`)})
+
+	report := l.Report()
+	assert.Empty(t, report.Issues)
+	assert.Equal(t, 1, report.Servers)
+	assert.Equal(t, 1, report.Tools)
+}
+
+func TestLintRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		tool     json.RawMessage
+		expected []string
+	}{
+		{
+			name:     "invalid name",
+			tool:     tool(t, "get forecast", "Get the forecast", `{"type": "object"}`),
+			expected: []string{"invalid-name name"},
+		},
+		{
+			name:     "name too long",
+			tool:     tool(t, strings.Repeat("a", 65), "Get the forecast", `{"type": "object"}`),
+			expected: []string{"invalid-name name"},
+		},
+		{
+			name:     "missing descriptions",
+			tool:     tool(t, "forecast", " ", `{"type": "object", "properties": {"city": {"type": "string"}}}`),
+			expected: []string{"missing-description description", "missing-description inputSchema.properties.city.description"},
+		},
+		{
+			name:     "long description",
+			tool:     tool(t, "forecast", strings.Repeat("x", 1025), `{"type": "object"}`),
+			expected: []string{"long-description description"},
+		},
+		{
+			name:     "long description in characters, not bytes",
+			tool:     tool(t, "forecast", strings.Repeat("é", 1024), `{"type": "object"}`),
+			expected: nil,
+		},
+		{
+			name:     "missing input schema",
+			tool:     tool(t, "forecast", "Get the forecast", ""),
+			expected: []string{"missing-input-schema inputSchema"},
+		},
+		{
+			name:     "not an object",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "string"}`),
+			expected: []string{"invalid-schema inputSchema.type"},
+		},
+		{
+			name:     "unknown type",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"city": {"type": "text", "description": "City"}}}`),
+			expected: []string{"invalid-schema inputSchema.properties.city.type"},
+		},
+		{
+			name:     "malformed schema",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"city": {"type": "string", "description": "City", "pattern": "("}}}`),
+			expected: []string{"invalid-schema inputSchema"},
+		},
+		{
+			name:     "undecodable schema",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": 5}`),
+			expected: []string{"invalid-schema inputSchema"},
+		},
+		{
+			name:     "untyped property",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"city": {"description": "City"}, "unit": {"enum": ["c", "f"], "description": "Unit"}}}`),
+			expected: []string{"untyped-property inputSchema.properties.city"},
+		},
+		{
+			name:     "untyped array items",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"cities": {"type": "array", "description": "Cities", "items": {}}}}`),
+			expected: []string{"untyped-property inputSchema.properties.cities.items"},
+		},
+		{
+			name:     "required not in properties",
+			tool:     tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"city": {"type": "string", "description": "City"}}, "required": ["city", "country"]}`),
+			expected: []string{"required-not-in-properties inputSchema.required"},
+		},
+		{
+			name: "nested object",
+			tool: tool(t, "forecast", "Get the forecast", `{"type": "object", "properties": {"place": {
+				"type": "object", "description": "Place",
+				"properties": {"lat": {"type": "number"}}, "required": ["lon"]
+			}}}`),
+			expected: []string{
+				"required-not-in-properties inputSchema.properties.place.required",
+				"missing-description inputSchema.properties.place.properties.lat.description",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := New(Options{})
+			l.AddServer("weather", []json.RawMessage{tt.tool})
+			assert.ElementsMatch(t, tt.expected, rules(l.Issues()))
+		})
+	}
+}
+
+func TestLintOutputSchema(t *testing.T) {
+	forecast := json.RawMessage(`{
+		"name": "forecast", "description": "Get the forecast",
+		"inputSchema": {"type": "object"},
+		"outputSchema": {"type": "object", "required": ["temperature"]}
+	}`)
+
+	l := New(Options{})
+	l.AddServer("weather", []json.RawMessage{forecast})
+	assert.Equal(t, []string{"required-not-in-properties outputSchema.required"}, rules(l.Issues()))
+}
+
+func TestLintMaxDescription(t *testing.T) {
+	l := New(Options{MaxDescription: 10})
+	l.AddServer("weather", []json.RawMessage{tool(t, "forecast", "Get the forecast", `{"type": "object"}`)})
+
+	issues := l.Issues()
+	require.Len(t, issues, 1)
+	assert.Equal(t, RuleLongDescription, issues[0].Rule)
+	assert.Equal(t, SeverityWarning, issues[0].Severity)
+}
+
+func TestLintDuplicateNames(t *testing.T) {
+	l := New(Options{})
+	l.AddServer("a", []json.RawMessage{tool(t, "search", "Search a", `{"type": "object"}`)})
+	l.AddServer("b", []json.RawMessage{tool(t, "search", "Search b", `{"type": "object"}`)})
+	l.AddServer("c", []json.RawMessage{tool(t, "fetch", "Fetch", `{"type": "object"}`)})
+
+	issues := l.Issues()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "a", issues[0].Server)
+	assert.Equal(t, RuleDuplicateName, issues[0].Rule)
+	assert.Contains(t, issues[0].Message, "b")
+	assert.Equal(t, "b", issues[1].Server)
+	assert.Contains(t, issues[1].Message, "a")
+}
+
+func TestLintDuplicateNamesWithinServer(t *testing.T) {
+	l := New(Options{})
+	l.AddServer("a", []json.RawMessage{
+		tool(t, "search", "Search", `{"type": "object"}`),
+		tool(t, "fetch", "Fetch", `{"type": "object"}`),
+		tool(t, "search", "Search again", `{"type": "object"}`),
+	})
+
+	issues := l.Issues()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "search", issues[0].Tool)
+	assert.Equal(t, RuleDuplicateName, issues[0].Rule)
+	assert.Equal(t, SeverityError, issues[0].Severity)
+	assert.Contains(t, issues[0].Message, "listed 2 times")
+}
+
+func TestLintMalformedToolDoesNotHideOthers(t *testing.T) {
+	l := New(Options{})
+	l.AddServer("weather", []json.RawMessage{
+		json.RawMessage(`"forecast"`),
+		tool(t, "alerts", "Weather alerts", `{"type": "object", "properties": {"region": {"type": ["string", 1]}}}`),
+		tool(t, "get forecast", "Get the forecast", `{"type": "object"}`),
+	})
+
+	assert.Equal(t, []string{
+		"invalid-tool ",
+		"invalid-schema inputSchema",
+		"invalid-name name",
+	}, rules(l.Issues()))
+	assert.Equal(t, 3, l.Report().Tools)
+}
+
+func TestParseSeverity(t *testing.T) {
+	severity, err := ParseSeverity("Warning")
+	require.NoError(t, err)
+	assert.Equal(t, SeverityWarning, severity)
+
+	_, err = ParseSeverity("fatal")
+	assert.Error(t, err)
+
+	assert.True(t, SeverityError.AtLeast(SeverityWarning))
+	assert.True(t, SeverityWarning.AtLeast(SeverityWarning))
+	assert.False(t, SeverityInfo.AtLeast(SeverityWarning))
+}
+
+func TestReport(t *testing.T) {
+	l := New(Options{})
+	l.AddServer("weather", []json.RawMessage{
+		tool(t, "get forecast", "", `{"type": "object", "properties": {"city": {"type": "string"}}}`),
+		tool(t, "alerts", "Weather alerts", `{"type": "object"}`),
+	})
+
+	report := l.Report()
+	assert.Equal(t, 1, report.Errors)
+	assert.Equal(t, 1, report.Warnings)
+	assert.Equal(t, 1, report.Infos)
+	assert.Equal(t, 2, report.Tools)
+	assert.Equal(t, 1, report.Count(SeverityError))
+	assert.Equal(t, 2, report.Count(SeverityWarning))
+	assert.Equal(t, 3, report.Count(SeverityInfo))
+
+	var text bytes.Buffer
+	require.NoError(t, report.WriteText(&text))
+	assert.Contains(t, text.String(), "weather/get forecast\n  error    invalid-name")
+	assert.Contains(t, text.String(), "at inputSchema.properties.city.description")
+	assert.Contains(t, text.String(), "1 errors, 1 warnings, 1 infos in 2 tools on 1 servers")
+
+	var data bytes.Buffer
+	require.NoError(t, report.WriteJSON(&data))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(data.Bytes(), &decoded))
+	assert.Equal(t, report.Issues, decoded.Issues)
+}
+
+func TestReportWithoutIssues(t *testing.T) {
+	var data bytes.Buffer
+	require.NoError(t, New(Options{}).Report().WriteJSON(&data))
+	assert.Contains(t, data.String(), `"issues": []`)
+}
@@ -0,0 +1,275 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Batch result statuses
+const (
+	BatchOK        = "ok"
+	BatchToolError = "tool_error"
+	BatchError     = "error"
+	BatchSkipped   = "skipped"
+)
+
+// BatchCall is one tool call read from a JSONL batch file
+type BatchCall struct {
+	Line      int                    `json:"-"`
+	Server    string                 `json:"server"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments"`
+}
+
+// BatchResult is the outcome of a single batch call
+type BatchResult struct {
+	Line       int                 `json:"line"`
+	Server     string              `json:"server"`
+	Tool       string              `json:"tool"`
+	Status     string              `json:"status"`
+	StartedAt  *time.Time          `json:"started_at,omitempty"`
+	DurationMS float64             `json:"duration_ms"`
+	Error      string              `json:"error,omitempty"`
+	Result     *mcp.CallToolResult `json:"result,omitempty"`
+}
+
+// BatchOptions controls how a batch is run
+type BatchOptions struct {
+	Concurrency int
+	FailFast    bool
+	// Validate checks arguments against each tool's input schema before calling it
+	Validate bool
+}
+
+// BatchSummary counts the outcomes of a batch
+type BatchSummary struct {
+	Total      int
+	Succeeded  int
+	ToolErrors int
+	Failed     int
+	Skipped    int
+	Duration   time.Duration
+}
+
+// Failures returns the number of calls that did not succeed
+func (s BatchSummary) Failures() int {
+	return s.ToolErrors + s.Failed + s.Skipped
+}
+
+// ReadBatch parses one call per line. Blank lines are ignored, and calls without a server use
+// defaultServer.
+func ReadBatch(r io.Reader, defaultServer string) ([]BatchCall, error) {
+	var calls []BatchCall
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var call BatchCall
+		if err := json.Unmarshal([]byte(text), &call); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		call.Line = line
+		if call.Server == "" {
+			call.Server = defaultServer
+		}
+		if call.Server == "" {
+			return nil, fmt.Errorf("line %d: no server given and no default server configured", line)
+		}
+		if call.Tool == "" {
+			return nil, fmt.Errorf("line %d: missing tool", line)
+		}
+		calls = append(calls, call)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch: %w", err)
+	}
+	return calls, nil
+}
+
+// BatchServers returns the sorted names of the servers a batch calls
+func BatchServers(calls []BatchCall) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, call := range calls {
+		if !seen[call.Server] {
+			seen[call.Server] = true
+			servers = append(servers, call.Server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// RunBatch runs calls concurrently and passes each result to emit as it completes. emit is
+// never called concurrently. With FailFast, the first unsuccessful call stops the batch and
+// calls that have not started are reported as skipped.
+func (m *Manager) RunBatch(ctx context.Context, calls []BatchCall, opts BatchOptions, emit func(BatchResult)) BatchSummary {
+	workers := opts.Concurrency
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > len(calls) {
+		workers = len(calls)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summary := BatchSummary{Total: len(calls)}
+	var mu sync.Mutex
+	record := func(result BatchResult) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch result.Status {
+		case BatchOK:
+			summary.Succeeded++
+		case BatchToolError:
+			summary.ToolErrors++
+		case BatchSkipped:
+			summary.Skipped++
+		default:
+			summary.Failed++
+		}
+		if result.Status != BatchOK && opts.FailFast {
+			cancel()
+		}
+		emit(result)
+	}
+
+	tools := &batchTools{tools: make(map[string]map[string]*mcp.Tool)}
+	jobs := make(chan BatchCall)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for call := range jobs {
+				record(m.runBatchCall(ctx, call, tools, opts.Validate))
+			}
+		}()
+	}
+
+	start := time.Now()
+	for _, call := range calls {
+		if ctx.Err() != nil {
+			record(skippedBatchCall(call))
+			continue
+		}
+		select {
+		case jobs <- call:
+		case <-ctx.Done():
+			record(skippedBatchCall(call))
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	summary.Duration = time.Since(start)
+	return summary
+}
+
+// runBatchCall executes one call and times it
+func (m *Manager) runBatchCall(ctx context.Context, call BatchCall, tools *batchTools, validate bool) BatchResult {
+	start := time.Now()
+	result := BatchResult{Line: call.Line, Server: call.Server, Tool: call.Tool, StartedAt: &start}
+	fail := func(err error) BatchResult {
+		result.Status = BatchError
+		result.Error = err.Error()
+		if ctx.Err() != nil {
+			// Stopped by --fail-fast rather than failing on its own
+			result.Status = BatchSkipped
+		}
+		result.DurationMS = durationMS(time.Since(start))
+		return result
+	}
+
+	client, err := m.GetClient(call.Server)
+	if err != nil {
+		return fail(err)
+	}
+
+	if validate {
+		tool, err := tools.find(ctx, client, call.Tool)
+		if err != nil {
+			return fail(err)
+		}
+		if err := ValidateToolArguments(tool, call.Arguments); err != nil {
+			return fail(err)
+		}
+	}
+
+	callResult, err := client.CallTool(ctx, call.Tool, call.Arguments)
+	if err != nil {
+		return fail(err)
+	}
+
+	result.DurationMS = durationMS(time.Since(start))
+	result.Result = callResult
+	result.Status = BatchOK
+	if callResult.IsError {
+		result.Status = BatchToolError
+	}
+	return result
+}
+
+// skippedBatchCall reports a call that was never started
+func skippedBatchCall(call BatchCall) BatchResult {
+	return BatchResult{
+		Line:   call.Line,
+		Server: call.Server,
+		Tool:   call.Tool,
+		Status: BatchSkipped,
+		Error:  "not run after an earlier failure",
+	}
+}
+
+// batchTools caches tool definitions per server for argument validation
+type batchTools struct {
+	mu    sync.Mutex
+	tools map[string]map[string]*mcp.Tool
+}
+
+// find returns a tool definition, listing the server's tools on first use
+func (b *batchTools) find(ctx context.Context, client *Client, name string) (*mcp.Tool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	server := client.GetName()
+	byName, ok := b.tools[server]
+	if !ok {
+		tools, err := client.AllTools(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list tools of server %s: %w", server, err)
+		}
+		byName = make(map[string]*mcp.Tool, len(tools))
+		for _, tool := range tools {
+			byName[tool.Name] = tool
+		}
+		b.tools[server] = byName
+	}
+
+	tool, ok := byName[name]
+	if !ok {
+		return nil, fmt.Errorf("tool %s not found on server %s", name, server)
+	}
+	return tool, nil
+}
+
+// durationMS converts a duration to fractional milliseconds
+func durationMS(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,98 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestReadBatch(t *testing.T) {
+	input := `{"server": "alpha", "tool": "echo", "arguments": {"message": "hi"}}
+
+{"tool": "echo"}
+`
+	calls, err := ReadBatch(strings.NewReader(input), "beta")
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+
+	assert.Equal(t, BatchCall{Line: 1, Server: "alpha", Tool: "echo", Arguments: map[string]interface{}{"message": "hi"}}, calls[0])
+	assert.Equal(t, BatchCall{Line: 3, Server: "beta", Tool: "echo"}, calls[1])
+	assert.Equal(t, []string{"alpha", "beta"}, BatchServers(calls))
+
+	_, err = ReadBatch(strings.NewReader(`{"tool": "echo"}`), "")
+	assert.EqualError(t, err, "line 1: no server given and no default server configured")
+
+	_, err = ReadBatch(strings.NewReader("{\"server\": \"a\", \"tool\": \"echo\"}\nnot json\n"), "")
+	assert.ErrorContains(t, err, "line 2:")
+}
+
+// batchManager starts a manager connected to a single fake server named "fake"
+func batchManager(t *testing.T) *Manager {
+	t.Helper()
+	manager := NewManager(logrus.New())
+	t.Cleanup(func() { _ = manager.Close() })
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+	return manager
+}
+
+func TestRunBatch(t *testing.T) {
+	manager := batchManager(t)
+
+	calls := []BatchCall{
+		{Line: 1, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "one"}},
+		{Line: 2, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "fail"}},
+		{Line: 3, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": 3}},
+		{Line: 4, Server: "ghost", Tool: "echo"},
+		{Line: 5, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "five"}},
+	}
+
+	results := make(map[int]BatchResult)
+	summary := manager.RunBatch(context.Background(), calls, BatchOptions{Concurrency: 3, Validate: true}, func(result BatchResult) {
+		results[result.Line] = result
+	})
+
+	assert.Equal(t, 5, summary.Total)
+	assert.Equal(t, 2, summary.Succeeded)
+	assert.Equal(t, 1, summary.ToolErrors)
+	assert.Equal(t, 2, summary.Failed)
+	assert.Equal(t, 3, summary.Failures())
+
+	require.Len(t, results, 5)
+	assert.Equal(t, BatchOK, results[1].Status)
+	assert.Equal(t, "one", results[1].Result.Content[0].(*mcp.TextContent).Text)
+	assert.NotNil(t, results[1].StartedAt)
+	assert.Equal(t, BatchToolError, results[2].Status)
+	assert.Equal(t, BatchError, results[3].Status)
+	assert.Contains(t, results[3].Error, "$.message: type: 3 has type \"integer\", want \"string\"")
+	assert.Equal(t, BatchError, results[4].Status)
+	assert.Contains(t, results[4].Error, "not found")
+	assert.Equal(t, BatchOK, results[5].Status)
+}
+
+func TestRunBatchFailFast(t *testing.T) {
+	manager := batchManager(t)
+
+	calls := []BatchCall{
+		{Line: 1, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "fail"}},
+		{Line: 2, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "two"}},
+		{Line: 3, Server: "fake", Tool: "echo", Arguments: map[string]interface{}{"message": "three"}},
+	}
+
+	var statuses []string
+	summary := manager.RunBatch(context.Background(), calls, BatchOptions{Concurrency: 1, FailFast: true}, func(result BatchResult) {
+		statuses = append(statuses, result.Status)
+	})
+
+	assert.Equal(t, []string{BatchToolError, BatchSkipped, BatchSkipped}, statuses)
+	assert.Equal(t, 1, summary.ToolErrors)
+	assert.Equal(t, 2, summary.Skipped)
+}
@@ -0,0 +1,142 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Health checks run by CheckServers, in order
+const (
+	CheckInitialize    = "initialize"
+	CheckPing          = "ping"
+	CheckListTools     = "list tools"
+	CheckListResources = "list resources"
+	CheckListPrompts   = "list prompts"
+)
+
+// CheckStep is the outcome of one health check against a server
+type CheckStep struct {
+	Name     string
+	Duration time.Duration
+	// Detail summarizes a successful check, or says why it was skipped
+	Detail  string
+	Err     error
+	Skipped bool
+}
+
+// ServerCheck holds the health checks of one server
+type ServerCheck struct {
+	Server string
+	Steps  []CheckStep
+}
+
+// Failed reports whether any check of the server failed
+func (c ServerCheck) Failed() bool {
+	for _, step := range c.Steps {
+		if step.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckServers checks every server of the last InitializeServers call: its startup becomes the
+// initialize check, followed by a ping and listing its tools, resources and prompts. Each check
+// gets its own timeout. Listings of capabilities the server did not declare are skipped rather
+// than failed.
+func (m *Manager) CheckServers(ctx context.Context, timeout time.Duration) []ServerCheck {
+	var checks []ServerCheck
+	for _, startup := range m.StartupReport() {
+		check := ServerCheck{Server: startup.Server}
+		check.Steps = append(check.Steps, CheckStep{Name: CheckInitialize, Duration: startup.Duration, Err: startup.Err})
+
+		client, err := m.GetClient(startup.Server)
+		if !startup.Connected || err != nil {
+			for _, name := range []string{CheckPing, CheckListTools, CheckListResources, CheckListPrompts} {
+				check.Steps = append(check.Steps, CheckStep{Name: name, Skipped: true, Detail: "server did not start"})
+			}
+			checks = append(checks, check)
+			continue
+		}
+
+		resources, prompts := client.declaresListings()
+		check.Steps = append(check.Steps,
+			runCheck(ctx, timeout, CheckPing, func(ctx context.Context) (string, error) {
+				return "", client.Ping(ctx)
+			}),
+			runCheck(ctx, timeout, CheckListTools, func(ctx context.Context) (string, error) {
+				tools, err := client.AllTools(ctx)
+				return countOf(len(tools), "tool"), err
+			}),
+			runListing(ctx, timeout, CheckListResources, resources, func(ctx context.Context) (string, error) {
+				result, err := client.ListResources(ctx)
+				if err != nil {
+					return "", err
+				}
+				return countOf(len(result.Resources), "resource"), nil
+			}),
+			runListing(ctx, timeout, CheckListPrompts, prompts, func(ctx context.Context) (string, error) {
+				result, err := client.ListPrompts(ctx)
+				if err != nil {
+					return "", err
+				}
+				return countOf(len(result.Prompts), "prompt"), nil
+			}),
+		)
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// runCheck times a single check
+func runCheck(ctx context.Context, timeout time.Duration, name string, check func(context.Context) (string, error)) CheckStep {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	detail, err := check(ctx)
+	step := CheckStep{Name: name, Duration: time.Since(start), Detail: detail, Err: err}
+	if err != nil && isMethodNotFound(err) {
+		step.Err = nil
+		step.Skipped = true
+		step.Detail = "not supported by the server"
+	}
+	return step
+}
+
+// runListing runs a listing check unless the server's capabilities leave the listing out
+func runListing(ctx context.Context, timeout time.Duration, name string, declared bool, check func(context.Context) (string, error)) CheckStep {
+	if !declared {
+		return CheckStep{Name: name, Skipped: true, Detail: "not supported by the server"}
+	}
+	return runCheck(ctx, timeout, name, check)
+}
+
+// declaresListings reports whether the server declared the resources and prompts capabilities.
+// Both count as declared when the initialize result is unknown, leaving the decision to the
+// listing's error.
+func (c *Client) declaresListings() (resources, prompts bool) {
+	c.mu.RLock()
+	initialized := c.initialized
+	c.mu.RUnlock()
+	if initialized == nil || initialized.Capabilities == nil {
+		return true, true
+	}
+	return initialized.Capabilities.Resources != nil, initialized.Capabilities.Prompts != nil
+}
+
+// isMethodNotFound reports whether a request failed because the server does not implement it.
+// It is the fallback for servers whose capabilities are unknown.
+func isMethodNotFound(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "method not found")
+}
+
+// countOf formats a count with a pluralized noun
+func countOf(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("1 %s", noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
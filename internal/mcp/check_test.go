@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestCheckServers(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "crashed")
+	require.NoError(t, os.WriteFile(marker, nil, 0o644))
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+	manager.CaptureStderr(1024)
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"good":   fakeServerConfig(t, nil),
+		"broken": fakeServerConfig(t, map[string]string{fakeCrashMarkerEnv: marker}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	checks := manager.CheckServers(context.Background(), 5*time.Second)
+	require.Len(t, checks, 2)
+
+	broken := checks[0]
+	assert.Equal(t, "broken", broken.Server)
+	assert.True(t, broken.Failed())
+	require.Len(t, broken.Steps, 5)
+	assert.Equal(t, CheckInitialize, broken.Steps[0].Name)
+	assert.Error(t, broken.Steps[0].Err)
+	for _, step := range broken.Steps[1:] {
+		assert.True(t, step.Skipped, step.Name)
+		assert.Equal(t, "server did not start", step.Detail)
+	}
+	assert.Contains(t, manager.ServerStderr("broken"), "crashed earlier, refusing to start")
+
+	good := checks[1]
+	assert.Equal(t, "good", good.Server)
+	assert.False(t, good.Failed())
+	names := make([]string, 0, len(good.Steps))
+	for _, step := range good.Steps {
+		names = append(names, step.Name)
+		assert.NoError(t, step.Err, step.Name)
+	}
+	assert.Equal(t, []string{CheckInitialize, CheckPing, CheckListTools, CheckListResources, CheckListPrompts}, names)
+	assert.Equal(t, "3 tools", good.Steps[2].Detail)
+	assert.Empty(t, manager.ServerStderr("good"))
+}
+
+func TestStderrTailKeepsLastBytes(t *testing.T) {
+	tail := NewStderrTail(8)
+	_, _ = tail.Write([]byte("hello "))
+	_, _ = tail.Write([]byte("world"))
+	assert.Equal(t, "lo world", tail.String())
+}
@@ -2,8 +2,12 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os/exec"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -17,32 +21,65 @@ import (
 type Client struct {
 	name    string
 	config  config.MCPServer
+	manager *Manager
+	logger  *logrus.Entry
+
+	mu      sync.RWMutex
 	client  *mcp.Client
 	session *mcp.ClientSession
-	logger  *logrus.Entry
+	// initialized is the server's answer to the handshake of the current session
+	initialized *mcp.InitializeResult
+	state       ConnectionState
+	closed      bool
 }
 
 // Manager manages multiple MCP clients
 type Manager struct {
 	clients map[string]*Client
 	logger  *logrus.Logger
+
+	mu                 sync.Mutex
+	stateHandler       StateHandler
+	listChangedHandler ListChangedHandler
+	closing            bool
+	done               chan struct{}
+	startupWorkers     int
+	startupReport      []StartupResult
+	disabled           map[string]config.MCPServer
+	tools              toolRegistry
+	toolFilters        map[string]ToolFilter
+	stderrLimit        int
+	stderr             map[string]*StderrTail
+	processes          map[string]int
+	recorder           *Recorder
+	tracer             *Tracer
 }
 
 // NewManager creates a new MCP client manager
 func NewManager(logger *logrus.Logger) *Manager {
 	return &Manager{
-		clients: make(map[string]*Client),
-		logger:  logger,
+		clients:        make(map[string]*Client),
+		logger:         logger,
+		done:           make(chan struct{}),
+		startupWorkers: constants.DefaultStartupWorkers,
+		disabled:       make(map[string]config.MCPServer),
+		tools:          toolRegistry{naming: ToolNamingAuto},
+		toolFilters:    make(map[string]ToolFilter),
+		stderr:         make(map[string]*StderrTail),
+		processes:      make(map[string]int),
 	}
 }
 
-// InitializeServers initializes MCP servers based on configuration
+// InitializeServers initializes MCP servers based on configuration.
+// Servers are connected concurrently by a bounded pool of workers; the outcome for
+// each server is available afterwards from StartupReport.
 func (m *Manager) InitializeServers(mcpConfig *config.MCPConfig, serverNames []string) error {
 	if len(serverNames) == 0 {
 		// Initialize all servers
 		for name := range mcpConfig.Servers {
 			serverNames = append(serverNames, name)
 		}
+		sort.Strings(serverNames)
 	}
 
 	if len(serverNames) == 0 {
@@ -50,22 +87,56 @@ func (m *Manager) InitializeServers(mcpConfig *config.MCPConfig, serverNames []s
 	}
 
 	for _, name := range serverNames {
-		serverConfig, exists := mcpConfig.Servers[name]
-		if !exists {
+		if _, exists := mcpConfig.Servers[name]; !exists {
 			return fmt.Errorf("server %s not found in configuration", name)
 		}
+	}
 
-		client, err := m.initializeServer(name, serverConfig)
-		if err != nil {
-			m.logger.WithError(err).Errorf("Failed to initialize server %s", name)
+	if err := m.SetToolNaming(mcpConfig.ToolNaming); err != nil {
+		return err
+	}
+
+	results := make([]StartupResult, len(serverNames))
+	clients := make([]*Client, len(serverNames))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < min(m.startupWorkers, len(serverNames)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				name := serverNames[i]
+				start := time.Now()
+				clients[i], results[i].Err = m.initializeServer(name, mcpConfig.Servers[name])
+				results[i].Server = name
+				results[i].Duration = time.Since(start)
+				results[i].Connected = results[i].Err == nil
+			}
+		}()
+	}
+	for i := range serverNames {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	m.mu.Lock()
+	for i, result := range results {
+		if result.Err != nil {
+			m.logger.WithError(result.Err).Errorf("Failed to initialize server %s", result.Server)
 			continue
 		}
 
-		m.clients[name] = client
-		m.logger.Infof("Successfully initialized MCP server: %s", name)
+		m.clients[result.Server] = clients[i]
+		m.logger.Infof("Successfully initialized MCP server: %s (%s)", result.Server, result.Duration.Round(time.Millisecond))
 	}
+	m.startupReport = results
+	connected := len(m.clients)
+	m.mu.Unlock()
+	m.InvalidateTools()
 
-	if len(m.clients) == 0 {
+	if connected == 0 {
 		return fmt.Errorf("no MCP servers could be initialized")
 	}
 
@@ -76,59 +147,129 @@ func (m *Manager) InitializeServers(mcpConfig *config.MCPConfig, serverNames []s
 func (m *Manager) initializeServer(name string, serverConfig config.MCPServer) (*Client, error) {
 	logger := m.logger.WithField("server", name)
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	timeout, err := startupTimeout(serverConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := configToolFilter(serverConfig).Validate(); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	var transport mcp.Transport
-	var err error
+	mcpClient, session, initialized, err := m.connect(ctx, name, serverConfig)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("startup timed out after %s: %w", timeout, err)
+		}
+		return nil, err
+	}
 
-	switch serverConfig.Transport.Type {
-	case "stdio":
-		transport, err = m.createStdioTransport(serverConfig)
-	case "http", "sse":
-		transport, err = m.createHTTPTransport(serverConfig)
-	default:
-		return nil, fmt.Errorf("unsupported transport type: %s", serverConfig.Transport.Type)
+	client := &Client{
+		name:        name,
+		config:      serverConfig,
+		manager:     m,
+		client:      mcpClient,
+		session:     session,
+		initialized: initialized,
+		state:       StateConnected,
+		logger:      logger,
 	}
 
+	// Test connection with ping
+	if err := client.Ping(ctx); err != nil {
+		logger.WithError(err).Warn("Server ping failed, but continuing")
+	}
+
+	go m.watch(client, session)
+
+	return client, nil
+}
+
+// connect creates a fresh transport for the server and performs the MCP initialization handshake,
+// returning the server's initialize result along with the session
+func (m *Manager) connect(ctx context.Context, name string, serverConfig config.MCPServer) (*mcp.Client, *mcp.ClientSession, *mcp.InitializeResult, error) {
+	transport, err := m.Transport(name, serverConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create %s transport: %w", serverConfig.Transport.Type, err)
+		return nil, nil, nil, err
 	}
 
 	// Create MCP client
-	mcpClient := mcp.NewClient(constants.AppName, constants.AppVersion, &mcp.ClientOptions{})
+	mcpClient := mcp.NewClient(constants.AppName, constants.AppVersion, &mcp.ClientOptions{
+		ToolListChangedHandler: func(context.Context, *mcp.ClientSession, *mcp.ToolListChangedParams) {
+			m.listChanged(name, ListTools)
+		},
+		PromptListChangedHandler: func(context.Context, *mcp.ClientSession, *mcp.PromptListChangedParams) {
+			m.listChanged(name, ListPrompts)
+		},
+		ResourceListChangedHandler: func(context.Context, *mcp.ClientSession, *mcp.ResourceListChangedParams) {
+			m.listChanged(name, ListResources)
+		},
+	})
+
+	// The SDK keeps the initialize result to itself, so it is caught on its way back
+	var initialized *mcp.InitializeResult
+	mcpClient.AddSendingMiddleware(func(next mcp.MethodHandler[*mcp.ClientSession]) mcp.MethodHandler[*mcp.ClientSession] {
+		return func(ctx context.Context, session *mcp.ClientSession, method string, params mcp.Params) (mcp.Result, error) {
+			result, err := next(ctx, session, method, params)
+			if r, ok := result.(*mcp.InitializeResult); ok && err == nil {
+				initialized = r
+			}
+			return result, err
+		}
+	})
 
 	// Connect to the server
 	session, err := mcpClient.Connect(ctx, transport)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect: %w", err)
+		return nil, nil, nil, fmt.Errorf("failed to connect: %w", err)
 	}
 
-	client := &Client{
-		name:    name,
-		config:  serverConfig,
-		client:  mcpClient,
-		session: session,
-		logger:  logger,
+	return mcpClient, session, initialized, nil
+}
+
+// Transport creates a transport to a server, recorded and traced like the manager's own
+// connections. A transport connects once, starting the process of a stdio server, so every
+// session needs a new one.
+func (m *Manager) Transport(name string, serverConfig config.MCPServer) (mcp.Transport, error) {
+	var transport mcp.Transport
+	var err error
+
+	switch serverConfig.Transport.Type {
+	case "stdio":
+		var cmd *exec.Cmd
+		transport, cmd, err = m.createStdioTransport(serverConfig, m.stderrWriter(name))
+		if err == nil {
+			transport = m.trackProcess(name, cmd, transport)
+		}
+	case "http", "sse":
+		transport, err = m.createHTTPTransport(serverConfig)
+	default:
+		return nil, fmt.Errorf("unsupported transport type: %s", serverConfig.Transport.Type)
 	}
 
-	// Test connection with ping
-	if err := client.Ping(ctx); err != nil {
-		logger.WithError(err).Warn("Server ping failed, but continuing")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s transport: %w", serverConfig.Transport.Type, err)
 	}
 
-	return client, nil
+	return m.observe(name, transport), nil
 }
 
-// createStdioTransport creates a STDIO transport
-func (m *Manager) createStdioTransport(serverConfig config.MCPServer) (mcp.Transport, error) {
+// createStdioTransport creates a STDIO transport and the command it starts. The server's stderr
+// is written to stderr, or discarded if that is nil.
+func (m *Manager) createStdioTransport(serverConfig config.MCPServer, stderr io.Writer) (mcp.Transport, *exec.Cmd, error) {
 	if len(serverConfig.Command) == 0 {
-		return nil, fmt.Errorf("command is required for stdio transport")
+		return nil, nil, fmt.Errorf("command is required for stdio transport")
 	}
 
 	// Create command
 	cmd := exec.Command(serverConfig.Command[0], serverConfig.Command[1:]...)
-	
+	if stderr != nil {
+		cmd.Stderr = stderr
+	}
+
 	// Set environment variables if provided
 	if len(serverConfig.Env) > 0 {
 		env := cmd.Environ()
@@ -138,7 +279,7 @@ func (m *Manager) createStdioTransport(serverConfig config.MCPServer) (mcp.Trans
 		cmd.Env = env
 	}
 
-	return mcp.NewCommandTransport(cmd), nil
+	return mcp.NewCommandTransport(cmd), cmd, nil
 }
 
 // createHTTPTransport creates an HTTP or SSE transport
@@ -147,10 +288,7 @@ func (m *Manager) createHTTPTransport(serverConfig config.MCPServer) (mcp.Transp
 		return nil, fmt.Errorf("host is required for http/sse transport")
 	}
 
-	baseURL := fmt.Sprintf("http://%s:%d%s", 
-		serverConfig.Transport.Host, 
-		serverConfig.Transport.Port, 
-		serverConfig.Transport.Path)
+	baseURL := serverConfig.Transport.URL()
 
 	if serverConfig.Transport.Type == "sse" {
 		return mcp.NewSSEClientTransport(baseURL, &mcp.SSEClientTransportOptions{}), nil
@@ -162,22 +300,47 @@ func (m *Manager) createHTTPTransport(serverConfig config.MCPServer) (mcp.Transp
 
 // GetClient returns a client by name
 func (m *Manager) GetClient(name string) (*Client, error) {
+	m.mu.Lock()
 	client, exists := m.clients[name]
+	m.mu.Unlock()
 	if !exists {
 		return nil, fmt.Errorf("client %s not found", name)
 	}
 	return client, nil
 }
 
-// GetAllClients returns all initialized clients
+// GetAllClients returns a snapshot of all initialized clients
 func (m *Manager) GetAllClients() map[string]*Client {
-	return m.clients
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clients := make(map[string]*Client, len(m.clients))
+	for name, client := range m.clients {
+		clients[name] = client
+	}
+	return clients
+}
+
+// SetStateHandler registers a callback that is notified of connection state transitions
+func (m *Manager) SetStateHandler(handler StateHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stateHandler = handler
 }
 
 // Close closes all MCP clients
 func (m *Manager) Close() error {
+	m.mu.Lock()
+	if !m.closing {
+		m.closing = true
+		close(m.done)
+	}
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
 	var lastErr error
-	for name, client := range m.clients {
+	for name, client := range clients {
 		if err := client.Close(); err != nil {
 			m.logger.WithError(err).Errorf("Failed to close client %s", name)
 			lastErr = err
@@ -188,36 +351,119 @@ func (m *Manager) Close() error {
 
 // Ping sends a ping request to the server
 func (c *Client) Ping(ctx context.Context) error {
-	return c.session.Ping(ctx, &mcp.PingParams{})
+	session, err := c.currentSession()
+	if err != nil {
+		return err
+	}
+	return c.checkConnection(session, session.Ping(ctx, &mcp.PingParams{}))
 }
 
 // ListTools returns the tools available on this server
 func (c *Client) ListTools(ctx context.Context) (*mcp.ListToolsResult, error) {
-	return c.session.ListTools(ctx, &mcp.ListToolsParams{})
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.ListTools(ctx, &mcp.ListToolsParams{})
+	return result, c.checkConnection(session, err)
+}
+
+// AllTools returns every tool on this server, following pagination cursors
+func (c *Client) AllTools(ctx context.Context) ([]*mcp.Tool, error) {
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+
+	var tools []*mcp.Tool
+	for tool, err := range session.Tools(ctx, nil) {
+		if err != nil {
+			return nil, c.checkConnection(session, err)
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// FindTool returns the definition of a tool on this server
+func (c *Client) FindTool(ctx context.Context, name string) (*mcp.Tool, error) {
+	tools, err := c.AllTools(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range tools {
+		if tool.Name == name {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("tool %s not found on server %s", name, c.name)
 }
 
 // ListResources returns the resources available on this server
 func (c *Client) ListResources(ctx context.Context) (*mcp.ListResourcesResult, error) {
-	return c.session.ListResources(ctx, &mcp.ListResourcesParams{})
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.ListResources(ctx, &mcp.ListResourcesParams{})
+	return result, c.checkConnection(session, err)
+}
+
+// ReadResource reads the contents of a resource
+func (c *Client) ReadResource(ctx context.Context, uri string) (*mcp.ReadResourceResult, error) {
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	return result, c.checkConnection(session, err)
+}
+
+// GetPrompt renders a prompt with the given arguments
+func (c *Client) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*mcp.GetPromptResult, error) {
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.GetPrompt(ctx, &mcp.GetPromptParams{
+		Name:      name,
+		Arguments: arguments,
+	})
+	return result, c.checkConnection(session, err)
 }
 
 // ListPrompts returns the prompts available on this server
 func (c *Client) ListPrompts(ctx context.Context) (*mcp.ListPromptsResult, error) {
-	return c.session.ListPrompts(ctx, &mcp.ListPromptsParams{})
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.ListPrompts(ctx, &mcp.ListPromptsParams{})
+	return result, c.checkConnection(session, err)
 }
 
 // CallTool executes a tool with the given parameters
 func (c *Client) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
-	return c.session.CallTool(ctx, &mcp.CallToolParams{
+	session, err := c.currentSession()
+	if err != nil {
+		return nil, err
+	}
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{
 		Name:      name,
 		Arguments: arguments,
 	})
+	return result, c.checkConnection(session, err)
 }
 
 // Close closes the MCP client connection
 func (c *Client) Close() error {
-	if c.session != nil {
-		return c.session.Close()
+	c.mu.Lock()
+	c.closed = true
+	session := c.session
+	c.mu.Unlock()
+
+	if session != nil {
+		return session.Close()
 	}
 	return nil
 }
@@ -231,3 +477,10 @@ func (c *Client) GetName() string {
 func (c *Client) GetConfig() config.MCPServer {
 	return c.config
 }
+
+// State returns the current connection state of the client
+func (c *Client) State() ConnectionState {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state
+}
@@ -63,7 +63,7 @@ func TestCreateStdioTransport(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			transport, err := manager.createStdioTransport(tt.serverConfig)
+			transport, _, err := manager.createStdioTransport(tt.serverConfig, nil)
 			if tt.expectError {
 				assert.Error(t, err)
 				assert.Nil(t, transport)
@@ -137,7 +137,7 @@ func TestCreateHTTPTransport(t *testing.T) {
 
 func TestClientGetters(t *testing.T) {
 	serverConfig := config.MCPServer{
-		Name: "test_server",
+		Name:    "test_server",
 		Command: []string{"echo", "hello"},
 		Transport: config.MCPTransport{
 			Type: "stdio",
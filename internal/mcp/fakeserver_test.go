@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/config"
+)
+
+// The test binary doubles as a stdio MCP server so Manager can be exercised against a real child process
+const (
+	fakeServerEnv      = "MCP_TSTR_FAKE_SERVER"
+	fakeCrashMarkerEnv = "MCP_TSTR_FAKE_CRASH_MARKER"
+)
+
+func TestMain(m *testing.M) {
+	if os.Getenv(fakeServerEnv) == "1" {
+		os.Exit(runFakeServer())
+	}
+	os.Exit(m.Run())
+}
+
+type echoParams struct {
+	Message string `json:"message"`
+}
+
+// runFakeServer serves a few tools over stdio. "echo" returns its message, or a tool error when
+// the message is "fail". The "crash" tool kills the process mid-call;
+// if a crash marker file is configured, the crash also leaves it behind so every restart fails.
+// The "grow" tool registers another tool so list_changed notifications can be tested.
+func runFakeServer() int {
+	marker := os.Getenv(fakeCrashMarkerEnv)
+	if marker != "" {
+		if _, err := os.Stat(marker); err == nil {
+			fmt.Fprintln(os.Stderr, "crashed earlier, refusing to start")
+			return 3
+		}
+	}
+
+	server := mcp.NewServer("fake", "0.0.1", nil)
+	server.AddTools(
+		mcp.NewServerTool("echo", "Echo a message", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[echoParams]) (*mcp.CallToolResultFor[any], error) {
+			if params.Arguments.Message == "fail" {
+				return &mcp.CallToolResultFor[any]{
+					Content: []mcp.Content{&mcp.TextContent{Text: "echo failed on purpose"}},
+					IsError: true,
+				}, nil
+			}
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: params.Arguments.Message}},
+			}, nil
+		}),
+		mcp.NewServerTool("crash", "Terminate the server", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+			if marker != "" {
+				_ = os.WriteFile(marker, nil, 0644)
+			}
+			os.Exit(2)
+			return nil, nil
+		}),
+		mcp.NewServerTool("grow", "Register an extra tool, triggering tools/list_changed", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+			server.AddTools(mcp.NewServerTool("extra", "Added at runtime", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[struct{}]) (*mcp.CallToolResultFor[any], error) {
+				return &mcp.CallToolResultFor[any]{}, nil
+			}))
+			return &mcp.CallToolResultFor[any]{}, nil
+		}),
+	)
+
+	if err := server.Run(context.Background(), mcp.NewStdioTransport()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// fakeServerConfig returns a stdio server configuration that launches the fake server
+func fakeServerConfig(t *testing.T, env map[string]string) config.MCPServer {
+	t.Helper()
+
+	executable, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to locate test binary: %v", err)
+	}
+
+	serverEnv := map[string]string{fakeServerEnv: "1"}
+	for key, value := range env {
+		serverEnv[key] = value
+	}
+
+	return config.MCPServer{
+		Name:      "fake",
+		Command:   []string{executable},
+		Env:       serverEnv,
+		Transport: config.MCPTransport{Type: "stdio"},
+	}
+}
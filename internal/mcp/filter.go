@@ -0,0 +1,125 @@
+package mcp
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"mcp_tstr/internal/config"
+)
+
+// ToolFilter selects tools, or prompts and resources, by glob patterns on their server-side names
+type ToolFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows reports whether a tool passes the filter: it must match an include pattern,
+// if any are set, and must not match any exclude pattern
+func (f ToolFilter) Allows(tool string) bool {
+	if len(f.Include) > 0 && !matchAny(f.Include, tool) {
+		return false
+	}
+	return !matchAny(f.Exclude, tool)
+}
+
+// Validate checks that every pattern is a well-formed glob
+func (f ToolFilter) Validate() error {
+	for _, pattern := range append(append([]string(nil), f.Include...), f.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid tool pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether the name matches any of the glob patterns
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// configToolFilter returns the filter declared for a server in mcp.json
+func configToolFilter(serverConfig config.MCPServer) ToolFilter {
+	return ToolFilter{Include: serverConfig.IncludeTools, Exclude: serverConfig.ExcludeTools}
+}
+
+// ParseFilters parses include/exclude specs into filters keyed by server. Each spec is either
+// "pattern", which applies to every server and is keyed by "", or "server:pattern".
+func ParseFilters(include, exclude []string) (map[string]ToolFilter, error) {
+	filters := make(map[string]ToolFilter)
+	add := func(specs []string, included bool) {
+		for _, spec := range specs {
+			server, pattern := "", spec
+			if i := strings.Index(spec, ":"); i >= 0 {
+				server, pattern = spec[:i], spec[i+1:]
+			}
+			f := filters[server]
+			if included {
+				f.Include = append(f.Include, pattern)
+			} else {
+				f.Exclude = append(f.Exclude, pattern)
+			}
+			filters[server] = f
+		}
+	}
+	add(include, true)
+	add(exclude, false)
+
+	for server, f := range filters {
+		if err := f.Validate(); err != nil {
+			return nil, err
+		}
+		if server != "" && f.Include == nil && f.Exclude == nil {
+			return nil, fmt.Errorf("empty tool pattern for server %s", server)
+		}
+	}
+	return filters, nil
+}
+
+// AddToolFilters applies additional include/exclude patterns on top of mcp.json. Each spec is
+// either "pattern", which applies to every server, or "server:pattern".
+func (m *Manager) AddToolFilters(include, exclude []string) error {
+	filters, err := ParseFilters(include, exclude)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	for server, f := range filters {
+		existing := m.toolFilters[server]
+		existing.Include = append(existing.Include, f.Include...)
+		existing.Exclude = append(existing.Exclude, f.Exclude...)
+		m.toolFilters[server] = existing
+	}
+	m.mu.Unlock()
+
+	m.InvalidateTools()
+	return nil
+}
+
+// ToolAllowed reports whether a server's tool passes both the mcp.json and command-line filters
+func (m *Manager) ToolAllowed(server, tool string) bool {
+	client, err := m.GetClient(server)
+	if err != nil {
+		return false
+	}
+	return m.toolAllowed(server, client.GetConfig(), tool)
+}
+
+// toolAllowed checks a tool against every filter that applies to its server
+func (m *Manager) toolAllowed(server string, serverConfig config.MCPServer, tool string) bool {
+	if !configToolFilter(serverConfig).Allows(tool) {
+		return false
+	}
+
+	m.mu.Lock()
+	global, specific := m.toolFilters[""], m.toolFilters[server]
+	m.mu.Unlock()
+
+	return global.Allows(tool) && specific.Allows(tool)
+}
@@ -0,0 +1,94 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestToolFilterAllows(t *testing.T) {
+	tests := []struct {
+		name     string
+		filter   ToolFilter
+		tool     string
+		expected bool
+	}{
+		{name: "empty filter allows everything", filter: ToolFilter{}, tool: "anything", expected: true},
+		{name: "include match", filter: ToolFilter{Include: []string{"read_*"}}, tool: "read_file", expected: true},
+		{name: "include miss", filter: ToolFilter{Include: []string{"read_*"}}, tool: "write_file", expected: false},
+		{name: "exclude match", filter: ToolFilter{Exclude: []string{"*delete*"}}, tool: "delete_file", expected: false},
+		{name: "exclude wins over include", filter: ToolFilter{Include: []string{"*"}, Exclude: []string{"rm"}}, tool: "rm", expected: false},
+		{name: "character class", filter: ToolFilter{Include: []string{"get_[ab]"}}, tool: "get_b", expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.filter.Allows(tt.tool))
+		})
+	}
+}
+
+func TestToolFilterValidate(t *testing.T) {
+	assert.NoError(t, ToolFilter{Include: []string{"read_*"}, Exclude: []string{"?x"}}.Validate())
+	assert.Error(t, ToolFilter{Exclude: []string{"[unclosed"}}.Validate())
+}
+
+func TestManagerAddToolFilters(t *testing.T) {
+	manager := NewManager(logrus.New())
+
+	require.NoError(t, manager.AddToolFilters([]string{"files:read_*"}, []string{"*delete*"}))
+	assert.Equal(t, []string{"read_*"}, manager.toolFilters["files"].Include)
+	assert.Equal(t, []string{"*delete*"}, manager.toolFilters[""].Exclude)
+
+	files := config.MCPServer{}
+	assert.True(t, manager.toolAllowed("files", files, "read_file"))
+	assert.False(t, manager.toolAllowed("files", files, "write_file"))
+	assert.False(t, manager.toolAllowed("web", files, "delete_page"))
+	assert.True(t, manager.toolAllowed("web", files, "fetch"))
+
+	withConfig := config.MCPServer{ExcludeTools: []string{"fetch"}}
+	assert.False(t, manager.toolAllowed("web", withConfig, "fetch"))
+
+	assert.Error(t, manager.AddToolFilters([]string{"files:[bad"}, nil))
+}
+
+func TestManagerFilteredToolsAreNotRoutable(t *testing.T) {
+	serverConfig := fakeServerConfig(t, nil)
+	serverConfig.ExcludeTools = []string{"crash"}
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+	require.NoError(t, manager.AddToolFilters(nil, []string{"fake:grow"}))
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": serverConfig}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	ctx := context.Background()
+	entries, err := manager.Tools(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "echo", entries[0].Name)
+
+	_, _, err = manager.ResolveTool(ctx, "crash")
+	assert.Error(t, err)
+	assert.False(t, manager.ToolAllowed("fake", "crash"))
+	assert.False(t, manager.ToolAllowed("fake", "grow"))
+	assert.True(t, manager.ToolAllowed("fake", "echo"))
+}
+
+func TestInitializeServerRejectsInvalidToolPattern(t *testing.T) {
+	serverConfig := fakeServerConfig(t, nil)
+	serverConfig.IncludeTools = []string{"[oops"}
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": serverConfig}}
+	assert.Error(t, manager.InitializeServers(mcpConfig, nil))
+	assert.Contains(t, manager.StartupReport()[0].Err.Error(), "invalid tool pattern")
+}
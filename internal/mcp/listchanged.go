@@ -0,0 +1,32 @@
+package mcp
+
+// Lists a server can announce changes to with a list_changed notification
+const (
+	ListTools     = "tools"
+	ListPrompts   = "prompts"
+	ListResources = "resources"
+)
+
+// ListChangedHandler is notified when a server announces that one of its lists changed
+type ListChangedHandler func(server, list string)
+
+// SetListChangedHandler registers a handler for the list_changed notifications of all servers
+func (m *Manager) SetListChangedHandler(handler ListChangedHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listChangedHandler = handler
+}
+
+// listChanged drops the cached tool index when tools changed and passes the notification on
+func (m *Manager) listChanged(server, list string) {
+	if list == ListTools {
+		m.InvalidateTools()
+	}
+
+	m.mu.Lock()
+	handler := m.listChangedHandler
+	m.mu.Unlock()
+	if handler != nil {
+		handler(server, list)
+	}
+}
@@ -0,0 +1,103 @@
+package mcp_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/mockserver"
+)
+
+// These tests run in their own package because the mock server imports the recording types
+// of package mcp.
+
+func TestManagerWithMockServers(t *testing.T) {
+	manager := mcp.NewManager(logrus.New())
+	defer manager.Close()
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"docs":   mcptest.MockServerConfig(t, mcptest.SearchFixture("docs"), mockserver.Options{}),
+		"issues": mcptest.MockServerConfig(t, mcptest.SearchFixture("issues"), mockserver.Options{}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	ctx := context.Background()
+	tools, err := manager.Tools(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range tools {
+		names = append(names, entry.Name)
+	}
+	assert.ElementsMatch(t, []string{"docs__search", "issues__search"}, names)
+
+	client, entry, err := manager.ResolveTool(ctx, "issues__search")
+	require.NoError(t, err)
+	result, err := client.CallTool(ctx, entry.Tool.Name, map[string]interface{}{"query": "bug"})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 1)
+	assert.Equal(t, "issues found bug", result.Content[0].(*sdk.TextContent).Text)
+
+	result, err = client.CallTool(ctx, entry.Tool.Name, map[string]interface{}{"query": "secret"})
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	docs, err := manager.GetClient("docs")
+	require.NoError(t, err)
+	resource, err := docs.ReadResource(ctx, "mem://docs")
+	require.NoError(t, err)
+	assert.Equal(t, "about docs", resource.Contents[0].Text)
+}
+
+func TestManagerWithFailingMockServer(t *testing.T) {
+	manager := mcp.NewManager(logrus.New())
+	defer manager.Close()
+
+	// Every request after the handshake fails, as a server under load might
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"flaky": mcptest.MockServerConfig(t, mcptest.SearchFixture("flaky"), mockserver.Options{ErrorRate: 1}),
+		"slow":  mcptest.MockServerConfig(t, mcptest.SearchFixture("slow"), mockserver.Options{Latency: 300 * time.Millisecond}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	checks := manager.CheckServers(context.Background(), 100*time.Millisecond)
+	require.Len(t, checks, 2)
+	for _, check := range checks {
+		assert.True(t, check.Failed(), check.Server)
+		for _, step := range check.Steps {
+			if step.Name == mcp.CheckListTools {
+				assert.Error(t, step.Err, check.Server)
+			}
+		}
+	}
+}
+
+func TestCheckSkipsUndeclaredListings(t *testing.T) {
+	manager := mcp.NewManager(logrus.New())
+	defer manager.Close()
+
+	// The mock server declares only the capabilities its fixture has, and answers listings of
+	// the others with its own wording of method not found
+	fixture := &mockserver.Fixture{Name: "tools", Tools: []mockserver.Tool{{Name: "echo", Responses: []mockserver.Response{{Text: "{{text}}"}}}}}
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"tools": mcptest.MockServerConfig(t, fixture, mockserver.Options{}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	checks := manager.CheckServers(context.Background(), 5*time.Second)
+	require.Len(t, checks, 1)
+	assert.False(t, checks[0].Failed())
+	for _, step := range checks[0].Steps {
+		if step.Name == mcp.CheckListResources || step.Name == mcp.CheckListPrompts {
+			assert.True(t, step.Skipped, step.Name)
+			assert.Equal(t, "not supported by the server", step.Detail)
+		}
+	}
+}
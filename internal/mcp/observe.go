@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// observer is called with every message exchanged with a server
+type observer func(direction string, msg mcp.JSONRPCMessage)
+
+// observedTransport passes every message of its connection to an observer
+type observedTransport struct {
+	mcp.Transport
+	observe observer
+}
+
+// Connect connects the wrapped transport and observes the connection
+func (t *observedTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &observedConnection{Connection: conn, observe: t.observe}, nil
+}
+
+// observedConnection passes messages to an observer as they are read and written
+type observedConnection struct {
+	mcp.Connection
+	observe observer
+}
+
+// Read reads a message from the server
+func (c *observedConnection) Read(ctx context.Context) (mcp.JSONRPCMessage, error) {
+	msg, err := c.Connection.Read(ctx)
+	if err == nil {
+		c.observe(DirectionReceive, msg)
+	}
+	return msg, err
+}
+
+// Write sends a message to the server
+func (c *observedConnection) Write(ctx context.Context, msg mcp.JSONRPCMessage) error {
+	c.observe(DirectionSend, msg)
+	return c.Connection.Write(ctx, msg)
+}
+
+// observe wraps a server's transport so its messages are recorded and traced, if enabled
+func (m *Manager) observe(name string, transport mcp.Transport) mcp.Transport {
+	m.mu.Lock()
+	recorder, tracer := m.recorder, m.tracer
+	m.mu.Unlock()
+	if recorder == nil && tracer == nil {
+		return transport
+	}
+	return &observedTransport{Transport: transport, observe: func(direction string, msg mcp.JSONRPCMessage) {
+		if recorder != nil {
+			recorder.Record(name, direction, msg)
+		}
+		if tracer != nil {
+			tracer.Trace(name, direction, msg)
+		}
+	}}
+}
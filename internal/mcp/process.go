@@ -0,0 +1,48 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/constants"
+)
+
+// processTransport records the process a stdio transport starts when it connects
+type processTransport struct {
+	mcp.Transport
+	cmd     *exec.Cmd
+	started func(pid int)
+}
+
+// Connect starts the server process and records its ID
+func (t *processTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	conn, err := t.Transport.Connect(ctx)
+	if err == nil && t.cmd.Process != nil {
+		t.started(t.cmd.Process.Pid)
+	}
+	return conn, err
+}
+
+// trackProcess wraps a stdio transport so the server's process can be looked up once it starts
+func (m *Manager) trackProcess(name string, cmd *exec.Cmd, transport mcp.Transport) mcp.Transport {
+	return &processTransport{Transport: transport, cmd: cmd, started: func(pid int) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.processes[name] = pid
+	}}
+}
+
+// ServerProcess returns the process ID of a stdio server, from the most recent time it was
+// started. Reconnecting starts a new process.
+func (m *Manager) ServerProcess(name string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pid, ok := m.processes[name]
+	if !ok {
+		return 0, fmt.Errorf("server %s has no process; only stdio servers are started by %s", name, constants.AppName)
+	}
+	return pid, nil
+}
@@ -0,0 +1,28 @@
+package mcp
+
+import (
+	"os"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestManagerServerProcess(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	_, err := manager.ServerProcess("fake")
+	assert.Error(t, err)
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	pid, err := manager.ServerProcess("fake")
+	require.NoError(t, err)
+	assert.Positive(t, pid)
+	assert.NotEqual(t, os.Getpid(), pid)
+}
@@ -0,0 +1,285 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+)
+
+// healthCheckTimeout bounds the ping used to decide whether a failed call means the server is gone
+const healthCheckTimeout = 5 * time.Second
+
+// ConnectionState describes the lifecycle of a server connection
+type ConnectionState string
+
+const (
+	// StateConnected means the session is initialized and usable
+	StateConnected ConnectionState = "connected"
+	// StateDisconnected means the session dropped and has not been restarted yet
+	StateDisconnected ConnectionState = "disconnected"
+	// StateReconnecting means a reconnect attempt is pending or in progress
+	StateReconnecting ConnectionState = "reconnecting"
+	// StateFailed means the retry budget is exhausted and the server is unusable
+	StateFailed ConnectionState = "failed"
+	// StateDisabled means the server was disconnected on request and can be enabled again
+	StateDisabled ConnectionState = "disabled"
+)
+
+// StateChange describes a connection state transition for a single server
+type StateChange struct {
+	Server      string
+	State       ConnectionState
+	Attempt     int
+	MaxAttempts int
+	Delay       time.Duration
+	Tools       int
+	Err         error
+}
+
+// StateHandler is notified of connection state transitions
+type StateHandler func(change StateChange)
+
+// reconnectPolicy is the resolved form of config.MCPReconnect
+type reconnectPolicy struct {
+	enabled        bool
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+// newReconnectPolicy resolves the reconnect configuration, falling back to defaults for unset values
+func newReconnectPolicy(cfg *config.MCPReconnect) (reconnectPolicy, error) {
+	policy := reconnectPolicy{
+		enabled:        true,
+		maxRetries:     constants.DefaultReconnectRetries,
+		initialBackoff: constants.DefaultReconnectInitialBackoff,
+		maxBackoff:     constants.DefaultReconnectMaxBackoff,
+	}
+	if cfg == nil {
+		return policy, nil
+	}
+
+	policy.enabled = !cfg.Disabled
+	if cfg.MaxRetries > 0 {
+		policy.maxRetries = cfg.MaxRetries
+	}
+
+	var errs []error
+	if cfg.InitialBackoff != "" {
+		d, err := time.ParseDuration(cfg.InitialBackoff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid initial_backoff %q: %w", cfg.InitialBackoff, err))
+		} else {
+			policy.initialBackoff = d
+		}
+	}
+	if cfg.MaxBackoff != "" {
+		d, err := time.ParseDuration(cfg.MaxBackoff)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid max_backoff %q: %w", cfg.MaxBackoff, err))
+		} else {
+			policy.maxBackoff = d
+		}
+	}
+	if policy.maxBackoff < policy.initialBackoff {
+		policy.maxBackoff = policy.initialBackoff
+	}
+
+	return policy, errors.Join(errs...)
+}
+
+// backoff returns the delay before the given (1-based) reconnect attempt
+func (p reconnectPolicy) backoff(attempt int) time.Duration {
+	delay := p.initialBackoff
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= p.maxBackoff {
+			return p.maxBackoff
+		}
+	}
+	return delay
+}
+
+// currentSession returns the live session, or an error if the server is not connected
+func (c *Client) currentSession() (*mcp.ClientSession, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.session == nil {
+		return nil, fmt.Errorf("server %s is not connected", c.name)
+	}
+	if c.state != StateConnected {
+		return nil, fmt.Errorf("server %s is %s", c.name, c.state)
+	}
+	return c.session, nil
+}
+
+// checkConnection inspects a call error and tears down the session if the server is no longer reachable,
+// which hands it over to the reconnect loop
+func (c *Client) checkConnection(session *mcp.ClientSession, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	if !errors.Is(err, mcp.ErrConnectionClosed) {
+		// The server may simply have rejected the request; only treat it as dead if it stops answering pings
+		ctx, cancel := context.WithTimeout(context.Background(), healthCheckTimeout)
+		pingErr := session.Ping(ctx, &mcp.PingParams{})
+		cancel()
+		if pingErr == nil {
+			return err
+		}
+	}
+
+	c.logger.WithError(err).Warn("Server connection lost")
+	_ = session.Close()
+	return fmt.Errorf("server %s connection lost: %w", c.name, err)
+}
+
+// setState updates the connection state of the client
+func (c *Client) setState(state ConnectionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.state = state
+}
+
+// isClosed reports whether the client was closed on purpose
+func (c *Client) isClosed() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.closed
+}
+
+// watch waits for the session to end and starts reconnecting if it ended unexpectedly
+func (m *Manager) watch(c *Client, session *mcp.ClientSession) {
+	err := session.Wait()
+
+	c.mu.Lock()
+	if c.closed || c.session != session {
+		c.mu.Unlock()
+		return
+	}
+	c.state = StateDisconnected
+	c.mu.Unlock()
+
+	if m.isClosing() {
+		return
+	}
+
+	if err == nil {
+		err = errors.New("connection closed by server")
+	}
+	m.InvalidateTools()
+	m.notify(StateChange{Server: c.name, State: StateDisconnected, Err: err})
+
+	policy, perr := newReconnectPolicy(c.config.Reconnect)
+	if perr != nil {
+		c.logger.WithError(perr).Warn("Invalid reconnect configuration, using defaults")
+	}
+	if !policy.enabled {
+		c.setState(StateFailed)
+		m.notify(StateChange{Server: c.name, State: StateFailed, Err: err})
+		return
+	}
+
+	m.reconnect(c, policy, err)
+}
+
+// reconnect restarts the server with exponential backoff until it succeeds or the retry budget is exhausted
+func (m *Manager) reconnect(c *Client, policy reconnectPolicy, lastErr error) {
+	for attempt := 1; attempt <= policy.maxRetries; attempt++ {
+		delay := policy.backoff(attempt)
+		c.setState(StateReconnecting)
+		m.notify(StateChange{
+			Server:      c.name,
+			State:       StateReconnecting,
+			Attempt:     attempt,
+			MaxAttempts: policy.maxRetries,
+			Delay:       delay,
+			Err:         lastErr,
+		})
+
+		select {
+		case <-time.After(delay):
+		case <-m.done:
+			return
+		}
+		if c.isClosed() {
+			return
+		}
+
+		// The startup timeout was validated when the server was first initialized
+		timeout, _ := startupTimeout(c.config)
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		mcpClient, session, initialized, err := m.connect(ctx, c.name, c.config)
+		var tools *mcp.ListToolsResult
+		if err == nil {
+			// Re-run tool discovery so a server that comes back broken counts as a failed attempt
+			tools, err = session.ListTools(ctx, &mcp.ListToolsParams{})
+			if err != nil {
+				_ = session.Close()
+				err = fmt.Errorf("tool discovery failed: %w", err)
+			}
+		}
+		cancel()
+
+		if err != nil {
+			c.logger.WithError(err).Warnf("Reconnect attempt %d/%d failed", attempt, policy.maxRetries)
+			lastErr = err
+			continue
+		}
+
+		c.mu.Lock()
+		if c.closed {
+			c.mu.Unlock()
+			_ = session.Close()
+			return
+		}
+		c.client = mcpClient
+		c.session = session
+		c.initialized = initialized
+		c.state = StateConnected
+		c.mu.Unlock()
+
+		m.InvalidateTools()
+		c.logger.Infof("Reconnected after %d attempt(s)", attempt)
+		m.notify(StateChange{
+			Server:      c.name,
+			State:       StateConnected,
+			Attempt:     attempt,
+			MaxAttempts: policy.maxRetries,
+			Tools:       len(tools.Tools),
+		})
+
+		go m.watch(c, session)
+		return
+	}
+
+	c.setState(StateFailed)
+	c.logger.WithError(lastErr).Errorf("Giving up after %d reconnect attempts", policy.maxRetries)
+	m.notify(StateChange{Server: c.name, State: StateFailed, MaxAttempts: policy.maxRetries, Err: lastErr})
+}
+
+// notify forwards a state change to the registered handler, if any
+func (m *Manager) notify(change StateChange) {
+	m.mu.Lock()
+	handler := m.stateHandler
+	m.mu.Unlock()
+
+	if handler != nil {
+		handler(change)
+	}
+}
+
+// isClosing reports whether the manager is shutting down
+func (m *Manager) isClosing() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closing
+}
@@ -0,0 +1,159 @@
+package mcp
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+)
+
+func TestReconnectPolicyDefaults(t *testing.T) {
+	policy, err := newReconnectPolicy(nil)
+	require.NoError(t, err)
+
+	assert.True(t, policy.enabled)
+	assert.Equal(t, constants.DefaultReconnectRetries, policy.maxRetries)
+	assert.Equal(t, constants.DefaultReconnectInitialBackoff, policy.initialBackoff)
+	assert.Equal(t, constants.DefaultReconnectMaxBackoff, policy.maxBackoff)
+}
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	policy, err := newReconnectPolicy(&config.MCPReconnect{
+		InitialBackoff: "100ms",
+		MaxBackoff:     "1s",
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{1, 100 * time.Millisecond},
+		{2, 200 * time.Millisecond},
+		{3, 400 * time.Millisecond},
+		{4, 800 * time.Millisecond},
+		{5, time.Second},
+		{10, time.Second},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, policy.backoff(tt.attempt), "attempt %d", tt.attempt)
+	}
+}
+
+func TestReconnectPolicyInvalidDuration(t *testing.T) {
+	policy, err := newReconnectPolicy(&config.MCPReconnect{
+		MaxRetries:     2,
+		InitialBackoff: "soon",
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "initial_backoff")
+	assert.Equal(t, 2, policy.maxRetries)
+	assert.Equal(t, constants.DefaultReconnectInitialBackoff, policy.initialBackoff)
+}
+
+// collectStates records state changes and lets tests wait for a specific one
+func collectStates(manager *Manager) <-chan StateChange {
+	changes := make(chan StateChange, 32)
+	manager.SetStateHandler(func(change StateChange) {
+		changes <- change
+	})
+	return changes
+}
+
+func waitForState(t *testing.T, changes <-chan StateChange, state ConnectionState) StateChange {
+	t.Helper()
+	timeout := time.After(20 * time.Second)
+	for {
+		select {
+		case change := <-changes:
+			if change.State == state {
+				return change
+			}
+		case <-timeout:
+			t.Fatalf("timed out waiting for state %s", state)
+		}
+	}
+}
+
+func TestManagerReconnectsCrashedServer(t *testing.T) {
+	serverConfig := fakeServerConfig(t, nil)
+	serverConfig.Reconnect = &config.MCPReconnect{MaxRetries: 3, InitialBackoff: "10ms"}
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+	changes := collectStates(manager)
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": serverConfig}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = client.CallTool(ctx, "echo", map[string]interface{}{"message": "before"})
+	require.NoError(t, err)
+
+	_, err = client.CallTool(ctx, "crash", map[string]interface{}{})
+	assert.Error(t, err)
+
+	waitForState(t, changes, StateDisconnected)
+	connected := waitForState(t, changes, StateConnected)
+	assert.Equal(t, 1, connected.Attempt)
+	assert.Equal(t, 3, connected.Tools)
+	assert.Equal(t, StateConnected, client.State())
+
+	result, err := client.CallTool(ctx, "echo", map[string]interface{}{"message": "after"})
+	require.NoError(t, err)
+	assert.False(t, result.IsError)
+}
+
+func TestManagerGivesUpAfterRetryBudget(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "crashed")
+	serverConfig := fakeServerConfig(t, map[string]string{fakeCrashMarkerEnv: marker})
+	serverConfig.Reconnect = &config.MCPReconnect{MaxRetries: 2, InitialBackoff: "10ms"}
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+	changes := collectStates(manager)
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": serverConfig}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+
+	_, err = client.CallTool(context.Background(), "crash", map[string]interface{}{})
+	assert.Error(t, err)
+
+	failed := waitForState(t, changes, StateFailed)
+	assert.Equal(t, 2, failed.MaxAttempts)
+	assert.Error(t, failed.Err)
+	assert.Equal(t, StateFailed, client.State())
+
+	_, err = client.CallTool(context.Background(), "echo", map[string]interface{}{"message": "x"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed")
+}
+
+func TestManagerCloseDoesNotReconnect(t *testing.T) {
+	manager := NewManager(logrus.New())
+	changes := collectStates(manager)
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+	require.NoError(t, manager.Close())
+
+	select {
+	case change := <-changes:
+		t.Fatalf("unexpected state change after close: %+v", change)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,164 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Directions of recorded messages
+const (
+	// DirectionSend is a message from the client to the server
+	DirectionSend = "send"
+	// DirectionReceive is a message from the server to the client
+	DirectionReceive = "receive"
+)
+
+// RecordedMessage is one JSON-RPC message in a recording
+type RecordedMessage struct {
+	Time      time.Time       `json:"time"`
+	Server    string          `json:"server"`
+	Direction string          `json:"direction"`
+	Message   json.RawMessage `json:"message"`
+}
+
+// Recorder writes every JSON-RPC message exchanged with servers to a JSONL file, one
+// RecordedMessage per line. It is safe for concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+	err    error
+}
+
+// NewRecorder creates a recorder writing to w
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{w: w}
+}
+
+// CreateRecorder creates a recorder writing to a new file at path
+func CreateRecorder(path string) (*Recorder, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recording: %w", err)
+	}
+	return &Recorder{w: file, closer: file}, nil
+}
+
+// Record writes a message. The first write error is kept and returned by Close.
+func (r *Recorder) Record(server, direction string, msg mcp.JSONRPCMessage) {
+	data, err := EncodeMessage(msg)
+	r.record(server, direction, data, err)
+}
+
+// RecordRaw writes a message in the wire form it was sent or received in on a raw connection.
+// Frames that are not JSON are left out, since a recording holds JSON-RPC messages.
+func (r *Recorder) RecordRaw(server, direction string, data []byte) {
+	if json.Valid(data) {
+		r.record(server, direction, data, nil)
+	}
+}
+
+// record writes a message in its wire form, or keeps the error that kept it from being encoded
+func (r *Recorder) record(server, direction string, data []byte, err error) {
+	if err == nil {
+		data, err = json.Marshal(RecordedMessage{Time: time.Now().UTC(), Server: server, Direction: direction, Message: data})
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil && r.err == nil {
+		_, err = r.w.Write(append(data, '\n'))
+	}
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("failed to record message: %w", err)
+	}
+}
+
+// Close closes the recording file and reports the first error seen while recording
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closer != nil {
+		if err := r.closer.Close(); err != nil && r.err == nil {
+			r.err = err
+		}
+		r.closer = nil
+	}
+	return r.err
+}
+
+// ReadRecording parses a recording written by a Recorder
+func ReadRecording(r io.Reader) ([]RecordedMessage, error) {
+	var messages []RecordedMessage
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var msg RecordedMessage
+		if err := json.Unmarshal([]byte(text), &msg); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if msg.Direction != DirectionSend && msg.Direction != DirectionReceive {
+			return nil, fmt.Errorf("line %d: invalid direction %q", line, msg.Direction)
+		}
+		messages = append(messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording: %w", err)
+	}
+	return messages, nil
+}
+
+// wireMessage is the JSON-RPC 2.0 wire form of a message
+type wireMessage struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      interface{}     `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   interface{}     `json:"error,omitempty"`
+}
+
+// EncodeMessage returns the JSON-RPC wire form of a message
+func EncodeMessage(msg mcp.JSONRPCMessage) ([]byte, error) {
+	wire := wireMessage{JSONRPC: "2.0"}
+	switch msg := msg.(type) {
+	case *mcp.JSONRPCRequest:
+		wire.ID = msg.ID.Raw()
+		wire.Method = msg.Method
+		wire.Params = msg.Params
+	case *mcp.JSONRPCResponse:
+		wire.ID = msg.ID.Raw()
+		wire.Result = msg.Result
+		if msg.Error != nil {
+			// The SDK's wire errors marshal to a JSON-RPC error object; others only have a message
+			wire.Error = msg.Error
+			if data, err := json.Marshal(msg.Error); err != nil || string(data) == "{}" {
+				wire.Error = map[string]interface{}{"code": 0, "message": msg.Error.Error()}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown message type %T", msg)
+	}
+	return json.Marshal(wire)
+}
+
+// SetRecorder records the traffic of every server connected afterwards, including reconnects
+func (m *Manager) SetRecorder(recorder *Recorder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.recorder = recorder
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestRecorderRecordsTraffic(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+
+	manager := NewManager(logrus.New())
+	manager.SetRecorder(recorder)
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	_, err = client.CallTool(context.Background(), "echo", map[string]interface{}{"message": "hi"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Close())
+	require.NoError(t, recorder.Close())
+
+	messages, err := ReadRecording(&buf)
+	require.NoError(t, err)
+
+	type wire struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Result json.RawMessage `json:"result"`
+	}
+	var sent []string
+	received := make(map[string]json.RawMessage)
+	for _, msg := range messages {
+		assert.Equal(t, "fake", msg.Server)
+		assert.False(t, msg.Time.IsZero())
+
+		var w wire
+		require.NoError(t, json.Unmarshal(msg.Message, &w))
+		if msg.Direction == DirectionSend {
+			sent = append(sent, w.Method)
+		} else if w.Method == "" {
+			received[string(w.ID)] = w.Result
+		}
+	}
+
+	require.GreaterOrEqual(t, len(sent), 3)
+	assert.Equal(t, []string{"initialize", "notifications/initialized"}, sent[:2])
+	assert.Contains(t, sent, "tools/call")
+	assert.Contains(t, string(received["1"]), "protocolVersion")
+
+	last := messages[len(messages)-1]
+	assert.Equal(t, DirectionReceive, last.Direction)
+	assert.Contains(t, string(last.Message), "hi")
+}
+
+func TestEncodeMessage(t *testing.T) {
+	call, err := json.Marshal(map[string]interface{}{"name": "echo"})
+	require.NoError(t, err)
+
+	request := &mcp.JSONRPCRequest{Method: "tools/call", Params: call}
+	data, err := EncodeMessage(request)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "method": "tools/call", "params": {"name": "echo"}}`, string(data))
+
+	response := &mcp.JSONRPCResponse{Error: errors.New("boom")}
+	data, err = EncodeMessage(response)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "error": {"code": 0, "message": "boom"}}`, string(data))
+}
+
+func TestReadRecordingRejectsInvalidLines(t *testing.T) {
+	_, err := ReadRecording(strings.NewReader(`{"server": "a", "direction": "sideways", "message": {}}`))
+	assert.EqualError(t, err, `line 1: invalid direction "sideways"`)
+
+	_, err = ReadRecording(strings.NewReader("\nnot json\n"))
+	assert.ErrorContains(t, err, "line 2:")
+}
+
+func TestRecorderRecordsRawMessages(t *testing.T) {
+	var buf bytes.Buffer
+	recorder := NewRecorder(&buf)
+	recorder.RecordRaw("raw", DirectionSend, []byte(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+	recorder.RecordRaw("raw", DirectionReceive, []byte("not json"))
+	recorder.RecordRaw("raw", DirectionReceive, []byte(`{"jsonrpc": "2.0", "id": 1, "result": {}}`))
+	require.NoError(t, recorder.Close())
+
+	messages, err := ReadRecording(&buf)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, DirectionSend, messages[0].Direction)
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "method": "ping"}`, string(messages[0].Message))
+	assert.Equal(t, DirectionReceive, messages[1].Direction)
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/config"
+)
+
+// ToolNamespaceSeparator joins a server name and a tool name when tool names collide
+const ToolNamespaceSeparator = "__"
+
+// Tool naming modes for config.MCPConfig.ToolNaming
+const (
+	// ToolNamingAuto namespaces only tool names exported by more than one server
+	ToolNamingAuto = "auto"
+	// ToolNamingNamespace namespaces every tool as server__tool
+	ToolNamingNamespace = "namespace"
+)
+
+// ToolEntry maps the name a tool is exposed under onto the server tool that backs it
+type ToolEntry struct {
+	// Name is the unique name callers use; Tool.Name is the name on the server
+	Name   string
+	Server string
+	Tool   *mcp.Tool
+}
+
+// toolRegistry caches which server owns which tool. mu guards the index and is never held
+// across server calls, so list_changed notifications can invalidate it while a refresh is running.
+type toolRegistry struct {
+	mu      sync.Mutex
+	entries map[string]ToolEntry
+	order   []string
+	valid   bool
+	version uint64
+	naming  string
+
+	refreshMu sync.Mutex
+}
+
+// SetToolNaming sets how tool names are exposed when several servers are connected
+func (m *Manager) SetToolNaming(mode string) error {
+	switch mode {
+	case "":
+		mode = ToolNamingAuto
+	case ToolNamingAuto, ToolNamingNamespace:
+	default:
+		return fmt.Errorf("invalid tool naming mode %q (expected %s or %s)", mode, ToolNamingAuto, ToolNamingNamespace)
+	}
+
+	m.tools.mu.Lock()
+	m.tools.naming = mode
+	m.tools.mu.Unlock()
+	m.InvalidateTools()
+	return nil
+}
+
+// InvalidateTools drops the cached tool index so the next lookup re-lists every server
+func (m *Manager) InvalidateTools() {
+	m.tools.mu.Lock()
+	defer m.tools.mu.Unlock()
+	m.tools.valid = false
+	m.tools.version++
+}
+
+// ToolsVersion returns a counter that changes every time the tool index is invalidated
+func (m *Manager) ToolsVersion() uint64 {
+	m.tools.mu.Lock()
+	defer m.tools.mu.Unlock()
+	return m.tools.version
+}
+
+// Tools returns every tool across all connected servers under its unique name
+func (m *Manager) Tools(ctx context.Context) ([]ToolEntry, error) {
+	if err := m.refreshTools(ctx, false); err != nil {
+		return nil, err
+	}
+
+	m.tools.mu.Lock()
+	defer m.tools.mu.Unlock()
+
+	entries := make([]ToolEntry, 0, len(m.tools.order))
+	for _, name := range m.tools.order {
+		entries = append(entries, m.tools.entries[name])
+	}
+	return entries, nil
+}
+
+// ResolveTool finds the client and server-side tool for an exposed tool name
+func (m *Manager) ResolveTool(ctx context.Context, name string) (*Client, ToolEntry, error) {
+	entry, exists, err := m.lookupTool(ctx, name, false)
+	if err == nil && !exists {
+		// The cached index may predate a server change we were not notified about
+		entry, exists, err = m.lookupTool(ctx, name, true)
+	}
+	if err != nil {
+		return nil, ToolEntry{}, err
+	}
+	if !exists {
+		return nil, ToolEntry{}, fmt.Errorf("tool %s not found in any connected server", name)
+	}
+
+	client, err := m.GetClient(entry.Server)
+	if err != nil {
+		return nil, ToolEntry{}, err
+	}
+	return client, entry, nil
+}
+
+// lookupTool looks up an exposed tool name in the index, refreshing it first if needed
+func (m *Manager) lookupTool(ctx context.Context, name string, force bool) (ToolEntry, bool, error) {
+	if err := m.refreshTools(ctx, force); err != nil {
+		return ToolEntry{}, false, err
+	}
+
+	m.tools.mu.Lock()
+	defer m.tools.mu.Unlock()
+	entry, exists := m.tools.entries[name]
+	return entry, exists, nil
+}
+
+// refreshTools rebuilds the tool index if it has been invalidated, or unconditionally when forced
+func (m *Manager) refreshTools(ctx context.Context, force bool) error {
+	m.tools.refreshMu.Lock()
+	defer m.tools.refreshMu.Unlock()
+
+	m.tools.mu.Lock()
+	if m.tools.valid && !force {
+		m.tools.mu.Unlock()
+		return nil
+	}
+	version := m.tools.version
+	naming := m.tools.naming
+	m.tools.mu.Unlock()
+
+	clients := m.GetAllClients()
+	serverNames := make([]string, 0, len(clients))
+	for name := range clients {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+
+	var listed []serverTools
+	for _, name := range serverNames {
+		client := clients[name]
+		tools, err := client.AllTools(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			client.logger.WithError(err).Warn("Failed to list tools for routing")
+			continue
+		}
+		listed = append(listed, serverTools{server: name, config: client.GetConfig(), tools: tools})
+	}
+
+	entries, order := buildToolIndex(listed, naming, m.toolAllowed, m.logger.Warnf)
+
+	m.tools.mu.Lock()
+	defer m.tools.mu.Unlock()
+	m.tools.entries, m.tools.order = entries, order
+	// An invalidation that raced with this refresh leaves the index marked stale
+	m.tools.valid = m.tools.version == version
+	return nil
+}
+
+// serverTools is the tool listing of a single server
+type serverTools struct {
+	server string
+	config config.MCPServer
+	tools  []*mcp.Tool
+}
+
+// buildToolIndex assigns each allowed tool a unique exposed name according to the naming mode.
+// Filtered tools are dropped before collisions are counted, so they never force namespacing.
+func buildToolIndex(listed []serverTools, naming string, allowed func(string, config.MCPServer, string) bool, warnf func(string, ...interface{})) (map[string]ToolEntry, []string) {
+	baseName := func(st serverTools, tool *mcp.Tool) string {
+		return st.config.ToolPrefix + tool.Name
+	}
+
+	for i, st := range listed {
+		kept := make([]*mcp.Tool, 0, len(st.tools))
+		for _, tool := range st.tools {
+			if allowed(st.server, st.config, tool.Name) {
+				kept = append(kept, tool)
+			}
+		}
+		listed[i].tools = kept
+	}
+
+	owners := make(map[string]int)
+	for _, st := range listed {
+		for _, tool := range st.tools {
+			owners[baseName(st, tool)]++
+		}
+	}
+
+	entries := make(map[string]ToolEntry)
+	order := make([]string, 0)
+	for _, st := range listed {
+		for _, tool := range st.tools {
+			name := baseName(st, tool)
+			if naming == ToolNamingNamespace || owners[name] > 1 {
+				name = st.server + ToolNamespaceSeparator + tool.Name
+			}
+			if existing, taken := entries[name]; taken {
+				warnf("Tool %s from server %s conflicts with %s from server %s, skipping", tool.Name, st.server, existing.Tool.Name, existing.Server)
+				continue
+			}
+			entries[name] = ToolEntry{Name: name, Server: st.server, Tool: tool}
+			order = append(order, name)
+		}
+	}
+
+	return entries, order
+}
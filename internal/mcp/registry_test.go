@@ -0,0 +1,187 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func toolsNamed(names ...string) []*mcp.Tool {
+	tools := make([]*mcp.Tool, len(names))
+	for i, name := range names {
+		tools[i] = &mcp.Tool{Name: name}
+	}
+	return tools
+}
+
+func allowAll(string, config.MCPServer, string) bool { return true }
+
+func TestBuildToolIndex(t *testing.T) {
+	listed := []serverTools{
+		{server: "files", tools: toolsNamed("read", "search")},
+		{server: "web", tools: toolsNamed("fetch", "search")},
+		{server: "db", config: config.MCPServer{ToolPrefix: "db_"}, tools: toolsNamed("query", "search")},
+	}
+
+	tests := []struct {
+		name     string
+		naming   string
+		expected map[string]string
+	}{
+		{
+			name:   "auto namespaces only collisions",
+			naming: ToolNamingAuto,
+			expected: map[string]string{
+				"read":          "files",
+				"files__search": "files",
+				"fetch":         "web",
+				"web__search":   "web",
+				"db_query":      "db",
+				"db_search":     "db",
+			},
+		},
+		{
+			name:   "namespace everything",
+			naming: ToolNamingNamespace,
+			expected: map[string]string{
+				"files__read":   "files",
+				"files__search": "files",
+				"web__fetch":    "web",
+				"web__search":   "web",
+				"db__query":     "db",
+				"db__search":    "db",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, order := buildToolIndex(listed, tt.naming, allowAll, t.Logf)
+			assert.Len(t, order, len(tt.expected))
+			for name, server := range tt.expected {
+				require.Contains(t, entries, name)
+				assert.Equal(t, server, entries[name].Server)
+			}
+		})
+	}
+}
+
+func TestBuildToolIndexKeepsServerSideName(t *testing.T) {
+	entries, _ := buildToolIndex([]serverTools{
+		{server: "a", tools: toolsNamed("echo")},
+		{server: "b", tools: toolsNamed("echo")},
+	}, ToolNamingAuto, allowAll, t.Logf)
+
+	assert.Equal(t, "echo", entries["a__echo"].Tool.Name)
+	assert.Equal(t, "echo", entries["b__echo"].Tool.Name)
+}
+
+func TestBuildToolIndexFiltersBeforeCollisions(t *testing.T) {
+	listed := []serverTools{
+		{server: "a", tools: toolsNamed("echo", "delete")},
+		{server: "b", tools: toolsNamed("echo")},
+	}
+	onlyA := func(server string, _ config.MCPServer, tool string) bool {
+		return server == "a" && tool != "delete"
+	}
+
+	entries, order := buildToolIndex(listed, ToolNamingAuto, onlyA, t.Logf)
+	assert.Equal(t, []string{"echo"}, order)
+	assert.Equal(t, "a", entries["echo"].Server)
+}
+
+func TestSetToolNaming(t *testing.T) {
+	manager := NewManager(logrus.New())
+	assert.NoError(t, manager.SetToolNaming(""))
+	assert.NoError(t, manager.SetToolNaming(ToolNamingNamespace))
+	assert.Error(t, manager.SetToolNaming("prefix-everything"))
+}
+
+func TestManagerResolvesDuplicateToolNames(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"alpha": fakeServerConfig(t, nil),
+		"beta":  fakeServerConfig(t, nil),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	ctx := context.Background()
+	entries, err := manager.Tools(ctx)
+	require.NoError(t, err)
+
+	names := make([]string, len(entries))
+	for i, entry := range entries {
+		names[i] = entry.Name
+	}
+	assert.Contains(t, names, "alpha__echo")
+	assert.Contains(t, names, "beta__echo")
+	assert.NotContains(t, names, "echo")
+
+	client, entry, err := manager.ResolveTool(ctx, "beta__echo")
+	require.NoError(t, err)
+	assert.Equal(t, "beta", client.GetName())
+	assert.Equal(t, "echo", entry.Tool.Name)
+
+	_, _, err = manager.ResolveTool(ctx, "echo")
+	assert.Error(t, err)
+}
+
+func TestManagerToolIndexInvalidatedOnListChanged(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	ctx := context.Background()
+	_, _, err := manager.ResolveTool(ctx, "echo")
+	require.NoError(t, err)
+	version := manager.ToolsVersion()
+
+	client, _, err := manager.ResolveTool(ctx, "grow")
+	require.NoError(t, err)
+	_, err = client.CallTool(ctx, "grow", map[string]interface{}{})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return manager.ToolsVersion() != version
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, entry, err := manager.ResolveTool(ctx, "extra")
+	require.NoError(t, err)
+	assert.Equal(t, "fake", entry.Server)
+}
+
+func TestManagerListChangedHandler(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	changes := make(chan string, 4)
+	manager.SetListChangedHandler(func(server, list string) {
+		changes <- server + "/" + list
+	})
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	_, err = client.CallTool(context.Background(), "grow", map[string]interface{}{})
+	require.NoError(t, err)
+
+	select {
+	case change := <-changes:
+		assert.Equal(t, "fake/"+ListTools, change)
+	case <-time.After(5 * time.Second):
+		t.Fatal("list_changed notification was not passed on")
+	}
+}
@@ -0,0 +1,221 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"mcp_tstr/internal/config"
+)
+
+// ServerStatus describes a server known to the manager
+type ServerStatus struct {
+	Name      string
+	Transport string
+	State     ConnectionState
+	Enabled   bool
+}
+
+// AddServer connects to a server at runtime and makes it available to callers
+func (m *Manager) AddServer(name string, serverConfig config.MCPServer) error {
+	if err := m.checkOpen(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	existing, exists := m.clients[name]
+	m.mu.Unlock()
+	if exists {
+		return alreadyAdded(existing)
+	}
+
+	client, err := m.initializeServer(name, serverConfig)
+	if err != nil {
+		return fmt.Errorf("failed to initialize server %s: %w", name, err)
+	}
+
+	m.mu.Lock()
+	if existing, exists := m.clients[name]; exists || m.closing {
+		m.mu.Unlock()
+		_ = client.Close()
+		if !exists {
+			return fmt.Errorf("manager is closed")
+		}
+		return alreadyAdded(existing)
+	}
+	m.clients[name] = client
+	delete(m.disabled, name)
+	m.mu.Unlock()
+	m.InvalidateTools()
+
+	m.logger.Infof("Added MCP server: %s", name)
+	return nil
+}
+
+// alreadyAdded describes why a server that is already known cannot be added again
+func alreadyAdded(client *Client) error {
+	if state := client.State(); state != StateConnected {
+		return fmt.Errorf("server %s is already added and %s", client.name, state)
+	}
+	return fmt.Errorf("server %s is already connected", client.name)
+}
+
+// RetryServer connects a server again after its reconnect attempts ran out
+func (m *Manager) RetryServer(name string) error {
+	if err := m.checkOpen(); err != nil {
+		return err
+	}
+	client, err := m.GetClient(name)
+	if err != nil {
+		return err
+	}
+	if state := client.State(); state != StateFailed {
+		return fmt.Errorf("server %s is %s", name, state)
+	}
+
+	// The startup timeout was validated when the server was first initialized
+	timeout, _ := startupTimeout(client.config)
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	mcpClient, session, initialized, err := m.connect(ctx, name, client.config)
+	if err != nil {
+		return fmt.Errorf("failed to reconnect server %s: %w", name, err)
+	}
+
+	client.mu.Lock()
+	if client.closed || client.state != StateFailed {
+		client.mu.Unlock()
+		_ = session.Close()
+		return fmt.Errorf("server %s changed while reconnecting", name)
+	}
+	client.client = mcpClient
+	client.session = session
+	client.initialized = initialized
+	client.state = StateConnected
+	client.mu.Unlock()
+	m.InvalidateTools()
+
+	go m.watch(client, session)
+	m.logger.Infof("Reconnected MCP server: %s", name)
+	return nil
+}
+
+// RemoveServer disconnects a server and forgets about it
+func (m *Manager) RemoveServer(name string) error {
+	m.mu.Lock()
+	client, exists := m.clients[name]
+	_, disabled := m.disabled[name]
+	delete(m.clients, name)
+	delete(m.disabled, name)
+	m.mu.Unlock()
+	m.InvalidateTools()
+
+	if !exists {
+		if disabled {
+			return nil
+		}
+		return fmt.Errorf("server %s not found", name)
+	}
+
+	m.logger.Infof("Removed MCP server: %s", name)
+	return client.Close()
+}
+
+// DisableServer disconnects a server but keeps its configuration so it can be enabled again
+func (m *Manager) DisableServer(name string) error {
+	m.mu.Lock()
+	client, exists := m.clients[name]
+	if !exists {
+		m.mu.Unlock()
+		if m.isDisabled(name) {
+			return nil
+		}
+		return fmt.Errorf("server %s not found", name)
+	}
+	delete(m.clients, name)
+	m.disabled[name] = client.GetConfig()
+	m.mu.Unlock()
+	m.InvalidateTools()
+
+	m.logger.Infof("Disabled MCP server: %s", name)
+	return client.Close()
+}
+
+// EnableServer reconnects a previously disabled server
+func (m *Manager) EnableServer(name string) error {
+	m.mu.Lock()
+	serverConfig, disabled := m.disabled[name]
+	_, connected := m.clients[name]
+	m.mu.Unlock()
+
+	if connected {
+		return nil
+	}
+	if !disabled {
+		return fmt.Errorf("server %s not found", name)
+	}
+
+	return m.AddServer(name, serverConfig)
+}
+
+// ReloadServer restarts a server with a new configuration, keeping it disabled if it was disabled
+func (m *Manager) ReloadServer(name string, serverConfig config.MCPServer) error {
+	if m.isDisabled(name) {
+		m.mu.Lock()
+		m.disabled[name] = serverConfig
+		m.mu.Unlock()
+		return nil
+	}
+
+	if _, err := m.GetClient(name); err == nil {
+		if err := m.RemoveServer(name); err != nil {
+			m.logger.WithError(err).Warnf("Error while closing server %s for reload", name)
+		}
+	}
+
+	return m.AddServer(name, serverConfig)
+}
+
+// Servers returns the status of every connected and disabled server, sorted by name
+func (m *Manager) Servers() []ServerStatus {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	statuses := make([]ServerStatus, 0, len(m.clients)+len(m.disabled))
+	for name, client := range m.clients {
+		statuses = append(statuses, ServerStatus{
+			Name:      name,
+			Transport: client.GetConfig().Transport.Type,
+			State:     client.State(),
+			Enabled:   true,
+		})
+	}
+	for name, serverConfig := range m.disabled {
+		statuses = append(statuses, ServerStatus{
+			Name:      name,
+			Transport: serverConfig.Transport.Type,
+			State:     StateDisabled,
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+	return statuses
+}
+
+// isDisabled reports whether a server is registered but disabled
+func (m *Manager) isDisabled(name string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, disabled := m.disabled[name]
+	return disabled
+}
+
+// checkOpen returns an error if the manager has been closed
+func (m *Manager) checkOpen() error {
+	if m.isClosing() {
+		return fmt.Errorf("manager is closed")
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+func TestManagerAddRemoveServer(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	require.NoError(t, manager.AddServer("fake", fakeServerConfig(t, nil)))
+	assert.Error(t, manager.AddServer("fake", fakeServerConfig(t, nil)))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	require.NoError(t, client.Ping(context.Background()))
+
+	require.NoError(t, manager.RemoveServer("fake"))
+	_, err = manager.GetClient("fake")
+	assert.Error(t, err)
+	assert.Empty(t, manager.Servers())
+
+	assert.Error(t, manager.RemoveServer("fake"))
+}
+
+func TestManagerDisableEnableServer(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	require.NoError(t, manager.AddServer("fake", fakeServerConfig(t, nil)))
+	require.NoError(t, manager.DisableServer("fake"))
+
+	_, err := manager.GetClient("fake")
+	assert.Error(t, err)
+	assert.Empty(t, manager.GetAllClients())
+
+	servers := manager.Servers()
+	require.Len(t, servers, 1)
+	assert.Equal(t, "fake", servers[0].Name)
+	assert.Equal(t, StateDisabled, servers[0].State)
+	assert.False(t, servers[0].Enabled)
+
+	require.NoError(t, manager.EnableServer("fake"))
+	servers = manager.Servers()
+	require.Len(t, servers, 1)
+	assert.Equal(t, StateConnected, servers[0].State)
+	assert.True(t, servers[0].Enabled)
+
+	assert.Error(t, manager.EnableServer("ghost"))
+	assert.Error(t, manager.DisableServer("ghost"))
+}
+
+func TestManagerReloadServer(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	require.NoError(t, manager.AddServer("fake", fakeServerConfig(t, nil)))
+	original, err := manager.GetClient("fake")
+	require.NoError(t, err)
+
+	updated := fakeServerConfig(t, nil)
+	updated.StartupTimeout = "10s"
+	require.NoError(t, manager.ReloadServer("fake", updated))
+
+	reloaded, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	assert.NotSame(t, original, reloaded)
+	assert.Equal(t, "10s", reloaded.GetConfig().StartupTimeout)
+	assert.True(t, original.isClosed())
+}
+
+func TestManagerConcurrentAccess(t *testing.T) {
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+
+	require.NoError(t, manager.AddServer("fake", fakeServerConfig(t, nil)))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				for _, client := range manager.GetAllClients() {
+					_ = client.State()
+				}
+				_ = manager.Servers()
+			}
+		}()
+	}
+
+	require.NoError(t, manager.DisableServer("fake"))
+	require.NoError(t, manager.EnableServer("fake"))
+	wg.Wait()
+
+	assert.Len(t, manager.GetAllClients(), 1)
+}
+
+func TestManagerAddServerAfterClose(t *testing.T) {
+	manager := NewManager(logrus.New())
+	require.NoError(t, manager.Close())
+
+	err := manager.AddServer("fake", fakeServerConfig(t, nil))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "closed")
+}
+
+func TestManagerRetryFailedServer(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "crashed")
+	serverConfig := fakeServerConfig(t, map[string]string{fakeCrashMarkerEnv: marker})
+	serverConfig.Reconnect = &config.MCPReconnect{MaxRetries: 1, InitialBackoff: "10ms"}
+
+	manager := NewManager(logrus.New())
+	defer manager.Close()
+	changes := collectStates(manager)
+
+	require.NoError(t, manager.AddServer("fake", serverConfig))
+	err := manager.RetryServer("fake")
+	assert.EqualError(t, err, "server fake is connected")
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	_, err = client.CallTool(context.Background(), "crash", map[string]interface{}{})
+	assert.Error(t, err)
+	waitForState(t, changes, StateFailed)
+
+	err = manager.AddServer("fake", serverConfig)
+	assert.EqualError(t, err, "server fake is already added and failed")
+
+	// The server starts again once the crash marker is gone
+	require.NoError(t, os.Remove(marker))
+	require.NoError(t, manager.RetryServer("fake"))
+	assert.Equal(t, StateConnected, client.State())
+	_, err = client.CallTool(context.Background(), "echo", map[string]interface{}{"message": "back"})
+	require.NoError(t, err)
+}
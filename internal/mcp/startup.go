@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+)
+
+// StartupResult records the outcome of initializing a single server
+type StartupResult struct {
+	Server    string
+	Connected bool
+	Duration  time.Duration
+	Err       error
+}
+
+// SetStartupWorkers sets how many servers InitializeServers connects concurrently
+func (m *Manager) SetStartupWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	m.startupWorkers = workers
+}
+
+// StartupReport returns the per-server results of the last InitializeServers call
+func (m *Manager) StartupReport() []StartupResult {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]StartupResult(nil), m.startupReport...)
+}
+
+// WriteStartupSummary writes the startup results as a table
+func WriteStartupSummary(w io.Writer, results []StartupResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVER\tSTATUS\tTIME\tERROR")
+	for _, result := range results {
+		status := "connected"
+		errText := ""
+		if !result.Connected {
+			status = "failed"
+			if result.Err != nil {
+				errText = result.Err.Error()
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Server, status, result.Duration.Round(time.Millisecond), errText)
+	}
+	return tw.Flush()
+}
+
+// startupTimeout returns the configured startup timeout for a server
+func startupTimeout(serverConfig config.MCPServer) (time.Duration, error) {
+	if serverConfig.StartupTimeout == "" {
+		return constants.DefaultStartupTimeout, nil
+	}
+
+	timeout, err := time.ParseDuration(serverConfig.StartupTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid startup_timeout %q: %w", serverConfig.StartupTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("startup_timeout must be positive, got %s", timeout)
+	}
+	return timeout, nil
+}
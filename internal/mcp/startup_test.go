@@ -0,0 +1,100 @@
+package mcp
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/constants"
+)
+
+func TestStartupTimeout(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{name: "default", value: "", expected: constants.DefaultStartupTimeout},
+		{name: "custom", value: "5s", expected: 5 * time.Second},
+		{name: "invalid", value: "fast", expectError: true},
+		{name: "negative", value: "-1s", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeout, err := startupTimeout(config.MCPServer{StartupTimeout: tt.value})
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expected, timeout)
+			}
+		})
+	}
+}
+
+func TestWriteStartupSummary(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteStartupSummary(&buf, []StartupResult{
+		{Server: "alpha", Connected: true, Duration: 120 * time.Millisecond},
+		{Server: "beta", Duration: 2 * time.Second, Err: errors.New("connection refused")},
+	})
+	require.NoError(t, err)
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	require.Len(t, lines, 3)
+	assert.Contains(t, string(lines[0]), "SERVER")
+	assert.Contains(t, string(lines[1]), "alpha")
+	assert.Contains(t, string(lines[1]), "connected")
+	assert.Contains(t, string(lines[2]), "failed")
+	assert.Contains(t, string(lines[2]), "connection refused")
+}
+
+func TestInitializeServersReportsEachServer(t *testing.T) {
+	slow := fakeServerConfig(t, nil)
+	slow.StartupTimeout = "1ns"
+
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"one":     fakeServerConfig(t, nil),
+		"two":     fakeServerConfig(t, nil),
+		"slow":    slow,
+		"missing": {Command: []string{"/nonexistent/mcp-server"}, Transport: config.MCPTransport{Type: "stdio"}},
+	}}
+
+	manager := NewManager(logrus.New())
+	manager.SetStartupWorkers(2)
+	defer manager.Close()
+
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"one", "missing", "two", "slow"}))
+
+	report := manager.StartupReport()
+	require.Len(t, report, 4)
+
+	assert.Equal(t, "one", report[0].Server)
+	assert.True(t, report[0].Connected)
+	assert.Equal(t, "missing", report[1].Server)
+	assert.False(t, report[1].Connected)
+	assert.Error(t, report[1].Err)
+	assert.Equal(t, "two", report[2].Server)
+	assert.True(t, report[2].Connected)
+	assert.Equal(t, "slow", report[3].Server)
+	assert.False(t, report[3].Connected)
+	assert.Contains(t, report[3].Err.Error(), "timed out")
+
+	assert.Len(t, manager.GetAllClients(), 2)
+}
+
+func TestInitializeServersUnknownServer(t *testing.T) {
+	manager := NewManager(logrus.New())
+	err := manager.InitializeServers(&config.MCPConfig{Servers: map[string]config.MCPServer{}}, []string{"ghost"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+	assert.Empty(t, manager.StartupReport())
+}
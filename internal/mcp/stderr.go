@@ -0,0 +1,72 @@
+package mcp
+
+import (
+	"io"
+	"sync"
+)
+
+// StderrTail keeps the last bytes a server wrote to stderr
+type StderrTail struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+// NewStderrTail creates a buffer that keeps the last limit bytes written to it
+func NewStderrTail(limit int) *StderrTail {
+	return &StderrTail{limit: limit}
+}
+
+// Write appends to the buffer, dropping the oldest bytes beyond the limit
+func (t *StderrTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.data = append(t.data, p...)
+	if excess := len(t.data) - t.limit; excess > 0 {
+		t.data = append(t.data[:0], t.data[excess:]...)
+	}
+	return len(p), nil
+}
+
+// String returns the buffered output
+func (t *StderrTail) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.data)
+}
+
+// CaptureStderr keeps the last limit bytes every stdio server started afterwards writes to
+// stderr, so failures can be reported with the server's own output. Output is kept across
+// reconnects.
+func (m *Manager) CaptureStderr(limit int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stderrLimit = limit
+}
+
+// ServerStderr returns the captured stderr of a server, or "" if nothing was captured
+func (m *Manager) ServerStderr(name string) string {
+	m.mu.Lock()
+	tail := m.stderr[name]
+	m.mu.Unlock()
+	if tail == nil {
+		return ""
+	}
+	return tail.String()
+}
+
+// stderrWriter returns where a server's stderr should go, or nil when it is not captured
+func (m *Manager) stderrWriter(name string) io.Writer {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.stderrLimit <= 0 {
+		return nil
+	}
+	tail, ok := m.stderr[name]
+	if !ok {
+		tail = NewStderrTail(m.stderrLimit)
+		m.stderr[name] = tail
+	}
+	return tail
+}
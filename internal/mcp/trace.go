@@ -0,0 +1,225 @@
+package mcp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// pendingTimeout is how long a request waits for its response before the tracer forgets it, so
+// requests that are never answered do not pile up
+const pendingTimeout = 10 * time.Minute
+
+// TraceOptions controls how much of each message a trace shows
+type TraceOptions struct {
+	// MaxPayload truncates payloads longer than this many bytes; zero keeps them whole
+	MaxPayload int
+	// Compact prints payloads on a single line instead of indented
+	Compact bool
+}
+
+// Tracer writes a readable log of every JSON-RPC message exchanged with servers: the direction,
+// method, ID, time from request to response and the payload. It is safe for concurrent use.
+type Tracer struct {
+	mu      sync.Mutex
+	w       io.Writer
+	closer  io.Closer
+	opts    TraceOptions
+	pending map[string]pendingRequest
+	// evicted is when requests that timed out were last forgotten
+	evicted time.Time
+	now     func() time.Time
+}
+
+// pendingRequest is a request waiting for its response
+type pendingRequest struct {
+	method string
+	start  time.Time
+}
+
+// NewTracer creates a tracer writing to w
+func NewTracer(w io.Writer, opts TraceOptions) *Tracer {
+	return &Tracer{w: w, opts: opts, pending: make(map[string]pendingRequest), now: time.Now}
+}
+
+// CreateTracer creates a tracer writing to a new file at path
+func CreateTracer(path string, opts TraceOptions) (*Tracer, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace file: %w", err)
+	}
+	t := NewTracer(file, opts)
+	t.closer = file
+	return t, nil
+}
+
+// Close closes the trace file, if the tracer created one
+func (t *Tracer) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closer == nil {
+		return nil
+	}
+	err := t.closer.Close()
+	t.closer = nil
+	return err
+}
+
+// traceMessage is the wire form of a message, decoded for tracing
+type traceMessage struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"error"`
+}
+
+// Trace writes one message. Requests sent by the client are answered by messages received
+// from the server and the other way round, which is how responses are matched to requests.
+func (t *Tracer) Trace(server, direction string, msg mcp.JSONRPCMessage) {
+	data, err := EncodeMessage(msg)
+	t.trace(server, direction, data, err)
+}
+
+// TraceRaw writes one message in the wire form it was sent or received in on a raw connection
+func (t *Tracer) TraceRaw(server, direction string, data []byte) {
+	t.trace(server, direction, data, nil)
+}
+
+// trace writes one message in its wire form, or the error that kept it from being encoded
+func (t *Tracer) trace(server, direction string, data []byte, err error) {
+	var wire traceMessage
+	if err == nil {
+		err = json.Unmarshal(data, &wire)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	arrow := "→"
+	if direction == DirectionReceive {
+		arrow = "←"
+	}
+	header := fmt.Sprintf("%s [%s] %s ", now.Format("15:04:05.000"), server, arrow)
+
+	if err != nil {
+		fmt.Fprintf(t.w, "%s%v\n", header, err)
+		return
+	}
+
+	var payload json.RawMessage
+	switch {
+	case wire.Method != "" && wire.ID != nil:
+		t.evictPending(now)
+		t.pending[pendingKey(server, direction, wire.ID)] = pendingRequest{method: wire.Method, start: now}
+		header += fmt.Sprintf("%s #%v", wire.Method, wire.ID)
+		payload = wire.Params
+	case wire.Method != "":
+		header += wire.Method
+		payload = wire.Params
+	default:
+		// A response travels the opposite way to its request
+		requestDirection := DirectionSend
+		if direction == DirectionSend {
+			requestDirection = DirectionReceive
+		}
+		key := pendingKey(server, requestDirection, wire.ID)
+		request, ok := t.pending[key]
+		delete(t.pending, key)
+
+		method := "response"
+		if ok {
+			method = request.method
+		}
+		header += fmt.Sprintf("%s #%v", method, wire.ID)
+		if wire.Error != nil {
+			header += fmt.Sprintf(" error %d: %s", wire.Error.Code, wire.Error.Message)
+			payload = wire.Error.Data
+		} else {
+			payload = wire.Result
+		}
+		if ok {
+			header += fmt.Sprintf(" (%s)", formatLatency(now.Sub(request.start)))
+		}
+	}
+
+	fmt.Fprintln(t.w, header)
+	if body := t.payload(payload); body != "" {
+		fmt.Fprintln(t.w, body)
+	}
+}
+
+// payload formats a payload, indented under its header and truncated to the configured size.
+// Empty payloads are left out.
+func (t *Tracer) payload(data json.RawMessage) string {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "", "null", "{}":
+		return ""
+	}
+
+	var buf bytes.Buffer
+	if t.opts.Compact {
+		if err := json.Compact(&buf, data); err != nil {
+			buf.Write(data)
+		}
+	} else if err := json.Indent(&buf, data, "", "  "); err != nil {
+		buf.Write(data)
+	}
+
+	text := buf.String()
+	if t.opts.MaxPayload > 0 && len(text) > t.opts.MaxPayload {
+		cut := t.opts.MaxPayload
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = fmt.Sprintf("%s… (%d more bytes)", text[:cut], len(text)-cut)
+	}
+	return "    " + strings.ReplaceAll(text, "\n", "\n    ")
+}
+
+// evictPending forgets the requests that have waited longer than pendingTimeout. Their
+// responses, if they ever come, are traced without the method and latency. The caller holds mu.
+func (t *Tracer) evictPending(now time.Time) {
+	if now.Sub(t.evicted) < pendingTimeout {
+		return
+	}
+	for key, request := range t.pending {
+		if now.Sub(request.start) >= pendingTimeout {
+			delete(t.pending, key)
+		}
+	}
+	t.evicted = now
+}
+
+// pendingKey identifies a request by server, direction and ID
+func pendingKey(server, direction string, id interface{}) string {
+	return fmt.Sprintf("%s|%s|%v", server, direction, id)
+}
+
+// formatLatency rounds a latency for display
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(100 * time.Microsecond).String()
+}
+
+// SetTracer traces the traffic of every server connected afterwards, including reconnects
+func (m *Manager) SetTracer(tracer *Tracer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tracer = tracer
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+)
+
+// fixedTracer returns a tracer whose clock advances by step on every message
+func fixedTracer(out *strings.Builder, opts TraceOptions, step time.Duration) *Tracer {
+	tracer := NewTracer(out, opts)
+	now := time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC)
+	tracer.now = func() time.Time {
+		now = now.Add(step)
+		return now
+	}
+	return tracer
+}
+
+func TestTracerFormatsMessages(t *testing.T) {
+	var out strings.Builder
+	tracer := fixedTracer(&out, TraceOptions{}, 2500*time.Microsecond)
+
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo"}}`), nil)
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`), nil)
+	tracer.trace("fake", DirectionReceive, []byte(`{"jsonrpc":"2.0","id":1,"result":{"content":[]}}`), nil)
+	tracer.trace("fake", DirectionReceive, []byte(`{"jsonrpc":"2.0","id":"s1","method":"ping"}`), nil)
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","id":"s1","result":{}}`), nil)
+	tracer.trace("fake", DirectionReceive, []byte(`{"jsonrpc":"2.0","id":7,"error":{"code":-32602,"message":"unknown tool","data":"ghost"}}`), nil)
+
+	assert.Equal(t, `15:04:05.002 [fake] → tools/call #1
+    {
+      "name": "echo"
+    }
+15:04:05.005 [fake] → notifications/initialized
+15:04:05.007 [fake] ← tools/call #1 (5ms)
+    {
+      "content": []
+    }
+15:04:05.010 [fake] ← ping #s1
+15:04:05.012 [fake] → ping #s1 (2.5ms)
+15:04:05.015 [fake] ← response #7 error -32602: unknown tool
+    "ghost"
+`, out.String())
+}
+
+func TestTracerTruncatesPayloads(t *testing.T) {
+	var out strings.Builder
+	tracer := fixedTracer(&out, TraceOptions{MaxPayload: 11, Compact: true}, 0)
+
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","method":"log","params":{"text": "héllo wörld, a long message"}}`), nil)
+
+	assert.Equal(t, "15:04:05.000 [fake] → log\n    {\"text\":\"h… (30 more bytes)\n", out.String())
+}
+
+func TestManagerTracesTraffic(t *testing.T) {
+	var out strings.Builder
+	manager := NewManager(logrus.New())
+	manager.SetTracer(NewTracer(&out, TraceOptions{Compact: true}))
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{"fake": fakeServerConfig(t, nil)}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, []string{"fake"}))
+
+	client, err := manager.GetClient("fake")
+	require.NoError(t, err)
+	_, err = client.CallTool(context.Background(), "echo", map[string]interface{}{"message": "hi"})
+	require.NoError(t, err)
+	require.NoError(t, manager.Close())
+
+	trace := out.String()
+	assert.Regexp(t, `\[fake\] → initialize #1\n    \{.*"protocolVersion"`, trace)
+	assert.Regexp(t, `\[fake\] ← initialize #1 \(\S+\)\n`, trace)
+	assert.Contains(t, trace, "[fake] → notifications/initialized")
+	assert.Regexp(t, `\[fake\] ← tools/call #\d+ \(\S+\)\n    \{"content":\[\{"type":"text","text":"hi"\}\]\}`, trace)
+}
+
+func TestTracerTracesSDKMessages(t *testing.T) {
+	var out strings.Builder
+	tracer := fixedTracer(&out, TraceOptions{}, 0)
+	tracer.Trace("fake", DirectionSend, &mcp.JSONRPCRequest{Method: "notifications/cancelled"})
+	assert.Equal(t, "15:04:05.000 [fake] → notifications/cancelled\n", out.String())
+}
+
+func TestTracerForgetsUnansweredRequests(t *testing.T) {
+	var out strings.Builder
+	tracer := fixedTracer(&out, TraceOptions{}, pendingTimeout)
+
+	// The first request has waited too long by the time the second is sent
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","id":1,"method":"tools/call"}`), nil)
+	tracer.trace("fake", DirectionSend, []byte(`{"jsonrpc":"2.0","id":2,"method":"ping"}`), nil)
+	assert.Len(t, tracer.pending, 1)
+
+	tracer.trace("fake", DirectionReceive, []byte(`{"jsonrpc":"2.0","id":1,"result":{}}`), nil)
+	tracer.trace("fake", DirectionReceive, []byte(`{"jsonrpc":"2.0","id":2,"result":{}}`), nil)
+	assert.Contains(t, out.String(), "← response #1\n")
+	assert.Contains(t, out.String(), "← ping #2 (20m0s)\n")
+	assert.Empty(t, tracer.pending)
+}
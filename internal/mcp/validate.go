@@ -0,0 +1,36 @@
+package mcp
+
+import (
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/schema"
+)
+
+// ValidateToolArguments checks arguments against a tool's input schema before it is called
+func ValidateToolArguments(tool *mcp.Tool, arguments map[string]interface{}) error {
+	if arguments == nil {
+		// A call without arguments is sent as an empty object
+		arguments = map[string]interface{}{}
+	}
+	if err := schema.Validate(tool.InputSchema, arguments); err != nil {
+		return fmt.Errorf("invalid arguments for tool %s: %w", tool.Name, err)
+	}
+	return nil
+}
+
+// ValidateToolOutput checks a tool result against the tool's output schema. Tools that declare
+// an output schema must return matching structured content unless the call failed.
+func ValidateToolOutput(tool *mcp.Tool, result *mcp.CallToolResult) error {
+	if tool.OutputSchema == nil || result.IsError {
+		return nil
+	}
+	if result.StructuredContent == nil {
+		return fmt.Errorf("tool %s declares an output schema but returned no structured content", tool.Name)
+	}
+	if err := schema.Validate(tool.OutputSchema, result.StructuredContent); err != nil {
+		return fmt.Errorf("structured content of tool %s does not match its output schema: %w", tool.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package mcp
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateToolArguments(t *testing.T) {
+	tool := &mcp.Tool{
+		Name: "get_weather",
+		InputSchema: &jsonschema.Schema{
+			Type:     "object",
+			Required: []string{"location"},
+			Properties: map[string]*jsonschema.Schema{
+				"location": {Type: "string"},
+			},
+		},
+	}
+
+	assert.NoError(t, ValidateToolArguments(tool, map[string]interface{}{"location": "Oslo"}))
+	assert.EqualError(t, ValidateToolArguments(tool, nil), "invalid arguments for tool get_weather: $: required: missing properties: [\"location\"]")
+	assert.NoError(t, ValidateToolArguments(&mcp.Tool{Name: "free"}, nil))
+}
+
+func TestValidateToolOutput(t *testing.T) {
+	tool := &mcp.Tool{
+		Name: "get_weather",
+		OutputSchema: &jsonschema.Schema{
+			Type:     "object",
+			Required: []string{"temperature"},
+			Properties: map[string]*jsonschema.Schema{
+				"temperature": {Type: "number"},
+			},
+		},
+	}
+
+	assert.NoError(t, ValidateToolOutput(tool, &mcp.CallToolResult{StructuredContent: map[string]interface{}{"temperature": 21.5}}))
+	assert.EqualError(t, ValidateToolOutput(tool, &mcp.CallToolResult{StructuredContent: map[string]interface{}{"temperature": "warm"}}),
+		"structured content of tool get_weather does not match its output schema: $.temperature: type: warm has type \"string\", want \"number\"")
+	assert.EqualError(t, ValidateToolOutput(tool, &mcp.CallToolResult{}), "tool get_weather declares an output schema but returned no structured content")
+	assert.NoError(t, ValidateToolOutput(tool, &mcp.CallToolResult{IsError: true}))
+	assert.NoError(t, ValidateToolOutput(&mcp.Tool{Name: "plain"}, &mcp.CallToolResult{}))
+}
@@ -0,0 +1,104 @@
+// Package mcptest provides the recordings, mock servers, clients and configurations shared by tests
+package mcptest
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/config"
+	"mcp_tstr/internal/mcp"
+	"mcp_tstr/internal/mockserver"
+)
+
+// Recording parses "server direction message" lines
+func Recording(t *testing.T, lines ...string) []mcp.RecordedMessage {
+	t.Helper()
+	var messages []mcp.RecordedMessage
+	for _, line := range lines {
+		parts := strings.SplitN(line, " ", 3)
+		require.Len(t, parts, 3)
+		messages = append(messages, mcp.RecordedMessage{Server: parts[0], Direction: parts[1], Message: json.RawMessage(parts[2])})
+	}
+	return messages
+}
+
+// ServerConfig serves a handler over HTTP and returns the configuration to reach it
+func ServerConfig(t *testing.T, handler http.Handler) config.MCPServer {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	host, port, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	portNumber, err := strconv.Atoi(port)
+	require.NoError(t, err)
+	return config.MCPServer{Transport: config.MCPTransport{Type: "http", Host: host, Port: portNumber}}
+}
+
+// MockServerConfig serves a fixture over streamable HTTP and returns the configuration to reach it
+func MockServerConfig(t *testing.T, fixture *mockserver.Fixture, opts mockserver.Options) config.MCPServer {
+	t.Helper()
+	return ServerConfig(t, mockserver.New(fixture, opts).HTTPHandler())
+}
+
+// SearchFixture offers a search tool answering with its query, a greeting prompt and a resource,
+// plus any extra tools
+func SearchFixture(name string, tools ...mockserver.Tool) *mockserver.Fixture {
+	return &mockserver.Fixture{
+		Name: name,
+		Tools: append([]mockserver.Tool{{
+			Name:        "search",
+			Description: "Search " + name,
+			Responses: []mockserver.Response{
+				{Text: name + " found {{query}}"},
+				{Match: map[string]interface{}{"query": "secret"}, Text: "forbidden", IsError: true},
+			},
+		}}, tools...),
+		Resources: []mockserver.Resource{{URI: "mem://" + name, Name: name, Text: "about " + name}},
+		Prompts: []mockserver.Prompt{{
+			Name:      "greet",
+			Arguments: []mockserver.PromptArgument{{Name: "who", Required: true}},
+			Messages:  []mockserver.PromptMessage{{Role: "user", Text: name + " says hello to {{who}}"}},
+		}},
+	}
+}
+
+// MockClient connects to a mock server offering an echo tool and a failing tool
+func MockClient(t *testing.T) *mcp.Client {
+	t.Helper()
+	fixture := &mockserver.Fixture{Tools: []mockserver.Tool{
+		{Name: "echo", Responses: []mockserver.Response{{Text: "{{text}}"}}},
+		{Name: "broken", Responses: []mockserver.Response{{Text: "out of order", IsError: true}}},
+	}}
+
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	manager := mcp.NewManager(logger)
+	t.Cleanup(func() { manager.Close() })
+	mcpConfig := &config.MCPConfig{Servers: map[string]config.MCPServer{
+		"mock": MockServerConfig(t, fixture, mockserver.Options{}),
+	}}
+	require.NoError(t, manager.InitializeServers(mcpConfig, nil))
+
+	client, err := manager.GetClient("mock")
+	require.NoError(t, err)
+	return client
+}
+
+// ParseSchema unmarshals a schema written as JSON
+func ParseSchema(t *testing.T, data string) *jsonschema.Schema {
+	t.Helper()
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(data), &s))
+	return &s
+}
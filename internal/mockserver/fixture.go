@@ -0,0 +1,193 @@
+// Package mockserver serves canned MCP responses from a fixture, so clients can be developed
+// and tested against servers that are not available locally
+package mockserver
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixture describes what a mock server offers and how it answers
+type Fixture struct {
+	Name         string `yaml:"name"`
+	Version      string `yaml:"version"`
+	Instructions string `yaml:"instructions"`
+	// Latency delays every response except the initialize handshake
+	Latency time.Duration `yaml:"latency"`
+	// ErrorRate is the share of requests, between 0 and 1, answered with an internal error
+	ErrorRate float64    `yaml:"error_rate"`
+	Tools     []Tool     `yaml:"tools"`
+	Resources []Resource `yaml:"resources"`
+	Prompts   []Prompt   `yaml:"prompts"`
+}
+
+// Tool is a tool the mock server lists, with the responses it gives to calls
+type Tool struct {
+	Name         string                 `yaml:"name" json:"name"`
+	Title        string                 `yaml:"title" json:"title,omitempty"`
+	Description  string                 `yaml:"description" json:"description,omitempty"`
+	InputSchema  map[string]interface{} `yaml:"input_schema" json:"inputSchema"`
+	OutputSchema map[string]interface{} `yaml:"output_schema" json:"outputSchema,omitempty"`
+	Annotations  map[string]interface{} `yaml:"annotations" json:"annotations,omitempty"`
+	Responses    []Response             `yaml:"responses" json:"-"`
+}
+
+// Response is a canned answer to a tool call or prompt request. The most specific response whose
+// Match is contained in the request's arguments is used; earlier responses win ties.
+type Response struct {
+	// Match lists argument values the request must have; an empty Match matches any request
+	Match map[string]interface{} `yaml:"match"`
+	// Text is returned as a single text content block, with {{name}} replaced by argument values
+	Text string `yaml:"text"`
+	// Structured is returned as structured content, and as text when Text is empty
+	Structured interface{} `yaml:"structured"`
+	IsError    bool        `yaml:"is_error"`
+	// Result is returned as the complete result
+	Result map[string]interface{} `yaml:"result"`
+	// Error is returned as a JSON-RPC error
+	Error *Error `yaml:"error"`
+	// Latency delays this response on top of the server's latency
+	Latency time.Duration `yaml:"latency"`
+}
+
+// Error is a JSON-RPC error a response returns
+type Error struct {
+	Code    int         `yaml:"code"`
+	Message string      `yaml:"message"`
+	Data    interface{} `yaml:"data"`
+}
+
+// Resource is a resource the mock server lists and serves. Blob is base64 encoded.
+type Resource struct {
+	URI         string `yaml:"uri" json:"uri"`
+	Name        string `yaml:"name" json:"name"`
+	Title       string `yaml:"title" json:"title,omitempty"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	MIMEType    string `yaml:"mime_type" json:"mimeType,omitempty"`
+	Text        string `yaml:"text" json:"-"`
+	Blob        string `yaml:"blob" json:"-"`
+}
+
+// Prompt is a prompt the mock server lists. Its messages are returned with {{name}} replaced by
+// argument values, unless one of its responses matches the arguments.
+type Prompt struct {
+	Name        string           `yaml:"name" json:"name"`
+	Title       string           `yaml:"title" json:"title,omitempty"`
+	Description string           `yaml:"description" json:"description,omitempty"`
+	Arguments   []PromptArgument `yaml:"arguments" json:"arguments,omitempty"`
+	Messages    []PromptMessage  `yaml:"messages" json:"-"`
+	Responses   []Response       `yaml:"responses" json:"-"`
+}
+
+// PromptArgument is an argument a prompt accepts
+type PromptArgument struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description" json:"description,omitempty"`
+	Required    bool   `yaml:"required" json:"required,omitempty"`
+}
+
+// PromptMessage is a text message of a prompt
+type PromptMessage struct {
+	Role string `yaml:"role"`
+	Text string `yaml:"text"`
+}
+
+// Load reads a fixture from a YAML file
+func Load(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	fixture, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return fixture, nil
+}
+
+// Parse decodes a fixture from YAML and checks that it is well formed
+func Parse(data []byte) (*Fixture, error) {
+	var fixture Fixture
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&fixture); err != nil {
+		return nil, fmt.Errorf("failed to parse fixture: %w", err)
+	}
+	if err := fixture.validate(); err != nil {
+		return nil, err
+	}
+	return &fixture, nil
+}
+
+// validate checks names are present and unique and every response returns one kind of answer
+func (f *Fixture) validate() error {
+	if f.ErrorRate < 0 || f.ErrorRate > 1 {
+		return fmt.Errorf("error_rate must be between 0 and 1")
+	}
+
+	tools := make(map[string]bool)
+	for i, tool := range f.Tools {
+		if tool.Name == "" {
+			return fmt.Errorf("tool %d has no name", i+1)
+		}
+		if tools[tool.Name] {
+			return fmt.Errorf("duplicate tool %q", tool.Name)
+		}
+		tools[tool.Name] = true
+		for j, response := range tool.Responses {
+			if err := response.validate(); err != nil {
+				return fmt.Errorf("tool %q, response %d: %w", tool.Name, j+1, err)
+			}
+		}
+	}
+
+	resources := make(map[string]bool)
+	for i, resource := range f.Resources {
+		if resource.URI == "" {
+			return fmt.Errorf("resource %d has no uri", i+1)
+		}
+		if resources[resource.URI] {
+			return fmt.Errorf("duplicate resource %q", resource.URI)
+		}
+		resources[resource.URI] = true
+		if resource.Text != "" && resource.Blob != "" {
+			return fmt.Errorf("resource %q has both text and blob", resource.URI)
+		}
+	}
+
+	prompts := make(map[string]bool)
+	for i, prompt := range f.Prompts {
+		if prompt.Name == "" {
+			return fmt.Errorf("prompt %d has no name", i+1)
+		}
+		if prompts[prompt.Name] {
+			return fmt.Errorf("duplicate prompt %q", prompt.Name)
+		}
+		prompts[prompt.Name] = true
+		for j, response := range prompt.Responses {
+			if response.Result == nil && response.Error == nil {
+				return fmt.Errorf("prompt %q, response %d: prompt responses need a result or an error", prompt.Name, j+1)
+			}
+			if err := response.validate(); err != nil {
+				return fmt.Errorf("prompt %q, response %d: %w", prompt.Name, j+1, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that a response does not mix a complete result or error with other answers
+func (r Response) validate() error {
+	content := r.Text != "" || r.Structured != nil || r.IsError
+	switch {
+	case r.Error != nil && (content || r.Result != nil):
+		return fmt.Errorf("error cannot be combined with other answers")
+	case r.Result != nil && content:
+		return fmt.Errorf("result cannot be combined with text, structured or is_error")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package mockserver_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mockserver"
+)
+
+// These tests run in their own package so they can use mcptest, which imports the mock server.
+
+const weatherFixture = `
+name: weather
+version: 2.0.0
+latency: 5ms
+tools:
+  - name: forecast
+    description: Get the forecast
+    input_schema:
+      type: object
+      properties:
+        city: {type: string}
+      required: [city]
+    responses:
+      - text: "Sunny in {{city}}"
+      - match: {city: Bergen}
+        text: Rain
+        latency: 10ms
+      - match: {city: Nowhere}
+        error: {code: -32602, message: unknown city}
+      - match: {city: Oslo, days: 3}
+        structured: {temperature: 21}
+  - name: raw
+    responses:
+      - result: {content: [{type: text, text: raw}], isError: true}
+resources:
+  - uri: file:///notes.txt
+    name: notes
+    mime_type: text/plain
+    text: hello
+prompts:
+  - name: greet
+    description: Greet someone
+    arguments:
+      - {name: who, required: true}
+    messages:
+      - {role: user, text: "Say hello to {{who}}"}
+`
+
+func TestParseFixture(t *testing.T) {
+	fixture, err := mockserver.Parse([]byte(weatherFixture))
+	require.NoError(t, err)
+
+	assert.Equal(t, "weather", fixture.Name)
+	assert.Equal(t, 5*time.Millisecond, fixture.Latency)
+	require.Len(t, fixture.Tools, 2)
+	assert.Equal(t, 10*time.Millisecond, fixture.Tools[0].Responses[1].Latency)
+	assert.Equal(t, -32602, fixture.Tools[0].Responses[2].Error.Code)
+	assert.Equal(t, "text/plain", fixture.Resources[0].MIMEType)
+	assert.True(t, fixture.Prompts[0].Arguments[0].Required)
+}
+
+func TestParseFixtureErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		expected string
+	}{
+		{"unknown field", "tool: []", "failed to parse fixture"},
+		{"tool without name", "tools: [{description: x}]", "tool 1 has no name"},
+		{"duplicate tool", "tools: [{name: a}, {name: a}]", `duplicate tool "a"`},
+		{"mixed response", "tools: [{name: a, responses: [{text: x, error: {code: 1, message: y}}]}]", `tool "a", response 1: error cannot be combined with other answers`},
+		{"result and text", "tools: [{name: a, responses: [{text: x, result: {}}]}]", "result cannot be combined"},
+		{"resource without uri", "resources: [{name: a}]", "resource 1 has no uri"},
+		{"text and blob", "resources: [{uri: a, text: x, blob: eA==}]", "both text and blob"},
+		{"prompt response", "prompts: [{name: p, responses: [{text: x}]}]", "prompt responses need a result or an error"},
+		{"error rate", "error_rate: 2", "error_rate must be between 0 and 1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mockserver.Parse([]byte(tt.yaml))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestLoadFixture(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mock.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("tools: [{name: a}, {name: a}]"), 0o644))
+
+	_, err := mockserver.Load(path)
+	assert.EqualError(t, err, path+`: duplicate tool "a"`)
+}
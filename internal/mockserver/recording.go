@@ -0,0 +1,240 @@
+package mockserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcp"
+)
+
+// recordedMessage is a JSON-RPC message of a recording
+type recordedMessage struct {
+	Direction string
+	ID        json.RawMessage `json:"id"`
+	Method    string          `json:"method"`
+	Params    json.RawMessage `json:"params"`
+	Result    json.RawMessage `json:"result"`
+	Error     *jsonrpc.Error  `json:"error"`
+}
+
+// FromRecording builds a fixture that answers like a server did in a recording: it offers the
+// tools, resources and prompts the server listed and answers calls, reads and prompt requests
+// with the recorded responses. server selects the server when the recording has several.
+func FromRecording(messages []mcp.RecordedMessage, server string) (*Fixture, error) {
+	var decoded []recordedMessage
+	for i, msg := range messages {
+		if server == "" {
+			server = msg.Server
+		}
+		if msg.Server != server {
+			continue
+		}
+		r := recordedMessage{Direction: msg.Direction}
+		if err := json.Unmarshal(msg.Message, &r); err != nil {
+			return nil, fmt.Errorf("message %d: %w", i+1, err)
+		}
+		decoded = append(decoded, r)
+	}
+	if len(decoded) == 0 {
+		return nil, fmt.Errorf("server %s does not appear in the recording", server)
+	}
+
+	b := &fixtureBuilder{fixture: &Fixture{Name: server}, tools: make(map[string]int), resources: make(map[string]int), prompts: make(map[string]int)}
+	for i, msg := range decoded {
+		if msg.Direction != mcp.DirectionSend || msg.Method == "" || len(msg.ID) == 0 {
+			continue
+		}
+		reply := findReply(decoded[i+1:], msg.ID)
+		if reply == nil {
+			continue
+		}
+		if err := b.add(msg, reply); err != nil {
+			return nil, fmt.Errorf("recorded %s: %w", msg.Method, err)
+		}
+	}
+	return b.fixture, nil
+}
+
+// findReply returns the first recorded response to the request with the given ID
+func findReply(messages []recordedMessage, id json.RawMessage) *recordedMessage {
+	for i, msg := range messages {
+		if msg.Direction == mcp.DirectionReceive && msg.Method == "" && bytes.Equal(msg.ID, id) {
+			return &messages[i]
+		}
+	}
+	return nil
+}
+
+// fixtureBuilder collects recorded exchanges into a fixture
+type fixtureBuilder struct {
+	fixture *Fixture
+	// tools, resources and prompts index the fixture's entries by name or URI
+	tools     map[string]int
+	resources map[string]int
+	prompts   map[string]int
+}
+
+// add records one request and its response
+func (b *fixtureBuilder) add(msg recordedMessage, reply *recordedMessage) error {
+	var response Response
+	if reply.Error != nil {
+		response.Error = &Error{Code: reply.Error.Code, Message: reply.Error.Message}
+		if len(reply.Error.Data) > 0 {
+			if err := json.Unmarshal(reply.Error.Data, &response.Error.Data); err != nil {
+				return err
+			}
+		}
+	} else if err := json.Unmarshal(reply.Result, &response.Result); err != nil {
+		return err
+	}
+
+	switch msg.Method {
+	case "initialize":
+		var result struct {
+			ServerInfo struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"serverInfo"`
+			Instructions string `json:"instructions"`
+		}
+		if reply.Error == nil && json.Unmarshal(reply.Result, &result) == nil {
+			if result.ServerInfo.Name != "" {
+				b.fixture.Name = result.ServerInfo.Name
+			}
+			b.fixture.Version = result.ServerInfo.Version
+			b.fixture.Instructions = result.Instructions
+		}
+	case "tools/list":
+		var result struct {
+			Tools []Tool `json:"tools"`
+		}
+		if reply.Error == nil {
+			if err := json.Unmarshal(reply.Result, &result); err != nil {
+				return err
+			}
+			for _, tool := range result.Tools {
+				b.tool(tool, true)
+			}
+		}
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		response.Match = params.Arguments
+		tool := b.tool(Tool{Name: params.Name}, false)
+		tool.Responses = append(tool.Responses, response)
+	case "resources/list":
+		var result struct {
+			Resources []Resource `json:"resources"`
+		}
+		if reply.Error == nil {
+			if err := json.Unmarshal(reply.Result, &result); err != nil {
+				return err
+			}
+			for _, resource := range result.Resources {
+				b.resource(resource, true)
+			}
+		}
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		var result struct {
+			Contents []struct {
+				MIMEType string `json:"mimeType"`
+				Text     string `json:"text"`
+				Blob     string `json:"blob"`
+			} `json:"contents"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		if reply.Error == nil && json.Unmarshal(reply.Result, &result) == nil && len(result.Contents) > 0 {
+			resource := b.resource(Resource{URI: params.URI, Name: params.URI}, false)
+			contents := result.Contents[0]
+			resource.Text, resource.Blob = contents.Text, contents.Blob
+			if contents.MIMEType != "" {
+				resource.MIMEType = contents.MIMEType
+			}
+		}
+	case "prompts/list":
+		var result struct {
+			Prompts []Prompt `json:"prompts"`
+		}
+		if reply.Error == nil {
+			if err := json.Unmarshal(reply.Result, &result); err != nil {
+				return err
+			}
+			for _, prompt := range result.Prompts {
+				b.prompt(prompt, true)
+			}
+		}
+	case "prompts/get":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return err
+		}
+		response.Match = params.Arguments
+		prompt := b.prompt(Prompt{Name: params.Name}, false)
+		prompt.Responses = append(prompt.Responses, response)
+	}
+	return nil
+}
+
+// tool returns the fixture's tool of the same name, adding it if needed. A listed tool replaces
+// what is known about it except its responses; a tool only seen in calls has just its name.
+func (b *fixtureBuilder) tool(tool Tool, listed bool) *Tool {
+	i, ok := b.tools[tool.Name]
+	if !ok {
+		i = len(b.fixture.Tools)
+		b.tools[tool.Name] = i
+		b.fixture.Tools = append(b.fixture.Tools, tool)
+	} else if listed {
+		tool.Responses = b.fixture.Tools[i].Responses
+		b.fixture.Tools[i] = tool
+	}
+	return &b.fixture.Tools[i]
+}
+
+// resource returns the fixture's resource with the same URI, adding it if needed. A listed
+// resource replaces what is known about it except its contents.
+func (b *fixtureBuilder) resource(resource Resource, listed bool) *Resource {
+	i, ok := b.resources[resource.URI]
+	if !ok {
+		i = len(b.fixture.Resources)
+		b.resources[resource.URI] = i
+		b.fixture.Resources = append(b.fixture.Resources, resource)
+	} else if listed {
+		existing := b.fixture.Resources[i]
+		resource.Text, resource.Blob = existing.Text, existing.Blob
+		if resource.MIMEType == "" {
+			resource.MIMEType = existing.MIMEType
+		}
+		b.fixture.Resources[i] = resource
+	}
+	return &b.fixture.Resources[i]
+}
+
+// prompt returns the fixture's prompt of the same name, adding it if needed. A listed prompt
+// replaces what is known about it except its responses.
+func (b *fixtureBuilder) prompt(prompt Prompt, listed bool) *Prompt {
+	i, ok := b.prompts[prompt.Name]
+	if !ok {
+		i = len(b.fixture.Prompts)
+		b.prompts[prompt.Name] = i
+		b.fixture.Prompts = append(b.fixture.Prompts, prompt)
+	} else if listed {
+		prompt.Responses = b.fixture.Prompts[i].Responses
+		b.fixture.Prompts[i] = prompt
+	}
+	return &b.fixture.Prompts[i]
+}
@@ -0,0 +1,73 @@
+package mockserver_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/mockserver"
+)
+
+func TestFromRecording(t *testing.T) {
+	messages := mcptest.Recording(t,
+		`other send {"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		`weather send {"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`weather receive {"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","serverInfo":{"name":"weather-api","version":"3.1"},"instructions":"Ask about weather"}}`,
+		`weather send {"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"forecast","arguments":{"city":"Oslo"}}}`,
+		`weather send {"jsonrpc":"2.0","id":3,"method":"tools/list","params":{}}`,
+		`weather receive {"jsonrpc":"2.0","id":3,"result":{"tools":[{"name":"forecast","description":"Get the forecast","inputSchema":{"type":"object"}}]}}`,
+		`weather receive {"jsonrpc":"2.0","id":2,"result":{"content":[{"type":"text","text":"Sunny"}]}}`,
+		`weather send {"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"forecast","arguments":{"city":"Atlantis"}}}`,
+		`weather receive {"jsonrpc":"2.0","id":4,"error":{"code":-32602,"message":"unknown city","data":{"city":"Atlantis"}}}`,
+		`weather send {"jsonrpc":"2.0","id":5,"method":"resources/read","params":{"uri":"file:///a.txt"}}`,
+		`weather receive {"jsonrpc":"2.0","id":5,"result":{"contents":[{"uri":"file:///a.txt","mimeType":"text/plain","text":"A"}]}}`,
+		`weather send {"jsonrpc":"2.0","id":6,"method":"resources/list","params":{}}`,
+		`weather receive {"jsonrpc":"2.0","id":6,"result":{"resources":[{"uri":"file:///a.txt","name":"a"}]}}`,
+		`weather send {"jsonrpc":"2.0","id":7,"method":"prompts/get","params":{"name":"brief","arguments":{"city":"Oslo"}}}`,
+		`weather receive {"jsonrpc":"2.0","id":7,"result":{"messages":[{"role":"user","content":{"type":"text","text":"Brief for Oslo"}}]}}`,
+		`weather send {"jsonrpc":"2.0","id":8,"method":"tools/call","params":{"name":"forecast","arguments":{"city":"Bergen"}}}`,
+	)
+
+	fixture, err := mockserver.FromRecording(messages, "weather")
+	require.NoError(t, err)
+
+	assert.Equal(t, "weather-api", fixture.Name)
+	assert.Equal(t, "3.1", fixture.Version)
+	assert.Equal(t, "Ask about weather", fixture.Instructions)
+
+	require.Len(t, fixture.Tools, 1)
+	assert.Equal(t, "Get the forecast", fixture.Tools[0].Description)
+	assert.Len(t, fixture.Tools[0].Responses, 2, "the unanswered call is left out")
+
+	require.Len(t, fixture.Resources, 1)
+	assert.Equal(t, mockserver.Resource{URI: "file:///a.txt", Name: "a", MIMEType: "text/plain", Text: "A"}, fixture.Resources[0])
+	require.Len(t, fixture.Prompts, 1)
+
+	s := mockserver.New(fixture, mockserver.Options{})
+	result, _ := call(t, s, "tools/call", `{"name": "forecast", "arguments": {"city": "Oslo"}}`)
+	assert.JSONEq(t, `{"content": [{"type": "text", "text": "Sunny"}]}`, result)
+
+	_, rpcErr := call(t, s, "tools/call", `{"name": "forecast", "arguments": {"city": "Atlantis"}}`)
+	assert.Equal(t, &jsonrpc.Error{Code: -32602, Message: "unknown city", Data: json.RawMessage(`{"city":"Atlantis"}`)}, rpcErr)
+
+	result, _ = call(t, s, "prompts/get", `{"name": "brief", "arguments": {"city": "Oslo"}}`)
+	assert.Contains(t, result, "Brief for Oslo")
+
+	result, _ = call(t, s, "resources/read", `{"uri": "file:///a.txt"}`)
+	assert.Contains(t, result, `"text":"A"`)
+}
+
+func TestFromRecordingDefaultsToFirstServer(t *testing.T) {
+	messages := mcptest.Recording(t, `first send {"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	fixture, err := mockserver.FromRecording(messages, "")
+	require.NoError(t, err)
+	assert.Equal(t, "first", fixture.Name)
+
+	_, err = mockserver.FromRecording(messages, "ghost")
+	assert.EqualError(t, err, "server ghost does not appear in the recording")
+}
@@ -0,0 +1,415 @@
+package mockserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"mcp_tstr/internal/constants"
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/schema"
+)
+
+// Options injects latency and errors on top of the fixture's own settings
+type Options struct {
+	// Latency delays every response except the initialize handshake
+	Latency time.Duration
+	// Jitter adds a random delay of up to this much to every delayed response
+	Jitter time.Duration
+	// ErrorRate is the share of requests, between 0 and 1, answered with an internal error
+	ErrorRate float64
+	// Seed makes injected jitter and errors reproducible; zero seeds from the clock
+	Seed int64
+}
+
+// Server answers MCP requests from a fixture. It is safe for concurrent use.
+type Server struct {
+	fixture *Fixture
+	opts    Options
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a server for a fixture. Options that are zero fall back to the fixture's latency
+// and error rate.
+func New(fixture *Fixture, opts Options) *Server {
+	if opts.Latency == 0 {
+		opts.Latency = fixture.Latency
+	}
+	if opts.ErrorRate == 0 {
+		opts.ErrorRate = fixture.ErrorRate
+	}
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	return &Server{fixture: fixture, opts: opts, rand: rand.New(rand.NewSource(opts.Seed))}
+}
+
+// Handle answers a request
+func (s *Server) Handle(ctx context.Context, method string, params json.RawMessage) (interface{}, *jsonrpc.Error) {
+	if method != "initialize" {
+		delay, fail := s.inject()
+		if err := sleep(ctx, delay); err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+		}
+		if fail {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "injected error"}
+		}
+	}
+
+	switch method {
+	case "tools/list", "resources/list", "resources/templates/list", "prompts/list":
+		if rpcErr := checkCursor(params); rpcErr != nil {
+			return nil, rpcErr
+		}
+	}
+
+	switch method {
+	case "initialize":
+		return s.initialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools()}, nil
+	case "tools/call":
+		return s.callTool(ctx, params)
+	case "resources/list":
+		return map[string]interface{}{"resources": nonNil(s.fixture.Resources)}, nil
+	case "resources/templates/list":
+		return map[string]interface{}{"resourceTemplates": []interface{}{}}, nil
+	case "resources/read":
+		return s.readResource(params)
+	case "prompts/list":
+		return map[string]interface{}{"prompts": nonNil(s.fixture.Prompts)}, nil
+	case "prompts/get":
+		return s.getPrompt(ctx, params)
+	}
+	return nil, &jsonrpc.Error{Code: jsonrpc.CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", method)}
+}
+
+// inject draws the delay and whether the request fails
+func (s *Server) inject() (time.Duration, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delay := s.opts.Latency
+	if s.opts.Jitter > 0 {
+		delay += time.Duration(s.rand.Int63n(int64(s.opts.Jitter)))
+	}
+	return delay, s.opts.ErrorRate > 0 && s.rand.Float64() < s.opts.ErrorRate
+}
+
+// initialize negotiates the protocol version and declares the capabilities the fixture has
+func (s *Server) initialize(params json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var request struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, invalidParams("invalid initialize params: %v", err)
+	}
+	version := jsonrpc.SpecVersion
+	if slices.Contains(jsonrpc.KnownVersions, request.ProtocolVersion) {
+		version = request.ProtocolVersion
+	}
+
+	capabilities := make(map[string]interface{})
+	if len(s.fixture.Tools) > 0 {
+		capabilities["tools"] = struct{}{}
+	}
+	if len(s.fixture.Resources) > 0 {
+		capabilities["resources"] = struct{}{}
+	}
+	if len(s.fixture.Prompts) > 0 {
+		capabilities["prompts"] = struct{}{}
+	}
+
+	name, serverVersion := s.fixture.Name, s.fixture.Version
+	if name == "" {
+		name = "mock"
+	}
+	if serverVersion == "" {
+		serverVersion = constants.AppVersion
+	}
+
+	result := map[string]interface{}{
+		"protocolVersion": version,
+		"capabilities":    capabilities,
+		"serverInfo":      map[string]string{"name": name, "version": serverVersion},
+	}
+	if s.fixture.Instructions != "" {
+		result["instructions"] = s.fixture.Instructions
+	}
+	return result, nil
+}
+
+// tools lists the fixture's tools, giving tools without an input schema an empty object schema
+func (s *Server) tools() []Tool {
+	tools := make([]Tool, len(s.fixture.Tools))
+	for i, tool := range s.fixture.Tools {
+		if tool.InputSchema == nil {
+			tool.InputSchema = map[string]interface{}{"type": "object"}
+		}
+		tools[i] = tool
+	}
+	return tools
+}
+
+// callTool answers a tool call with the best matching response
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var request struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, invalidParams("invalid tools/call params: %v", err)
+	}
+
+	var tool *Tool
+	for i := range s.fixture.Tools {
+		if s.fixture.Tools[i].Name == request.Name {
+			tool = &s.fixture.Tools[i]
+		}
+	}
+	if tool == nil {
+		return nil, invalidParams("unknown tool %q", request.Name)
+	}
+
+	response := bestMatch(tool.Responses, request.Arguments)
+	if response == nil {
+		return textResult(fmt.Sprintf("no mock response for tool %s matches these arguments", tool.Name), true), nil
+	}
+	if err := sleep(ctx, response.Latency); err != nil {
+		return nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+	}
+
+	switch {
+	case response.Error != nil:
+		return nil, response.Error.rpcError()
+	case response.Result != nil:
+		result := make(map[string]interface{}, len(response.Result)+1)
+		for key, value := range response.Result {
+			result[key] = value
+		}
+		if _, ok := result["content"]; !ok {
+			result["content"] = []interface{}{}
+		}
+		return result, nil
+	}
+
+	text := expand(response.Text, request.Arguments)
+	if text == "" && response.Structured != nil {
+		data, err := json.Marshal(response.Structured)
+		if err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+		}
+		text = string(data)
+	}
+	result := textResult(text, response.IsError)
+	if response.Structured != nil {
+		result["structuredContent"] = response.Structured
+	}
+	return result, nil
+}
+
+// readResource returns the contents of a resource
+func (s *Server) readResource(params json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var request struct {
+		URI string `json:"uri"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, invalidParams("invalid resources/read params: %v", err)
+	}
+
+	for _, resource := range s.fixture.Resources {
+		if resource.URI != request.URI {
+			continue
+		}
+		contents := map[string]interface{}{"uri": resource.URI}
+		if resource.MIMEType != "" {
+			contents["mimeType"] = resource.MIMEType
+		}
+		if resource.Blob != "" {
+			contents["blob"] = resource.Blob
+		} else {
+			contents["text"] = resource.Text
+		}
+		return map[string]interface{}{"contents": []interface{}{contents}}, nil
+	}
+	return nil, &jsonrpc.Error{Code: jsonrpc.CodeResourceNotFound, Message: fmt.Sprintf("resource %q not found", request.URI)}
+}
+
+// getPrompt returns a prompt's messages, or the response matching its arguments
+func (s *Server) getPrompt(ctx context.Context, params json.RawMessage) (interface{}, *jsonrpc.Error) {
+	var request struct {
+		Name      string            `json:"name"`
+		Arguments map[string]string `json:"arguments"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return nil, invalidParams("invalid prompts/get params: %v", err)
+	}
+
+	var prompt *Prompt
+	for i := range s.fixture.Prompts {
+		if s.fixture.Prompts[i].Name == request.Name {
+			prompt = &s.fixture.Prompts[i]
+		}
+	}
+	if prompt == nil {
+		return nil, invalidParams("unknown prompt %q", request.Name)
+	}
+
+	arguments := make(map[string]interface{}, len(request.Arguments))
+	for name, value := range request.Arguments {
+		arguments[name] = value
+	}
+	if response := bestMatch(prompt.Responses, arguments); response != nil {
+		if err := sleep(ctx, response.Latency); err != nil {
+			return nil, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}
+		}
+		if response.Error != nil {
+			return nil, response.Error.rpcError()
+		}
+		return response.Result, nil
+	}
+
+	for _, argument := range prompt.Arguments {
+		if _, ok := request.Arguments[argument.Name]; argument.Required && !ok {
+			return nil, invalidParams("missing required argument %q", argument.Name)
+		}
+	}
+	messages := make([]interface{}, 0, len(prompt.Messages))
+	for _, message := range prompt.Messages {
+		messages = append(messages, map[string]interface{}{
+			"role":    message.Role,
+			"content": map[string]string{"type": "text", "text": expand(message.Text, arguments)},
+		})
+	}
+	result := map[string]interface{}{"messages": messages}
+	if prompt.Description != "" {
+		result["description"] = prompt.Description
+	}
+	return result, nil
+}
+
+// checkCursor rejects pagination cursors, since every list fits on one page
+func checkCursor(params json.RawMessage) *jsonrpc.Error {
+	if len(params) == 0 {
+		return nil
+	}
+	var request struct {
+		Cursor *string `json:"cursor"`
+	}
+	if err := json.Unmarshal(params, &request); err != nil {
+		return invalidParams("invalid params: %v", err)
+	}
+	if request.Cursor != nil {
+		return invalidParams("invalid cursor %q", *request.Cursor)
+	}
+	return nil
+}
+
+// rpcError converts a fixture error into a JSON-RPC error
+func (e *Error) rpcError() *jsonrpc.Error {
+	rpcErr := &jsonrpc.Error{Code: e.Code, Message: e.Message}
+	if e.Data != nil {
+		rpcErr.Data, _ = json.Marshal(e.Data)
+	}
+	return rpcErr
+}
+
+// bestMatch returns the response with the most match values that are all in arguments
+func bestMatch(responses []Response, arguments map[string]interface{}) *Response {
+	var best *Response
+	for i := range responses {
+		response := &responses[i]
+		if !matches(response.Match, arguments) {
+			continue
+		}
+		if best == nil || len(response.Match) > len(best.Match) {
+			best = response
+		}
+	}
+	return best
+}
+
+// matches reports whether every match value equals the argument of the same name
+func matches(match, arguments map[string]interface{}) bool {
+	for name, want := range match {
+		got, ok := arguments[name]
+		if !ok {
+			return false
+		}
+		// Both sides are normalized so YAML and JSON numbers compare equal
+		want, err := schema.Normalize(want)
+		if err != nil {
+			return false
+		}
+		got, err = schema.Normalize(got)
+		if err != nil || !reflect.DeepEqual(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// expand replaces {{name}} with the value of the argument called name
+func expand(text string, arguments map[string]interface{}) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	pairs := make([]string, 0, 2*len(arguments))
+	for name, value := range arguments {
+		rendered, ok := value.(string)
+		if !ok {
+			data, _ := json.Marshal(value)
+			rendered = string(data)
+		}
+		pairs = append(pairs, "{{"+name+"}}", rendered)
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// textResult builds a tool result with a single text content block
+func textResult(text string, isError bool) map[string]interface{} {
+	result := map[string]interface{}{
+		"content": []interface{}{map[string]string{"type": "text", "text": text}},
+	}
+	if isError {
+		result["isError"] = true
+	}
+	return result
+}
+
+// invalidParams builds an invalid params error
+func invalidParams(format string, args ...interface{}) *jsonrpc.Error {
+	return &jsonrpc.Error{Code: jsonrpc.CodeInvalidParams, Message: fmt.Sprintf(format, args...)}
+}
+
+// nonNil returns an empty list instead of nil so lists are encoded as []
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,226 @@
+package mockserver_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mockserver"
+)
+
+// weatherServer creates a server for the weather fixture without its latency
+func weatherServer(t *testing.T, opts mockserver.Options) *mockserver.Server {
+	t.Helper()
+	fixture, err := mockserver.Parse([]byte(weatherFixture))
+	require.NoError(t, err)
+	fixture.Latency = 0
+	return mockserver.New(fixture, opts)
+}
+
+// call handles a request and returns its result as JSON
+func call(t *testing.T, s *mockserver.Server, method, params string) (string, *jsonrpc.Error) {
+	t.Helper()
+	result, rpcErr := s.Handle(context.Background(), method, json.RawMessage(params))
+	if rpcErr != nil {
+		return "", rpcErr
+	}
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	return string(data), nil
+}
+
+func TestServerInitialize(t *testing.T) {
+	s := weatherServer(t, mockserver.Options{})
+
+	result, rpcErr := call(t, s, "initialize", `{"protocolVersion": "2025-03-26"}`)
+	require.Nil(t, rpcErr)
+	assert.JSONEq(t, `{
+		"protocolVersion": "2025-03-26",
+		"capabilities": {"tools": {}, "resources": {}, "prompts": {}},
+		"serverInfo": {"name": "weather", "version": "2.0.0"}
+	}`, result)
+
+	result, _ = call(t, s, "initialize", `{"protocolVersion": "1999-01-01"}`)
+	assert.Contains(t, result, jsonrpc.SpecVersion)
+}
+
+func TestServerToolCalls(t *testing.T) {
+	s := weatherServer(t, mockserver.Options{})
+
+	result, _ := call(t, s, "tools/list", `{}`)
+	assert.Contains(t, result, `"inputSchema":{"properties":{"city":{"type":"string"}},"required":["city"],"type":"object"}`)
+	assert.Contains(t, result, `{"name":"raw","inputSchema":{"type":"object"}}`)
+
+	tests := []struct {
+		name      string
+		arguments string
+		expected  string
+	}{
+		{"fallback", `{"city": "Paris"}`, `{"content": [{"type": "text", "text": "Sunny in Paris"}]}`},
+		{"match", `{"city": "Bergen"}`, `{"content": [{"type": "text", "text": "Rain"}]}`},
+		{"most specific", `{"city": "Oslo", "days": 3}`, `{"content": [{"type": "text", "text": "{\"temperature\":21}"}], "structuredContent": {"temperature": 21}}`},
+		{"less specific", `{"city": "Oslo", "days": 4}`, `{"content": [{"type": "text", "text": "Sunny in Oslo"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, rpcErr := call(t, s, "tools/call", `{"name": "forecast", "arguments": `+tt.arguments+`}`)
+			require.Nil(t, rpcErr)
+			assert.JSONEq(t, tt.expected, result)
+		})
+	}
+
+	_, rpcErr := call(t, s, "tools/call", `{"name": "forecast", "arguments": {"city": "Nowhere"}}`)
+	assert.Equal(t, &jsonrpc.Error{Code: -32602, Message: "unknown city"}, rpcErr)
+
+	result, _ = call(t, s, "tools/call", `{"name": "raw"}`)
+	assert.JSONEq(t, `{"content": [{"type": "text", "text": "raw"}], "isError": true}`, result)
+
+	_, rpcErr = call(t, s, "tools/call", `{"name": "ghost"}`)
+	assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+}
+
+func TestServerToolWithoutMatchingResponse(t *testing.T) {
+	s := mockserver.New(&mockserver.Fixture{Tools: []mockserver.Tool{{Name: "only", Responses: []mockserver.Response{{Match: map[string]interface{}{"x": 1}, Text: "one"}}}}}, mockserver.Options{})
+
+	result, _ := call(t, s, "tools/call", `{"name": "only", "arguments": {"x": 1}}`)
+	assert.Contains(t, result, "one")
+
+	result, _ = call(t, s, "tools/call", `{"name": "only", "arguments": {"x": 2}}`)
+	assert.Contains(t, result, `"isError":true`)
+	assert.Contains(t, result, "no mock response for tool only")
+}
+
+func TestServerResourcesAndPrompts(t *testing.T) {
+	s := weatherServer(t, mockserver.Options{})
+
+	result, _ := call(t, s, "resources/list", `{}`)
+	assert.JSONEq(t, `{"resources": [{"uri": "file:///notes.txt", "name": "notes", "mimeType": "text/plain"}]}`, result)
+
+	result, _ = call(t, s, "resources/read", `{"uri": "file:///notes.txt"}`)
+	assert.JSONEq(t, `{"contents": [{"uri": "file:///notes.txt", "mimeType": "text/plain", "text": "hello"}]}`, result)
+
+	_, rpcErr := call(t, s, "resources/read", `{"uri": "file:///missing"}`)
+	assert.Equal(t, jsonrpc.CodeResourceNotFound, rpcErr.Code)
+
+	result, _ = call(t, s, "prompts/get", `{"name": "greet", "arguments": {"who": "Ada"}}`)
+	assert.JSONEq(t, `{"description": "Greet someone", "messages": [{"role": "user", "content": {"type": "text", "text": "Say hello to Ada"}}]}`, result)
+
+	_, rpcErr = call(t, s, "prompts/get", `{"name": "greet"}`)
+	assert.Equal(t, `missing required argument "who"`, rpcErr.Message)
+
+	_, rpcErr = call(t, s, "prompts/list", `{"cursor": "page-2"}`)
+	assert.Equal(t, jsonrpc.CodeInvalidParams, rpcErr.Code)
+
+	_, rpcErr = call(t, s, "completion/complete", `{}`)
+	assert.Equal(t, jsonrpc.CodeMethodNotFound, rpcErr.Code)
+}
+
+func TestServerInjection(t *testing.T) {
+	s := weatherServer(t, mockserver.Options{ErrorRate: 1})
+	_, rpcErr := call(t, s, "ping", `{}`)
+	assert.Equal(t, &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: "injected error"}, rpcErr)
+
+	_, rpcErr = call(t, s, "initialize", `{}`)
+	assert.Nil(t, rpcErr, "the handshake is never failed")
+
+	s = weatherServer(t, mockserver.Options{Latency: 20 * time.Millisecond})
+	start := time.Now()
+	_, rpcErr = call(t, s, "ping", `{}`)
+	assert.Nil(t, rpcErr)
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, rpcErr = s.Handle(ctx, "ping", nil)
+	assert.NotNil(t, rpcErr)
+}
+
+func TestServerInjectionIsReproducible(t *testing.T) {
+	failures := func() []bool {
+		s := weatherServer(t, mockserver.Options{ErrorRate: 0.5, Seed: 42})
+		var result []bool
+		for i := 0; i < 20; i++ {
+			_, rpcErr := call(t, s, "ping", `{}`)
+			result = append(result, rpcErr != nil)
+		}
+		return result
+	}
+	first := failures()
+	assert.Equal(t, first, failures())
+	assert.Contains(t, first, true)
+	assert.Contains(t, first, false)
+}
+
+func TestServeStdio(t *testing.T) {
+	s := weatherServer(t, mockserver.Options{})
+	in := strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": {"protocolVersion": "2025-06-18"}}
+{"jsonrpc": "2.0", "method": "notifications/initialized"}
+
+not json
+{"jsonrpc": "2.0", "id": "b", "method": "ping"}
+`)
+	var out strings.Builder
+	require.NoError(t, s.ServeStdio(context.Background(), in, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	joined := out.String()
+	assert.Contains(t, joined, `"id":1,"result":{"capabilities"`)
+	assert.Contains(t, joined, `{"jsonrpc":"2.0","id":"b","result":{}}`)
+	assert.Contains(t, joined, `"id":null,"error":{"code":-32700`)
+}
+
+func TestHTTPHandler(t *testing.T) {
+	server := httptest.NewServer(weatherServer(t, mockserver.Options{}).HTTPHandler())
+	defer server.Close()
+
+	conn, err := conformance.DialHTTP(server.URL)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	ctx := context.Background()
+	resp, err := conn.Call(ctx, "initialize", map[string]interface{}{"protocolVersion": "2025-06-18"})
+	require.NoError(t, err)
+	require.Nil(t, resp.Error)
+	require.NoError(t, conn.Notify(ctx, "notifications/initialized", nil))
+
+	resp, err = conn.Call(ctx, "tools/call", map[string]interface{}{"name": "forecast", "arguments": map[string]string{"city": "Bergen"}})
+	require.NoError(t, err)
+	assert.Contains(t, string(resp.Result), "Rain")
+
+	// Requests outside a session are rejected
+	post, err := http.Post(server.URL, "application/json", strings.NewReader(`{"jsonrpc": "2.0", "id": 1, "method": "ping"}`))
+	require.NoError(t, err)
+	body, _ := io.ReadAll(post.Body)
+	post.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, post.StatusCode)
+	assert.Contains(t, string(body), "missing Mcp-Session-Id")
+
+	get, err := http.Get(server.URL)
+	require.NoError(t, err)
+	get.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, get.StatusCode)
+}
+
+func TestServerConformance(t *testing.T) {
+	server := httptest.NewServer(weatherServer(t, mockserver.Options{}).HTTPHandler())
+	defer server.Close()
+
+	report, err := conformance.Run(context.Background(), "mock", func() (conformance.Conn, error) {
+		return conformance.DialHTTP(server.URL)
+	}, 5*time.Second)
+	require.NoError(t, err)
+	for _, finding := range report.Findings {
+		assert.NotEqual(t, conformance.StatusFail, finding.Status, "%s: %s", finding.ID, finding.Message)
+	}
+}
@@ -0,0 +1,186 @@
+package mockserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/streamable"
+)
+
+// request is an incoming JSON-RPC message
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response is an outgoing JSON-RPC response
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// isCall reports whether a message is a request that needs a response, rather than a
+// notification or a response to a server request
+func (r *request) isCall() bool {
+	return r.Method != "" && len(r.ID) > 0 && string(r.ID) != "null"
+}
+
+// respond handles a decoded message and returns the encoded response, or nil if none is due
+func (s *Server) respond(ctx context.Context, data []byte) []byte {
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return encodeResponse(response{ID: json.RawMessage("null"), Error: &jsonrpc.Error{Code: jsonrpc.CodeParseError, Message: err.Error()}})
+	}
+	if !req.isCall() {
+		return nil
+	}
+	if req.JSONRPC != "2.0" {
+		return encodeResponse(response{ID: req.ID, Error: &jsonrpc.Error{Code: jsonrpc.CodeInvalidRequest, Message: `jsonrpc must be "2.0"`}})
+	}
+
+	params := req.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	result, rpcErr := s.Handle(ctx, req.Method, params)
+	return encodeResponse(response{ID: req.ID, Result: result, Error: rpcErr})
+}
+
+// encodeResponse encodes a response; results are always present when there is no error
+func encodeResponse(resp response) []byte {
+	resp.JSONRPC = "2.0"
+	if resp.Error == nil && resp.Result == nil {
+		resp.Result = struct{}{}
+	}
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{JSONRPC: "2.0", ID: resp.ID, Error: &jsonrpc.Error{Code: jsonrpc.CodeInternalError, Message: err.Error()}})
+	}
+	return data
+}
+
+// ServeStdio answers newline-delimited JSON-RPC messages read from in until it ends. Requests are
+// handled concurrently, so a slow response does not hold up the others.
+func (s *Server) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		data := append([]byte(nil), line...)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reply := s.respond(ctx, data)
+			if reply == nil {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			_, _ = out.Write(append(reply, '\n'))
+		}()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %w", err)
+	}
+	return nil
+}
+
+// HTTPHandler serves the streamable HTTP transport. Each request is answered with a single
+// server-sent event, sessions start with initialize, and GET streams are not offered.
+func (s *Server) HTTPHandler() http.Handler {
+	return &httpHandler{server: s, sessions: make(map[string]bool)}
+}
+
+// httpHandler tracks the sessions of the streamable HTTP transport
+type httpHandler struct {
+	server *Server
+
+	mu       sync.Mutex
+	sessions map[string]bool
+}
+
+// ServeHTTP handles POST for messages and DELETE to end a session
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodPost:
+		h.post(w, r)
+	case http.MethodDelete:
+		h.mu.Lock()
+		delete(h.sessions, r.Header.Get(streamable.HeaderSessionID))
+		h.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "POST, DELETE")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// post answers one message
+func (h *httpHandler) post(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var req request
+	if err := json.Unmarshal(data, &req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write(h.server.respond(r.Context(), data))
+		return
+	}
+
+	if req.Method == "initialize" {
+		id, err := streamable.NewSessionID()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		h.mu.Lock()
+		h.sessions[id] = true
+		h.mu.Unlock()
+		w.Header().Set(streamable.HeaderSessionID, id)
+	} else {
+		id := r.Header.Get(streamable.HeaderSessionID)
+		h.mu.Lock()
+		known := h.sessions[id]
+		h.mu.Unlock()
+		switch {
+		case id == "":
+			http.Error(w, "missing "+streamable.HeaderSessionID+" header", http.StatusBadRequest)
+			return
+		case !known:
+			http.Error(w, "unknown session", http.StatusNotFound)
+			return
+		}
+	}
+
+	reply := h.server.respond(r.Context(), data)
+	if reply == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	fmt.Fprintf(w, "event: message\ndata: %s\n\n", reply)
+}
@@ -0,0 +1,239 @@
+// Package params builds tool call arguments from inline JSON, files, stdin and key=value pairs
+package params
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads parameters from a spec: inline JSON, "@path" for a JSON or YAML file, or "-" for
+// JSON or YAML read from stdin. An empty spec yields an empty object.
+func Load(spec string, stdin io.Reader) (map[string]interface{}, error) {
+	switch {
+	case spec == "":
+		return map[string]interface{}{}, nil
+	case spec == "-":
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parameters from stdin: %w", err)
+		}
+		return Parse(data, true)
+	case strings.HasPrefix(spec, "@"):
+		path := spec[1:]
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read parameters file: %w", err)
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		return Parse(data, ext == ".yaml" || ext == ".yml")
+	default:
+		return Parse([]byte(spec), false)
+	}
+}
+
+// Parse decodes a JSON object, or a YAML mapping when allowYAML is set and the data is not JSON
+func Parse(data []byte, allowYAML bool) (map[string]interface{}, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return map[string]interface{}{}, nil
+	}
+
+	var value interface{}
+	if !allowYAML || trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse parameters as JSON: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(trimmed, &value); err != nil {
+			return nil, fmt.Errorf("failed to parse parameters as YAML: %w", err)
+		}
+		var err error
+		if value, err = normalizeYAML(value); err != nil {
+			return nil, err
+		}
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parameters must be an object, got %T", value)
+	}
+	return object, nil
+}
+
+// normalizeYAML converts YAML-decoded values into the types encoding/json produces
+func normalizeYAML(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			normalized, err := normalizeYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			v[key] = normalized
+		}
+		return v, nil
+	case map[interface{}]interface{}:
+		object := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			name, ok := key.(string)
+			if !ok {
+				return nil, fmt.Errorf("parameter keys must be strings, got %v", key)
+			}
+			normalized, err := normalizeYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			object[name] = normalized
+		}
+		return object, nil
+	case []interface{}:
+		for i, item := range v {
+			normalized, err := normalizeYAML(item)
+			if err != nil {
+				return nil, err
+			}
+			v[i] = normalized
+		}
+		return v, nil
+	case int:
+		return float64(v), nil
+	default:
+		return v, nil
+	}
+}
+
+// ApplyArgs sets key=value pairs on the parameters. Keys may be dotted paths into nested objects.
+// Values are converted to the type the schema declares for the key, so count=3 becomes a number
+// and a repeated key for an array property appends to it. Without a schema, values that parse as
+// JSON are used as such and anything else is a string.
+func ApplyArgs(params map[string]interface{}, args []string, s *jsonschema.Schema) error {
+	for _, arg := range args {
+		key, raw, ok := strings.Cut(arg, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("invalid argument %q (expected key=value)", arg)
+		}
+
+		path := strings.Split(key, ".")
+		target := params
+		propertySchema := s
+		for _, name := range path[:len(path)-1] {
+			propertySchema = property(propertySchema, name)
+			next, ok := target[name].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				target[name] = next
+			}
+			target = next
+		}
+
+		name := path[len(path)-1]
+		propertySchema = property(propertySchema, name)
+		value, err := coerce(propertySchema, raw)
+		if err != nil {
+			return fmt.Errorf("invalid argument %s: %w", arg, err)
+		}
+
+		// A repeated key for an array property collects its items
+		if existing, ok := target[name].([]interface{}); ok && schemaType(propertySchema) == "array" {
+			if items, ok := value.([]interface{}); ok && !strings.HasPrefix(strings.TrimSpace(raw), "[") {
+				value = append(existing, items...)
+			}
+		}
+		target[name] = value
+	}
+	return nil
+}
+
+// property returns the schema of an object property, or nil if it is not declared
+func property(s *jsonschema.Schema, name string) *jsonschema.Schema {
+	if s == nil {
+		return nil
+	}
+	if sub, ok := s.Properties[name]; ok {
+		return sub
+	}
+	return s.AdditionalProperties
+}
+
+// coerce converts a raw string into the type a schema expects
+func coerce(s *jsonschema.Schema, raw string) (interface{}, error) {
+	switch schemaType(s) {
+	case "string":
+		return raw, nil
+	case "integer":
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected an integer")
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("expected a number")
+		}
+		return n, nil
+	case "boolean":
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("expected true or false")
+		}
+		return b, nil
+	case "array":
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			return decodeJSON(raw)
+		}
+		// A single item, appended by ApplyArgs when the key is repeated
+		item, err := coerce(s.Items, raw)
+		if err != nil {
+			return nil, err
+		}
+		return []interface{}{item}, nil
+	case "object":
+		return decodeJSON(raw)
+	}
+
+	// Without a declared type, prefer JSON literals such as 3, true or {"a":1}
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err == nil {
+		return value, nil
+	}
+	return raw, nil
+}
+
+// decodeJSON parses a JSON value
+func decodeJSON(raw string) (interface{}, error) {
+	var value interface{}
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return nil, fmt.Errorf("expected JSON: %v", err)
+	}
+	return value, nil
+}
+
+// schemaType returns the single non-null type a schema declares, or "" if there is none
+func schemaType(s *jsonschema.Schema) string {
+	if s == nil {
+		return ""
+	}
+	if s.Type != "" {
+		return s.Type
+	}
+	var types []string
+	for _, t := range s.Types {
+		if t != "null" {
+			types = append(types, t)
+		}
+	}
+	if len(types) == 1 {
+		return types[0]
+	}
+	return ""
+}
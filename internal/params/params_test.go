@@ -0,0 +1,96 @@
+package params
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	jsonFile := filepath.Join(dir, "params.json")
+	yamlFile := filepath.Join(dir, "params.yaml")
+	require.NoError(t, os.WriteFile(jsonFile, []byte(`{"location": "Oslo", "days": 3}`), 0o644))
+	require.NoError(t, os.WriteFile(yamlFile, []byte("location: Oslo\ndays: 3\ntags:\n  - a\n"), 0o644))
+
+	tests := []struct {
+		name     string
+		spec     string
+		stdin    string
+		expected map[string]interface{}
+	}{
+		{name: "empty", spec: "", expected: map[string]interface{}{}},
+		{name: "inline", spec: `{"location": "Oslo"}`, expected: map[string]interface{}{"location": "Oslo"}},
+		{name: "json file", spec: "@" + jsonFile, expected: map[string]interface{}{"location": "Oslo", "days": float64(3)}},
+		{name: "yaml file", spec: "@" + yamlFile, expected: map[string]interface{}{"location": "Oslo", "days": float64(3), "tags": []interface{}{"a"}}},
+		{name: "json stdin", spec: "-", stdin: `{"days": 1}`, expected: map[string]interface{}{"days": float64(1)}},
+		{name: "yaml stdin", spec: "-", stdin: "days: 1\n", expected: map[string]interface{}{"days": float64(1)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := Load(tt.spec, strings.NewReader(tt.stdin))
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, params)
+		})
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := Load(`[1, 2]`, nil)
+	assert.EqualError(t, err, "parameters must be an object, got []interface {}")
+
+	_, err = Load(`{"unterminated": `, nil)
+	assert.Error(t, err)
+
+	_, err = Load("@"+filepath.Join(t.TempDir(), "missing.json"), nil)
+	assert.Error(t, err)
+}
+
+func TestApplyArgs(t *testing.T) {
+	var s jsonschema.Schema
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"type": "object",
+		"properties": {
+			"count": {"type": "integer"},
+			"ratio": {"type": "number"},
+			"name": {"type": "string"},
+			"verbose": {"type": "boolean"},
+			"tags": {"type": "array", "items": {"type": "integer"}},
+			"filter": {"type": "object", "properties": {"limit": {"type": "integer"}}}
+		}
+	}`), &s))
+
+	params := map[string]interface{}{"name": "old"}
+	require.NoError(t, ApplyArgs(params, []string{
+		"count=3",
+		"ratio=0.5",
+		"name=42",
+		"verbose=true",
+		"tags=1",
+		"tags=2",
+		"filter.limit=10",
+		"extra={\"a\":1}",
+		"note=hello world",
+	}, &s))
+
+	assert.Equal(t, map[string]interface{}{
+		"count":   int64(3),
+		"ratio":   0.5,
+		"name":    "42",
+		"verbose": true,
+		"tags":    []interface{}{int64(1), int64(2)},
+		"filter":  map[string]interface{}{"limit": int64(10)},
+		"extra":   map[string]interface{}{"a": float64(1)},
+		"note":    "hello world",
+	}, params)
+
+	assert.EqualError(t, ApplyArgs(params, []string{"count=many"}, &s), "invalid argument count=many: expected an integer")
+	assert.EqualError(t, ApplyArgs(params, []string{"count"}, &s), `invalid argument "count" (expected key=value)`)
+}
@@ -5,10 +5,12 @@ import (
 	"io"
 )
 
-// Message represents a chat message
+// Message represents a chat message. Content holds plain text; Parts carries images, tool calls
+// and tool results, and any text that has to stay in order with them.
 type Message struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role    string        `json:"role"`
+	Content string        `json:"content"`
+	Parts   []ContentPart `json:"parts,omitempty"`
 }
 
 // Tool represents an available tool
@@ -20,26 +22,27 @@ type Tool struct {
 
 // ToolCall represents a tool call request
 type ToolCall struct {
-	ID       string                 `json:"id"`
-	Name     string                 `json:"name"`
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments"`
 }
 
 // ToolResult represents the result of a tool call
 type ToolResult struct {
 	ID      string      `json:"id"`
+	Name    string      `json:"name,omitempty"`
 	Content interface{} `json:"content"`
 	Error   string      `json:"error,omitempty"`
 }
 
 // ChatRequest represents a chat completion request
 type ChatRequest struct {
-	Messages    []Message    `json:"messages"`
-	Tools       []Tool       `json:"tools,omitempty"`
-	Stream      bool         `json:"stream,omitempty"`
-	Temperature *float64     `json:"temperature,omitempty"`
-	MaxTokens   *int         `json:"max_tokens,omitempty"`
-	SystemPrompt string      `json:"system_prompt,omitempty"`
+	Messages     []Message `json:"messages"`
+	Tools        []Tool    `json:"tools,omitempty"`
+	Stream       bool      `json:"stream,omitempty"`
+	Temperature  *float64  `json:"temperature,omitempty"`
+	MaxTokens    *int      `json:"max_tokens,omitempty"`
+	SystemPrompt string    `json:"system_prompt,omitempty"`
 }
 
 // ChatResponse represents a chat completion response
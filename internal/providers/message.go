@@ -0,0 +1,106 @@
+package providers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Content part types
+const (
+	PartText       = "text"
+	PartImage      = "image"
+	PartToolUse    = "tool_use"
+	PartToolResult = "tool_result"
+)
+
+// ContentPart is one block of a multimodal message
+type ContentPart struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+	// MIMEType and Data hold the raw bytes of an image part
+	MIMEType string `json:"mime_type,omitempty"`
+	Data     []byte `json:"data,omitempty"`
+	// ToolCall is set on tool_use parts, ToolResult on tool_result parts
+	ToolCall   *ToolCall   `json:"tool_call,omitempty"`
+	ToolResult *ToolResult `json:"tool_result,omitempty"`
+}
+
+// TextPart creates a text part
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: PartText, Text: text}
+}
+
+// ImagePart creates an image part from raw image bytes
+func ImagePart(mimeType string, data []byte) ContentPart {
+	return ContentPart{Type: PartImage, MIMEType: mimeType, Data: data}
+}
+
+// ToolUsePart creates a part recording a tool call made by the assistant
+func ToolUsePart(call ToolCall) ContentPart {
+	return ContentPart{Type: PartToolUse, ToolCall: &call}
+}
+
+// ToolResultPart creates a part answering a tool call
+func ToolResultPart(result ToolResult) ContentPart {
+	return ContentPart{Type: PartToolResult, ToolResult: &result}
+}
+
+// Text returns the message text: Content followed by any text parts
+func (m Message) Text() string {
+	texts := make([]string, 0, len(m.Parts)+1)
+	if m.Content != "" {
+		texts = append(texts, m.Content)
+	}
+	for _, part := range m.Parts {
+		if part.Type == PartText {
+			texts = append(texts, part.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Images returns the image parts of the message
+func (m Message) Images() []ContentPart {
+	var images []ContentPart
+	for _, part := range m.Parts {
+		if part.Type == PartImage {
+			images = append(images, part)
+		}
+	}
+	return images
+}
+
+// ToolCalls returns the tool calls requested by an assistant message
+func (m Message) ToolCalls() []ToolCall {
+	var calls []ToolCall
+	for _, part := range m.Parts {
+		if part.Type == PartToolUse && part.ToolCall != nil {
+			calls = append(calls, *part.ToolCall)
+		}
+	}
+	return calls
+}
+
+// ToolResult returns the tool result carried by a "tool" message, or nil
+func (m Message) ToolResult() *ToolResult {
+	for _, part := range m.Parts {
+		if part.Type == PartToolResult && part.ToolResult != nil {
+			return part.ToolResult
+		}
+	}
+	return nil
+}
+
+// Text renders a tool result as the text shown to the model
+func (r ToolResult) Text() string {
+	if r.Error != "" {
+		return "Error: " + r.Error
+	}
+	if text, ok := r.Content.(string); ok {
+		return text
+	}
+	if r.Content == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v", r.Content)
+}
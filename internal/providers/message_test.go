@@ -0,0 +1,52 @@
+package providers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// conversation exercises every content part type: an attached image, a tool call, and a tool
+// result that returned an image
+func conversation() []Message {
+	return []Message{
+		{Role: "user", Content: "What is in this picture?", Parts: []ContentPart{ImagePart("image/png", []byte("png"))}},
+		{Role: "assistant", Parts: []ContentPart{ToolUsePart(ToolCall{ID: "call_0", Name: "zoom", Arguments: map[string]interface{}{"factor": 2}})}},
+		{Role: "tool", Parts: []ContentPart{
+			ToolResultPart(ToolResult{ID: "call_0", Name: "zoom", Content: "zoomed"}),
+			ImagePart("image/jpeg", []byte("jpg")),
+		}},
+	}
+}
+
+// toJSON marshals a value for comparison against expected wire JSON
+func toJSON(t *testing.T, value interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestMessageAccessors(t *testing.T) {
+	msg := Message{Role: "user", Content: "first", Parts: []ContentPart{TextPart("second"), ImagePart("image/png", nil)}}
+	assert.Equal(t, "first\nsecond", msg.Text())
+	assert.Len(t, msg.Images(), 1)
+	assert.Nil(t, msg.ToolResult())
+
+	assert.Equal(t, "Error: denied", ToolResult{Error: "denied", Content: "ignored"}.Text())
+}
+
+func TestConvertOllamaMessages(t *testing.T) {
+	var converted []OllamaMessage
+	for _, msg := range conversation() {
+		converted = append(converted, convertOllamaMessage(msg))
+	}
+
+	assert.JSONEq(t, `[
+		{"role": "user", "content": "What is in this picture?", "images": ["cG5n"]},
+		{"role": "assistant", "content": "", "tool_calls": [{"function": {"name": "zoom", "arguments": {"factor": 2}}}]},
+		{"role": "tool", "content": "zoomed", "images": ["anBn"], "tool_name": "zoom"}
+	]`, toJSON(t, converted))
+}
@@ -3,6 +3,7 @@ package providers
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -30,17 +31,25 @@ type OllamaRequest struct {
 
 // OllamaMessage represents an Ollama message
 type OllamaMessage struct {
-	Role    string `json:"role"`
-	Content string `json:"content"`
+	Role      string           `json:"role"`
+	Content   string           `json:"content"`
+	Images    []string         `json:"images,omitempty"` // base64-encoded
+	ToolCalls []OllamaToolCall `json:"tool_calls,omitempty"`
+	ToolName  string           `json:"tool_name,omitempty"`
+}
+
+// OllamaToolCall represents a tool call in an Ollama message
+type OllamaToolCall struct {
+	Function struct {
+		Name      string                 `json:"name"`
+		Arguments map[string]interface{} `json:"arguments"`
+	} `json:"function"`
 }
 
 // OllamaResponse represents an Ollama API response
 type OllamaResponse struct {
-	Message struct {
-		Role    string `json:"role"`
-		Content string `json:"content"`
-	} `json:"message"`
-	Done bool `json:"done"`
+	Message OllamaMessage `json:"message"`
+	Done    bool          `json:"done"`
 }
 
 // NewOllamaProvider creates a new Ollama provider
@@ -114,8 +123,9 @@ func (p *OllamaProvider) Chat(ctx context.Context, request *ChatRequest) (*ChatR
 	}
 
 	return &ChatResponse{
-		Content:  ollamaResp.Message.Content,
-		Finished: ollamaResp.Done,
+		Content:   ollamaResp.Message.Content,
+		ToolCalls: convertOllamaToolCalls(ollamaResp.Message.ToolCalls),
+		Finished:  ollamaResp.Done,
 	}, nil
 }
 
@@ -167,8 +177,9 @@ func (p *OllamaProvider) ChatStream(ctx context.Context, request *ChatRequest) (
 			}
 
 			response := &ChatResponse{
-				Content:  ollamaResp.Message.Content,
-				Finished: ollamaResp.Done,
+				Content:   ollamaResp.Message.Content,
+				ToolCalls: convertOllamaToolCalls(ollamaResp.Message.ToolCalls),
+				Finished:  ollamaResp.Done,
 			}
 
 			select {
@@ -204,7 +215,7 @@ func (p *OllamaProvider) convertRequest(request *ChatRequest) *OllamaRequest {
 
 	// Convert messages
 	for _, msg := range request.Messages {
-		ollamaReq.Messages = append(ollamaReq.Messages, OllamaMessage(msg))
+		ollamaReq.Messages = append(ollamaReq.Messages, convertOllamaMessage(msg))
 	}
 
 	// Set temperature if provided
@@ -231,6 +242,49 @@ func (p *OllamaProvider) convertRequest(request *ChatRequest) *OllamaRequest {
 	return ollamaReq
 }
 
+// convertOllamaMessage converts a generic message. Ollama takes images as a list of base64
+// strings next to the text, and a tool result as a "tool" message naming the tool.
+func convertOllamaMessage(msg Message) OllamaMessage {
+	ollamaMsg := OllamaMessage{
+		Role:    msg.Role,
+		Content: msg.Text(),
+	}
+
+	if result := msg.ToolResult(); result != nil {
+		ollamaMsg.Content = result.Text()
+		ollamaMsg.ToolName = result.Name
+	}
+	for _, image := range msg.Images() {
+		ollamaMsg.Images = append(ollamaMsg.Images, base64.StdEncoding.EncodeToString(image.Data))
+	}
+	for _, call := range msg.ToolCalls() {
+		var ollamaCall OllamaToolCall
+		ollamaCall.Function.Name = call.Name
+		ollamaCall.Function.Arguments = call.Arguments
+		ollamaMsg.ToolCalls = append(ollamaMsg.ToolCalls, ollamaCall)
+	}
+
+	return ollamaMsg
+}
+
+// convertOllamaToolCalls converts Ollama tool calls to the generic representation.
+// Ollama does not assign call IDs, so one is derived from the position in the message.
+func convertOllamaToolCalls(calls []OllamaToolCall) []ToolCall {
+	if len(calls) == 0 {
+		return nil
+	}
+
+	toolCalls := make([]ToolCall, len(calls))
+	for i, call := range calls {
+		toolCalls[i] = ToolCall{
+			ID:        fmt.Sprintf("call_%d", i),
+			Name:      call.Function.Name,
+			Arguments: call.Function.Arguments,
+		}
+	}
+	return toolCalls
+}
+
 // Close closes any resources used by the provider
 func (p *OllamaProvider) Close() error {
 	// HTTP client doesn't need explicit closing
@@ -0,0 +1,218 @@
+package proxy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of messages a rule applies to
+const (
+	KindRequest      = "request"
+	KindResponse     = "response"
+	KindNotification = "notification"
+)
+
+// Faults describes the faults the proxy injects into the traffic it forwards
+type Faults struct {
+	// Latency delays every message except the initialize handshake
+	Latency time.Duration `yaml:"latency"`
+	// Jitter adds a random delay of up to this much to every delayed message
+	Jitter time.Duration `yaml:"jitter"`
+	// DropRate is the share of messages, between 0 and 1, that are never delivered
+	DropRate float64 `yaml:"drop_rate"`
+	// Rules apply to matching messages on top of the faults above; every matching rule applies
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule injects faults into the messages it matches
+type Rule struct {
+	// Method matches the method of a request or notification, or of the request a response
+	// answers. Patterns such as tools/* are allowed; empty matches every method.
+	Method string `yaml:"method"`
+	// Tool matches the name of the tool called by tools/call
+	Tool string `yaml:"tool"`
+	// On is the kind of message the rule applies to: request, response (the default) or
+	// notification
+	On string `yaml:"on"`
+	// Probability is the chance, between 0 and 1, that the rule applies; zero always applies
+	Probability float64 `yaml:"probability"`
+	// Latency delays the message
+	Latency time.Duration `yaml:"latency"`
+	// Drop discards the message
+	Drop bool `yaml:"drop"`
+	// Error answers with a JSON-RPC error instead: a response is replaced, and a request is not
+	// forwarded to the server
+	Error *Error `yaml:"error"`
+	// Result replaces the result of a response
+	Result map[string]interface{} `yaml:"result"`
+	// Replace substitutes text in the strings of a response's result
+	Replace []Replacement `yaml:"replace"`
+}
+
+// Error is a JSON-RPC error a rule answers with. The SDK sends errors that are not its own with
+// code 0, so only the message can be chosen.
+type Error struct {
+	Message string `yaml:"message"`
+}
+
+// Error returns the message of the error
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Replacement substitutes every occurrence of Old with New
+type Replacement struct {
+	Old string `yaml:"old"`
+	New string `yaml:"new"`
+}
+
+// Load reads faults from a YAML file
+func Load(path string) (*Faults, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read faults: %w", err)
+	}
+	faults, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return faults, nil
+}
+
+// Parse decodes faults from YAML and checks that they are well formed
+func Parse(data []byte) (*Faults, error) {
+	var faults Faults
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&faults); err != nil {
+		return nil, fmt.Errorf("failed to parse faults: %w", err)
+	}
+	if err := faults.Validate(); err != nil {
+		return nil, err
+	}
+	return &faults, nil
+}
+
+// Validate checks rates are between 0 and 1 and every rule can apply to its kind of message
+func (f *Faults) Validate() error {
+	if f.DropRate < 0 || f.DropRate > 1 {
+		return fmt.Errorf("drop_rate must be between 0 and 1")
+	}
+	if f.Latency < 0 || f.Jitter < 0 {
+		return fmt.Errorf("latency and jitter must not be negative")
+	}
+	for i := range f.Rules {
+		if err := f.Rules[i].validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// validate fills in the default kind and checks the rule's actions suit it
+func (r *Rule) validate() error {
+	if r.On == "" {
+		r.On = KindResponse
+	}
+	switch r.On {
+	case KindRequest, KindResponse, KindNotification:
+	default:
+		return fmt.Errorf("invalid on %q (expected request, response or notification)", r.On)
+	}
+	if _, err := path.Match(r.Method, ""); err != nil {
+		return fmt.Errorf("invalid method pattern %q: %w", r.Method, err)
+	}
+	if r.Probability < 0 || r.Probability > 1 {
+		return fmt.Errorf("probability must be between 0 and 1")
+	}
+	if r.Latency < 0 {
+		return fmt.Errorf("latency must not be negative")
+	}
+
+	if r.Error != nil && r.Error.Message == "" {
+		return fmt.Errorf("error needs a message")
+	}
+	if r.Error != nil && r.On == KindNotification {
+		return fmt.Errorf("notifications cannot be answered with an error")
+	}
+	if (r.Result != nil || len(r.Replace) > 0) && r.On != KindResponse {
+		return fmt.Errorf("result and replace only apply to responses")
+	}
+	if r.Error != nil && (r.Result != nil || len(r.Replace) > 0) {
+		return fmt.Errorf("error cannot be combined with result or replace")
+	}
+	for _, replacement := range r.Replace {
+		if replacement.Old == "" {
+			return fmt.Errorf("replace needs a non-empty old value")
+		}
+	}
+	if !r.Drop && r.Latency == 0 && r.Error == nil && r.Result == nil && len(r.Replace) == 0 {
+		return fmt.Errorf("rule has no effect (set latency, drop, error, result or replace)")
+	}
+	return nil
+}
+
+// matches reports whether the rule applies to a message of the given kind, method and tool
+func (r *Rule) matches(kind, method, tool string) bool {
+	if r.On != kind {
+		return false
+	}
+	if r.Method != "" {
+		if ok, _ := path.Match(r.Method, method); !ok {
+			return false
+		}
+	}
+	return r.Tool == "" || r.Tool == tool
+}
+
+// rewrite applies the rule's result and replacements to a result
+func (r *Rule) rewrite(result json.RawMessage) (json.RawMessage, error) {
+	if r.Result != nil {
+		data, err := json.Marshal(r.Result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode result: %w", err)
+		}
+		result = data
+	}
+	if len(r.Replace) == 0 {
+		return result, nil
+	}
+
+	// Replacements work on the decoded strings, so quotes and escapes in the JSON cannot be broken
+	var value interface{}
+	if err := json.Unmarshal(result, &value); err != nil {
+		return nil, fmt.Errorf("failed to decode result: %w", err)
+	}
+	data, err := json.Marshal(r.replace(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode result: %w", err)
+	}
+	return data, nil
+}
+
+// replace applies the replacements to every string in a decoded JSON value
+func (r *Rule) replace(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		for _, replacement := range r.Replace {
+			v = strings.ReplaceAll(v, replacement.Old, replacement.New)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = r.replace(v[i])
+		}
+	case map[string]interface{}:
+		for key := range v {
+			v[key] = r.replace(v[key])
+		}
+	}
+	return value
+}
@@ -0,0 +1,99 @@
+package proxy
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFaults(t *testing.T) {
+	faults, err := Parse([]byte(`
+latency: 50ms
+jitter: 10ms
+drop_rate: 0.1
+rules:
+  - method: tools/call
+    tool: weather
+    latency: 2s
+  - method: tools/*
+    probability: 0.5
+    replace:
+      - old: Sunny
+        new: Hail
+  - method: resources/read
+    on: request
+    error:
+      message: Resource not found
+`))
+	require.NoError(t, err)
+
+	assert.Equal(t, 50*time.Millisecond, faults.Latency)
+	assert.Equal(t, 10*time.Millisecond, faults.Jitter)
+	assert.Equal(t, 0.1, faults.DropRate)
+	require.Len(t, faults.Rules, 3)
+	assert.Equal(t, KindResponse, faults.Rules[0].On)
+	assert.Equal(t, 2*time.Second, faults.Rules[0].Latency)
+	assert.Equal(t, []Replacement{{Old: "Sunny", New: "Hail"}}, faults.Rules[1].Replace)
+	assert.Equal(t, KindRequest, faults.Rules[2].On)
+	assert.Equal(t, "Resource not found", faults.Rules[2].Error.Message)
+}
+
+func TestParseFaultsErrors(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":          "latancy: 1s",
+		"drop rate out of range": "drop_rate: 2",
+		"invalid kind":           "rules:\n  - on: reply\n    drop: true",
+		"invalid pattern":        "rules:\n  - method: '['\n    drop: true",
+		"probability":            "rules:\n  - probability: -1\n    drop: true",
+		"no effect":              "rules:\n  - method: tools/call",
+		"error on notification":  "rules:\n  - on: notification\n    error: {message: x}",
+		"rewrite on request":     "rules:\n  - on: request\n    replace: [{old: a, new: b}]",
+		"error and result":       "rules:\n  - error: {message: x}\n    result: {content: []}",
+		"error without message":  "rules:\n  - error: {}",
+		"error code":             "rules:\n  - error: {code: 1, message: x}",
+		"empty replacement":      "rules:\n  - replace: [{old: '', new: b}]",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse([]byte(data))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadFaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "faults.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("drop_rate: 3\n"), 0644))
+
+	_, err := Load(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestRuleMatches(t *testing.T) {
+	rule := Rule{Method: "tools/*", Tool: "weather", On: KindResponse}
+	assert.True(t, rule.matches(KindResponse, "tools/call", "weather"))
+	assert.False(t, rule.matches(KindRequest, "tools/call", "weather"))
+	assert.False(t, rule.matches(KindResponse, "tools/call", "forecast"))
+	assert.False(t, rule.matches(KindResponse, "prompts/get", "weather"))
+
+	notifications := Rule{On: KindNotification}
+	assert.True(t, notifications.matches(KindNotification, "notifications/progress", ""))
+}
+
+func TestRuleRewrite(t *testing.T) {
+	rule := Rule{Replace: []Replacement{{Old: "Sunny", New: `"Hail"`}, {Old: "Oslo", New: "Bergen"}}}
+	result, err := rule.rewrite(json.RawMessage(`{"content":[{"type":"text","text":"Sunny in Oslo"}],"isError":false}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[{"type":"text","text":"\"Hail\" in Bergen"}],"isError":false}`, string(result))
+
+	rule = Rule{Result: map[string]interface{}{"content": []interface{}{}}, Replace: []Replacement{{Old: "a", New: "b"}}}
+	result, err = rule.rewrite(json.RawMessage(`{"ignored":true}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"content":[]}`, string(result))
+}
@@ -0,0 +1,105 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/streamable"
+)
+
+// HTTPHandler serves the proxy over streamable HTTP. Every session starts with a POST without a
+// session ID and gets its own connection to the server; DELETE ends it.
+func (p *Proxy) HTTPHandler() http.Handler {
+	return &httpHandler{proxy: p, sessions: make(map[string]*mcp.StreamableServerTransport)}
+}
+
+// httpHandler tracks the host sessions of the streamable HTTP transport
+type httpHandler struct {
+	proxy *Proxy
+
+	mu       sync.Mutex
+	sessions map[string]*mcp.StreamableServerTransport
+}
+
+// ServeHTTP routes a request to its session, starting a new one if it has no session ID
+func (h *httpHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	id := r.Header.Get(streamable.HeaderSessionID)
+	if id == "" {
+		if r.Method != http.MethodPost {
+			http.Error(w, fmt.Sprintf("%s requires an %s header", r.Method, streamable.HeaderSessionID), http.StatusBadRequest)
+			return
+		}
+		h.start(w, r)
+		return
+	}
+
+	h.mu.Lock()
+	session := h.sessions[id]
+	h.mu.Unlock()
+	if session == nil {
+		http.Error(w, "session not found", http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		h.end(id)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	session.ServeHTTP(w, r)
+}
+
+// start connects a new session to the server and serves its first request
+func (h *httpHandler) start(w http.ResponseWriter, r *http.Request) {
+	id, err := streamable.NewSessionID()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// The session outlives the request that starts it
+	ctx := context.WithoutCancel(r.Context())
+	server, err := h.proxy.connect(ctx)
+	if err != nil {
+		h.proxy.logger.WithError(err).Warn("Failed to start session")
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	session := mcp.NewStreamableServerTransport(id)
+	host, err := session.Connect(ctx)
+	if err != nil {
+		server.Close()
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	h.mu.Lock()
+	h.sessions[id] = session
+	h.mu.Unlock()
+	h.proxy.logger.WithField("session", id).Debug("Started session")
+
+	go func() {
+		if err := h.proxy.forward(ctx, host, server); err != nil {
+			h.proxy.logger.WithError(err).WithField("session", id).Warn("Session ended")
+		}
+		h.end(id)
+	}()
+	session.ServeHTTP(w, r)
+}
+
+// end closes a session and forgets it
+func (h *httpHandler) end(id string) {
+	h.mu.Lock()
+	session := h.sessions[id]
+	delete(h.sessions, id)
+	h.mu.Unlock()
+	if session != nil {
+		session.Close()
+		h.proxy.logger.WithField("session", id).Debug("Ended session")
+	}
+}
@@ -0,0 +1,347 @@
+// Package proxy sits between an MCP host and a server, forwarding every message and injecting
+// latency, dropped messages and rewritten responses for fault testing
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Dialer creates a new transport to the server for every proxied session
+type Dialer func() (mcp.Transport, error)
+
+// Options controls the faults a proxy injects
+type Options struct {
+	Faults Faults
+	// Seed makes injected jitter, drops and rule probabilities reproducible; zero seeds from the clock
+	Seed int64
+}
+
+// Proxy forwards sessions from hosts to a server
+type Proxy struct {
+	server string
+	dial   Dialer
+	faults Faults
+	logger *logrus.Entry
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// New creates a proxy to the named server. The faults must have been validated.
+func New(server string, dial Dialer, opts Options, logger *logrus.Logger) *Proxy {
+	if opts.Seed == 0 {
+		opts.Seed = time.Now().UnixNano()
+	}
+	return &Proxy{
+		server: server,
+		dial:   dial,
+		faults: opts.Faults,
+		logger: logger.WithField("server", server),
+		rand:   rand.New(rand.NewSource(opts.Seed)),
+	}
+}
+
+// Serve forwards one session from a host transport, such as stdio, until either side closes or
+// ctx is done
+func (p *Proxy) Serve(ctx context.Context, host mcp.Transport) error {
+	server, err := p.connect(ctx)
+	if err != nil {
+		return err
+	}
+	hostConn, err := host.Connect(ctx)
+	if err != nil {
+		server.Close()
+		return fmt.Errorf("failed to accept host connection: %w", err)
+	}
+	return p.forward(ctx, hostConn, server)
+}
+
+// connect opens a new connection to the server
+func (p *Proxy) connect(ctx context.Context) (mcp.Connection, error) {
+	transport, err := p.dial()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := transport.Connect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to server %s: %w", p.server, err)
+	}
+	return conn, nil
+}
+
+// forward pumps messages both ways until one side closes, then closes the other
+func (p *Proxy) forward(ctx context.Context, host, server mcp.Connection) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	s := &session{
+		proxy:   p,
+		host:    &endpoint{conn: host, name: "host"},
+		server:  &endpoint{conn: server, name: "server"},
+		pending: make(map[pendingKey]call),
+	}
+	done := make(chan error, 2)
+	go func() { done <- s.pump(ctx, s.host, s.server) }()
+	go func() { done <- s.pump(ctx, s.server, s.host) }()
+
+	// Reads do not always stop for the context, so closing both sides is what ends the pumps
+	var err error
+	pumps := 2
+	select {
+	case err = <-done:
+		pumps--
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	cancel()
+	host.Close()
+	server.Close()
+	for ; pumps > 0; pumps-- {
+		<-done
+	}
+	s.delayed.Wait()
+
+	if errors.Is(err, io.EOF) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// fault is what happens to one message
+type fault struct {
+	delay    time.Duration
+	drop     bool
+	err      *Error
+	rewrites []*Rule
+}
+
+// inject draws the faults for a message. The initialize handshake only gets the faults of rules.
+func (p *Proxy) inject(kind, method, tool string) fault {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var f fault
+	if method != "initialize" {
+		f.delay = p.faults.Latency
+		if p.faults.Jitter > 0 {
+			f.delay += time.Duration(p.rand.Int63n(int64(p.faults.Jitter)))
+		}
+		f.drop = p.faults.DropRate > 0 && p.rand.Float64() < p.faults.DropRate
+	}
+
+	for i := range p.faults.Rules {
+		rule := &p.faults.Rules[i]
+		if !rule.matches(kind, method, tool) {
+			continue
+		}
+		if rule.Probability > 0 && p.rand.Float64() >= rule.Probability {
+			continue
+		}
+		f.delay += rule.Latency
+		f.drop = f.drop || rule.Drop
+		if rule.Error != nil {
+			f.err = rule.Error
+		}
+		if rule.Result != nil || len(rule.Replace) > 0 {
+			f.rewrites = append(f.rewrites, rule)
+		}
+	}
+	return f
+}
+
+// endpoint is one side of a session. Writes are serialized, as the SDK's connections expect.
+type endpoint struct {
+	conn mcp.Connection
+	name string
+	mu   sync.Mutex
+}
+
+// write sends a message to this side
+func (e *endpoint) write(ctx context.Context, msg mcp.JSONRPCMessage) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.conn.Write(ctx, msg)
+}
+
+// pendingKey identifies a request by the side it was sent to and its ID
+type pendingKey struct {
+	to string
+	id mcp.JSONRPCID
+}
+
+// call is the method and tool of a request waiting for its response
+type call struct {
+	method string
+	tool   string
+}
+
+// session forwards the messages of one host connection to its own server connection
+type session struct {
+	proxy        *Proxy
+	host, server *endpoint
+	delayed      sync.WaitGroup
+
+	mu      sync.Mutex
+	pending map[pendingKey]call
+}
+
+// pump forwards every message read from one side to the other
+func (s *session) pump(ctx context.Context, from, to *endpoint) error {
+	for {
+		msg, err := from.conn.Read(ctx)
+		if err != nil {
+			if errors.Is(err, io.EOF) || ctx.Err() != nil {
+				return err
+			}
+			return fmt.Errorf("failed to read from %s: %w", from.name, err)
+		}
+		s.deliver(ctx, msg, from, to)
+	}
+}
+
+// deliver applies the injected faults to a message and sends it on
+func (s *session) deliver(ctx context.Context, msg mcp.JSONRPCMessage, from, to *endpoint) {
+	kind, method, tool := s.describe(msg, to)
+	if kind == "" {
+		s.send(ctx, to, msg, 0)
+		return
+	}
+
+	f := s.proxy.inject(kind, method, tool)
+	logger := s.proxy.logger.WithFields(logrus.Fields{"to": to.name, "kind": kind, "method": method})
+	if f.delay > 0 {
+		logger = logger.WithField("latency", f.delay)
+	}
+
+	switch {
+	case f.drop:
+		// A dropped request is never answered, so it no longer waits for a response
+		if kind == KindRequest {
+			s.answered(to, msg.(*mcp.JSONRPCRequest).ID)
+		}
+		logger.Info("Dropped message")
+		return
+	case f.err != nil && kind == KindRequest:
+		// The request never reaches the other side; its sender gets the error instead
+		req := msg.(*mcp.JSONRPCRequest)
+		s.answered(to, req.ID)
+		logger.Infof("Answered request with error %q", f.err.Message)
+		s.send(ctx, from, &mcp.JSONRPCResponse{ID: req.ID, Error: f.err}, f.delay)
+		return
+	case f.err != nil:
+		resp := msg.(*mcp.JSONRPCResponse)
+		resp.Result, resp.Error = nil, f.err
+		logger.Infof("Replaced response with error %q", f.err.Message)
+	case len(f.rewrites) > 0:
+		s.rewrite(logger, msg.(*mcp.JSONRPCResponse), f.rewrites)
+	case f.delay > 0:
+		logger.Debug("Delayed message")
+	}
+	s.send(ctx, to, msg, f.delay)
+}
+
+// rewrite applies rules to the result of a response. Error responses are only rewritten by rules
+// with a replacement result.
+func (s *session) rewrite(logger *logrus.Entry, resp *mcp.JSONRPCResponse, rules []*Rule) {
+	for _, rule := range rules {
+		if resp.Error != nil && rule.Result == nil {
+			continue
+		}
+		result, err := rule.rewrite(resp.Result)
+		if err != nil {
+			logger.WithError(err).Warn("Failed to rewrite response")
+			continue
+		}
+		resp.Result, resp.Error = result, nil
+		logger.Info("Rewrote response")
+	}
+}
+
+// send writes a message to one side, after a delay if there is one
+func (s *session) send(ctx context.Context, to *endpoint, msg mcp.JSONRPCMessage, delay time.Duration) {
+	write := func() {
+		if err := to.write(ctx, msg); err != nil && ctx.Err() == nil {
+			s.proxy.logger.WithError(err).Warnf("Failed to forward message to %s", to.name)
+		}
+	}
+	if delay <= 0 {
+		write()
+		return
+	}
+
+	// Delayed messages do not hold up the ones behind them
+	s.delayed.Add(1)
+	go func() {
+		defer s.delayed.Done()
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+			write()
+		case <-ctx.Done():
+		}
+	}()
+}
+
+// describe returns the kind, method and tool of a message on its way to a side. Requests are
+// remembered so their responses can be matched by the method they answer.
+func (s *session) describe(msg mcp.JSONRPCMessage, to *endpoint) (kind, method, tool string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch m := msg.(type) {
+	case *mcp.JSONRPCRequest:
+		if !m.ID.IsValid() {
+			return KindNotification, m.Method, ""
+		}
+		c := call{method: m.Method, tool: toolName(m)}
+		s.pending[pendingKey{to: to.name, id: m.ID}] = c
+		return KindRequest, c.method, c.tool
+	case *mcp.JSONRPCResponse:
+		// A response answers a request that was sent the other way
+		key := pendingKey{to: s.other(to).name, id: m.ID}
+		c := s.pending[key]
+		delete(s.pending, key)
+		return KindResponse, c.method, c.tool
+	}
+	return "", "", ""
+}
+
+// answered forgets a request that the proxy answered itself or dropped
+func (s *session) answered(to *endpoint, id mcp.JSONRPCID) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pending, pendingKey{to: to.name, id: id})
+}
+
+// other returns the opposite side of a session
+func (s *session) other(e *endpoint) *endpoint {
+	if e == s.host {
+		return s.server
+	}
+	return s.host
+}
+
+// toolName returns the tool a tools/call request calls
+func toolName(req *mcp.JSONRPCRequest) string {
+	if req.Method != "tools/call" {
+		return ""
+	}
+	var params struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return ""
+	}
+	return params.Name
+}
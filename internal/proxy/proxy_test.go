@@ -0,0 +1,283 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/streamable"
+)
+
+type weatherParams struct {
+	Location string `json:"location"`
+}
+
+// weatherDialer returns a dialer that connects every session to a new in-memory weather server
+func weatherDialer(t *testing.T) Dialer {
+	server := mcp.NewServer("weather", "1.0.0", nil)
+	server.AddTools(mcp.NewServerTool("weather", "Report the weather", func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[weatherParams]) (*mcp.CallToolResultFor[any], error) {
+		return &mcp.CallToolResultFor[any]{
+			Content: []mcp.Content{&mcp.TextContent{Text: "Sunny in " + params.Arguments.Location}},
+		}, nil
+	}))
+
+	return func() (mcp.Transport, error) {
+		serverTransport, proxyTransport := mcp.NewInMemoryTransports()
+		session, err := server.Connect(context.Background(), serverTransport)
+		if err != nil {
+			return nil, err
+		}
+		t.Cleanup(func() { session.Close() })
+		return proxyTransport, nil
+	}
+}
+
+// quietLogger discards the proxy's logs
+func quietLogger() *logrus.Logger {
+	logger := logrus.New()
+	logger.SetOutput(io.Discard)
+	return logger
+}
+
+// connectThroughProxy serves one in-memory session through a proxy and returns the host's side
+func connectThroughProxy(t *testing.T, faults Faults) *mcp.ClientSession {
+	require.NoError(t, faults.Validate())
+	p := New("weather", weatherDialer(t), Options{Faults: faults, Seed: 1}, quietLogger())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	hostTransport, proxyTransport := mcp.NewInMemoryTransports()
+	served := make(chan error, 1)
+	go func() { served <- p.Serve(ctx, proxyTransport) }()
+
+	client := mcp.NewClient("test", "0.0.1", nil)
+	session, err := client.Connect(context.Background(), hostTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		// Stopping the proxy first closes the host's side, which lets the session close even
+		// with requests the proxy dropped still outstanding
+		cancel()
+		select {
+		case err := <-served:
+			assert.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			t.Error("proxy did not stop")
+		}
+		session.Close()
+	})
+	return session
+}
+
+// callWeather calls the weather tool and returns the text of its first content block
+func callWeather(ctx context.Context, session *mcp.ClientSession) (string, error) {
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "weather", Arguments: map[string]interface{}{"location": "Oslo"}})
+	if err != nil {
+		return "", err
+	}
+	if len(result.Content) == 0 {
+		return "", errors.New("no content")
+	}
+	return result.Content[0].(*mcp.TextContent).Text, nil
+}
+
+func TestProxyForwardsSession(t *testing.T) {
+	session := connectThroughProxy(t, Faults{})
+
+	tools, err := session.ListTools(context.Background(), &mcp.ListToolsParams{})
+	require.NoError(t, err)
+	require.Len(t, tools.Tools, 1)
+	assert.Equal(t, "weather", tools.Tools[0].Name)
+
+	text, err := callWeather(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, "Sunny in Oslo", text)
+}
+
+func TestProxyStopsWhenHostCloses(t *testing.T) {
+	p := New("weather", weatherDialer(t), Options{}, quietLogger())
+	hostTransport, proxyTransport := mcp.NewInMemoryTransports()
+	served := make(chan error, 1)
+	go func() { served <- p.Serve(context.Background(), proxyTransport) }()
+
+	session, err := mcp.NewClient("test", "0.0.1", nil).Connect(context.Background(), hostTransport)
+	require.NoError(t, err)
+	require.NoError(t, session.Ping(context.Background(), &mcp.PingParams{}))
+	require.NoError(t, session.Close())
+
+	select {
+	case err := <-served:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("proxy did not stop after the host closed")
+	}
+}
+
+func TestProxyRewritesResponses(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Rules: []Rule{
+		{Method: "tools/call", Tool: "weather", Replace: []Replacement{{Old: "Sunny", New: "Hail"}}},
+	}})
+
+	text, err := callWeather(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, "Hail in Oslo", text)
+}
+
+func TestProxyReplacesResult(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Rules: []Rule{
+		{Method: "tools/*", Result: map[string]interface{}{
+			"content": []interface{}{map[string]interface{}{"type": "text", "text": "rewritten"}},
+		}},
+	}})
+
+	text, err := callWeather(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, "rewritten", text)
+}
+
+func TestProxyAnswersRequestWithError(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Rules: []Rule{
+		{Method: "tools/call", On: KindRequest, Error: &Error{Message: "injected failure"}},
+	}})
+
+	_, err := callWeather(context.Background(), session)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "injected failure")
+
+	// Other requests still reach the server
+	require.NoError(t, session.Ping(context.Background(), &mcp.PingParams{}))
+}
+
+func TestProxyReplacesResponseWithError(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Rules: []Rule{
+		{Method: "tools/call", Error: &Error{Message: "server exploded"}},
+	}})
+
+	_, err := callWeather(context.Background(), session)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server exploded")
+}
+
+func TestProxyDropsMessages(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Rules: []Rule{
+		{Method: "tools/call", Tool: "weather", Drop: true},
+	}})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	_, err := callWeather(ctx, session)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, session.Ping(context.Background(), &mcp.PingParams{}))
+}
+
+// hostRequest returns the initialize request of an SDK client, as the proxy reads it from a host
+func hostRequest(t *testing.T) *mcp.JSONRPCRequest {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	hostTransport, proxyTransport := mcp.NewInMemoryTransports()
+	conn, err := proxyTransport.Connect(ctx)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() { _, _ = mcp.NewClient("test", "0.0.1", nil).Connect(ctx, hostTransport) }()
+
+	msg, err := conn.Read(ctx)
+	require.NoError(t, err)
+	return msg.(*mcp.JSONRPCRequest)
+}
+
+func TestProxyForgetsDroppedRequests(t *testing.T) {
+	faults := Faults{Rules: []Rule{{Method: "initialize", On: KindRequest, Drop: true}}}
+	require.NoError(t, faults.Validate())
+	p := New("weather", weatherDialer(t), Options{Faults: faults, Seed: 1}, quietLogger())
+	s := &session{proxy: p, host: &endpoint{name: "host"}, server: &endpoint{name: "server"}, pending: make(map[pendingKey]call)}
+
+	s.deliver(context.Background(), hostRequest(t), s.host, s.server)
+	assert.Empty(t, s.pending)
+}
+
+func TestProxyInjectsLatency(t *testing.T) {
+	session := connectThroughProxy(t, Faults{Latency: 100 * time.Millisecond})
+
+	start := time.Now()
+	_, err := callWeather(context.Background(), session)
+	require.NoError(t, err)
+	// Both the request and its response are delayed
+	assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+}
+
+func TestProxySkipsHandshakeForGlobalFaults(t *testing.T) {
+	// Dropping every message would make the session impossible to start if initialize was affected
+	session := connectThroughProxy(t, Faults{DropRate: 1})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	assert.Error(t, session.Ping(ctx, &mcp.PingParams{}))
+}
+
+func TestProxyRuleProbability(t *testing.T) {
+	p := New("weather", nil, Options{Seed: 1, Faults: Faults{Rules: []Rule{
+		{On: KindResponse, Probability: 0.5, Drop: true},
+	}}}, quietLogger())
+
+	dropped := 0
+	for i := 0; i < 1000; i++ {
+		if p.inject(KindResponse, "tools/call", "weather").drop {
+			dropped++
+		}
+	}
+	assert.InDelta(t, 500, dropped, 100)
+	assert.False(t, p.inject(KindRequest, "tools/call", "weather").drop)
+}
+
+func TestProxyHTTP(t *testing.T) {
+	faults := Faults{Rules: []Rule{{Tool: "weather", Replace: []Replacement{{Old: "Oslo", New: "Bergen"}}}}}
+	require.NoError(t, faults.Validate())
+	p := New("weather", weatherDialer(t), Options{Faults: faults}, quietLogger())
+
+	httpServer := httptest.NewServer(p.HTTPHandler())
+	defer httpServer.Close()
+
+	client := mcp.NewClient("test", "0.0.1", nil)
+	session, err := client.Connect(context.Background(), mcp.NewStreamableClientTransport(httpServer.URL, nil))
+	require.NoError(t, err)
+	defer session.Close()
+
+	text, err := callWeather(context.Background(), session)
+	require.NoError(t, err)
+	assert.Equal(t, "Sunny in Bergen", text)
+}
+
+func TestProxyHTTPUnknownSession(t *testing.T) {
+	handler := New("weather", weatherDialer(t), Options{}, quietLogger()).HTTPHandler()
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	request.Header.Set(streamable.HeaderSessionID, "missing")
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestProxyServerUnavailable(t *testing.T) {
+	p := New("weather", func() (mcp.Transport, error) {
+		return nil, errors.New("no such server")
+	}, Options{}, quietLogger())
+
+	_, proxyTransport := mcp.NewInMemoryTransports()
+	err := p.Serve(context.Background(), proxyTransport)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no such server")
+}
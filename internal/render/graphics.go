@@ -0,0 +1,209 @@
+package render
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF decoding
+	_ "image/jpeg" // register JPEG decoding
+	"image/png"
+	"io"
+	"os"
+	"strings"
+)
+
+// Graphics is a terminal protocol for displaying images inline
+type Graphics string
+
+const (
+	// GraphicsNone only saves images to files
+	GraphicsNone Graphics = "none"
+	// GraphicsKitty uses the kitty graphics protocol
+	GraphicsKitty Graphics = "kitty"
+	// GraphicsSixel uses DEC sixel graphics
+	GraphicsSixel Graphics = "sixel"
+)
+
+// maxInlineWidth bounds the width of inline images in pixels
+const maxInlineWidth = 800
+
+// kittyChunkSize is the largest base64 payload the kitty protocol accepts per escape sequence
+const kittyChunkSize = 4096
+
+// ParseGraphics converts a flag value into a Graphics protocol; "auto" detects it
+func ParseGraphics(value string) (Graphics, error) {
+	switch graphics := Graphics(strings.ToLower(value)); graphics {
+	case "auto":
+		return DetectGraphics(), nil
+	case GraphicsNone, GraphicsKitty, GraphicsSixel:
+		return graphics, nil
+	}
+	return "", fmt.Errorf("invalid image protocol %q (expected auto, %s, %s or %s)", value, GraphicsNone, GraphicsKitty, GraphicsSixel)
+}
+
+// DetectGraphics guesses which inline image protocol the terminal supports from its environment
+func DetectGraphics() Graphics {
+	term := os.Getenv("TERM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || term == "xterm-ghostty":
+		return GraphicsKitty
+	case os.Getenv("TERM_PROGRAM") == "WezTerm":
+		return GraphicsKitty
+	case strings.Contains(term, "sixel") || term == "mlterm" || term == "foot" || term == "foot-extra":
+		return GraphicsSixel
+	}
+	return GraphicsNone
+}
+
+// writeInlineImage displays an encoded image using the given protocol
+func writeInlineImage(w io.Writer, graphics Graphics, data []byte) error {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("unsupported image: %w", err)
+	}
+	img = limitWidth(img, maxInlineWidth)
+
+	switch graphics {
+	case GraphicsKitty:
+		return writeKitty(w, img)
+	case GraphicsSixel:
+		return writeSixel(w, img)
+	}
+	return nil
+}
+
+// limitWidth scales an image down with nearest-neighbour sampling if it is wider than maxWidth
+func limitWidth(img image.Image, maxWidth int) image.Image {
+	bounds := img.Bounds()
+	if bounds.Dx() <= maxWidth {
+		return img
+	}
+
+	height := bounds.Dy() * maxWidth / bounds.Dx()
+	if height == 0 {
+		height = 1
+	}
+	scaled := image.NewRGBA(image.Rect(0, 0, maxWidth, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < maxWidth; x++ {
+			sx := bounds.Min.X + x*bounds.Dx()/maxWidth
+			sy := bounds.Min.Y + y*bounds.Dy()/height
+			scaled.Set(x, y, img.At(sx, sy))
+		}
+	}
+	return scaled
+}
+
+// writeKitty transmits an image as PNG using the kitty graphics protocol
+func writeKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return fmt.Errorf("failed to encode image: %w", err)
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	for first := true; ; first = false {
+		chunk := payload
+		if len(chunk) > kittyChunkSize {
+			chunk = chunk[:kittyChunkSize]
+		}
+		payload = payload[len(chunk):]
+
+		more := 0
+		if payload != "" {
+			more = 1
+		}
+		control := fmt.Sprintf("m=%d", more)
+		if first {
+			control = "f=100,a=T," + control
+		}
+		if _, err := fmt.Fprintf(w, "\x1b_G%s;%s\x1b\\", control, chunk); err != nil {
+			return err
+		}
+		if payload == "" {
+			return nil
+		}
+	}
+}
+
+// writeSixel encodes an image as sixels using a 6x6x6 colour cube palette
+func writeSixel(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "\x1bPq\"1;1;%d;%d", width, height)
+	for i := 0; i < 216; i++ {
+		fmt.Fprintf(&buf, "#%d;2;%d;%d;%d", i, i/36*20, i/6%6*20, i%6*20)
+	}
+
+	// Palette index of every pixel, or -1 for transparent pixels
+	indexes := make([]int, width*height)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			r, g, b, a := img.At(bounds.Min.X+x, bounds.Min.Y+y).RGBA()
+			if a < 0x8000 {
+				indexes[y*width+x] = -1
+				continue
+			}
+			indexes[y*width+x] = cubeLevel(r)*36 + cubeLevel(g)*6 + cubeLevel(b)
+		}
+	}
+
+	row := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		used := make(map[int]bool)
+		for y := top; y < top+6 && y < height; y++ {
+			for x := 0; x < width; x++ {
+				if index := indexes[y*width+x]; index >= 0 {
+					used[index] = true
+				}
+			}
+		}
+
+		for color := 0; color < 216; color++ {
+			if !used[color] {
+				continue
+			}
+			for x := 0; x < width; x++ {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if indexes[(top+dy)*width+x] == color {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = '?' + bits
+			}
+			fmt.Fprintf(&buf, "#%d", color)
+			writeSixelRow(&buf, row)
+			buf.WriteByte('$')
+		}
+		buf.WriteByte('-')
+	}
+	buf.WriteString("\x1b\\")
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// writeSixelRow writes one colour of a sixel band with run-length encoding
+func writeSixelRow(buf *bytes.Buffer, row []byte) {
+	for i := 0; i < len(row); {
+		j := i
+		for j < len(row) && row[j] == row[i] {
+			j++
+		}
+		if run := j - i; run > 3 {
+			fmt.Fprintf(buf, "!%d%c", run, row[i])
+		} else {
+			buf.Write(row[i:j])
+		}
+		i = j
+	}
+}
+
+// cubeLevel maps a 16-bit colour channel onto the six levels of the colour cube
+func cubeLevel(v uint32) int {
+	return int((v>>8)*5+127) / 255
+}
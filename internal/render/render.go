@@ -0,0 +1,281 @@
+// Package render prints tool results for people reading them in a terminal
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// mediaDirName is the directory under the system temp directory where blobs are saved by default
+const mediaDirName = "mcp_tstr-media"
+
+// ANSI escape sequences used to highlight output on terminals
+const (
+	ansiReset   = "\x1b[0m"
+	ansiBoldRed = "\x1b[1;31m"
+	ansiRed     = "\x1b[31m"
+	ansiDim     = "\x1b[2m"
+)
+
+// Renderer prints CallToolResult content blocks. Text is printed as is, image and audio blobs are
+// saved to files (and images shown inline when the terminal supports it), and embedded resources
+// and resource links are expanded.
+type Renderer struct {
+	out      io.Writer
+	mediaDir string
+	graphics Graphics
+	color    bool
+}
+
+// New creates a renderer writing to out. Blobs are saved under mediaDir, or a directory in the
+// system temp directory if it is empty. Colors and inline images are enabled when out is a terminal.
+func New(out io.Writer, mediaDir string) *Renderer {
+	if mediaDir == "" {
+		mediaDir = filepath.Join(os.TempDir(), mediaDirName)
+	}
+
+	r := &Renderer{out: out, mediaDir: mediaDir, graphics: GraphicsNone}
+	if isTerminal(out) {
+		r.color = os.Getenv("NO_COLOR") == ""
+		r.graphics = DetectGraphics()
+	}
+	return r
+}
+
+// SetGraphics overrides the detected inline image protocol
+func (r *Renderer) SetGraphics(graphics Graphics) {
+	r.graphics = graphics
+}
+
+// Result prints every content block of a tool result, followed by its structured content
+func (r *Renderer) Result(result *sdk.CallToolResult) error {
+	if result.IsError {
+		r.styled(ansiBoldRed, "Tool returned an error (isError: true)")
+	}
+
+	for _, content := range result.Content {
+		if err := r.content(content, result.IsError); err != nil {
+			return err
+		}
+	}
+
+	if result.StructuredContent != nil {
+		data, err := json.MarshalIndent(result.StructuredContent, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to format structured content: %w", err)
+		}
+		r.styled(ansiDim, "Structured content:")
+		fmt.Fprintln(r.out, string(data))
+	}
+	return nil
+}
+
+// content prints a single content block
+func (r *Renderer) content(content sdk.Content, isError bool) error {
+	switch c := content.(type) {
+	case *sdk.TextContent:
+		if isError {
+			r.styled(ansiRed, c.Text)
+		} else {
+			fmt.Fprintln(r.out, c.Text)
+		}
+	case *sdk.ImageContent:
+		return r.image(c.Data, c.MIMEType)
+	case *sdk.AudioContent:
+		path, err := r.save("audio", c.MIMEType, c.Data)
+		if err != nil {
+			return err
+		}
+		r.styled(ansiDim, fmt.Sprintf("[audio %s, %s saved to %s]", c.MIMEType, formatSize(len(c.Data)), path))
+	case *sdk.ResourceLink:
+		r.resourceLink(c)
+	case *sdk.EmbeddedResource:
+		return r.embeddedResource(c.Resource)
+	default:
+		data, err := json.Marshal(content)
+		if err != nil {
+			return fmt.Errorf("failed to format content: %w", err)
+		}
+		fmt.Fprintln(r.out, string(data))
+	}
+	return nil
+}
+
+// image saves an image and shows it inline when the terminal supports it
+func (r *Renderer) image(data []byte, mimeType string) error {
+	path, err := r.save("image", mimeType, data)
+	if err != nil {
+		return err
+	}
+	r.styled(ansiDim, fmt.Sprintf("[image %s, %s saved to %s]", mimeType, formatSize(len(data)), path))
+
+	if r.graphics != GraphicsNone {
+		if err := writeInlineImage(r.out, r.graphics, data); err != nil {
+			r.styled(ansiDim, fmt.Sprintf("[cannot display image inline: %v]", err))
+			return nil
+		}
+		fmt.Fprintln(r.out)
+	}
+	return nil
+}
+
+// resourceLink prints what a resource link points to
+func (r *Renderer) resourceLink(link *sdk.ResourceLink) {
+	name := link.Title
+	if name == "" {
+		name = link.Name
+	}
+
+	details := []string{link.URI}
+	if link.MIMEType != "" {
+		details = append(details, link.MIMEType)
+	}
+	if link.Size != nil {
+		details = append(details, formatSize(int(*link.Size)))
+	}
+
+	r.styled(ansiDim, fmt.Sprintf("[resource link] %s (%s)", name, strings.Join(details, ", ")))
+	if link.Description != "" {
+		fmt.Fprintf(r.out, "  %s\n", link.Description)
+	}
+}
+
+// embeddedResource prints the text of an embedded resource, or saves its blob
+func (r *Renderer) embeddedResource(resource *sdk.ResourceContents) error {
+	if resource == nil {
+		r.styled(ansiDim, "[embedded resource without contents]")
+		return nil
+	}
+
+	header := "[resource] " + resource.URI
+	if resource.MIMEType != "" {
+		header += " (" + resource.MIMEType + ")"
+	}
+
+	if resource.Blob != nil {
+		if strings.HasPrefix(resource.MIMEType, "image/") {
+			r.styled(ansiDim, header)
+			return r.image(resource.Blob, resource.MIMEType)
+		}
+		path, err := r.save("resource", resource.MIMEType, resource.Blob)
+		if err != nil {
+			return err
+		}
+		r.styled(ansiDim, fmt.Sprintf("%s %s saved to %s", header, formatSize(len(resource.Blob)), path))
+		return nil
+	}
+
+	r.styled(ansiDim, header)
+	fmt.Fprintln(r.out, resource.Text)
+	return nil
+}
+
+// save writes a blob to a new file in the media directory and returns its path
+func (r *Renderer) save(kind, mimeType string, data []byte) (string, error) {
+	if err := os.MkdirAll(r.mediaDir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create media directory: %w", err)
+	}
+
+	pattern := fmt.Sprintf("%s-%s-*%s", kind, time.Now().Format("20060102-150405"), extension(mimeType))
+	file, err := os.CreateTemp(r.mediaDir, pattern)
+	if err != nil {
+		return "", fmt.Errorf("failed to create %s file: %w", kind, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(data); err != nil {
+		return "", fmt.Errorf("failed to save %s: %w", kind, err)
+	}
+	return file.Name(), nil
+}
+
+// styled prints a line, wrapped in an ANSI style when colors are enabled
+func (r *Renderer) styled(style, line string) {
+	if r.color {
+		fmt.Fprintf(r.out, "%s%s%s\n", style, line, ansiReset)
+		return
+	}
+	fmt.Fprintln(r.out, line)
+}
+
+// commonExtensions picks the usual extension where the mime package knows several
+var commonExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"audio/mpeg": ".mp3",
+	"audio/wav":  ".wav",
+	"text/plain": ".txt",
+}
+
+// extension returns a file extension for a MIME type
+func extension(mimeType string) string {
+	mediaType, _, err := mime.ParseMediaType(mimeType)
+	if err != nil {
+		return ".bin"
+	}
+	if ext, ok := commonExtensions[mediaType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// formatSize renders a byte count for people
+func formatSize(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// isTerminal reports whether w is a character device such as a terminal
+func isTerminal(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// PlainText describes a content block as plain text, for consumers such as models that cannot
+// use the blob itself
+func PlainText(content sdk.Content) string {
+	switch c := content.(type) {
+	case *sdk.TextContent:
+		return c.Text
+	case *sdk.ImageContent:
+		return fmt.Sprintf("[image %s, %s]", c.MIMEType, formatSize(len(c.Data)))
+	case *sdk.AudioContent:
+		return fmt.Sprintf("[audio %s, %s]", c.MIMEType, formatSize(len(c.Data)))
+	case *sdk.ResourceLink:
+		return fmt.Sprintf("[resource link %s: %s] %s", c.Name, c.URI, c.Description)
+	case *sdk.EmbeddedResource:
+		if c.Resource == nil {
+			return "[embedded resource]"
+		}
+		if c.Resource.Blob != nil {
+			return fmt.Sprintf("[resource %s, %s, %s]", c.Resource.URI, c.Resource.MIMEType, formatSize(len(c.Resource.Blob)))
+		}
+		return fmt.Sprintf("[resource %s]\n%s", c.Resource.URI, c.Resource.Text)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return fmt.Sprintf("%v", content)
+	}
+	return string(data)
+}
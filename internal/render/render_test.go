@@ -0,0 +1,131 @@
+package render
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"strings"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testPNG encodes a small two-colour image
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 7))
+	for y := 0; y < 7; y++ {
+		for x := 0; x < 4; x++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	img.Set(0, 6, color.RGBA{B: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestRenderText(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, t.TempDir())
+
+	require.NoError(t, r.Result(&sdk.CallToolResult{
+		Content:           []sdk.Content{&sdk.TextContent{Text: "hello"}, &sdk.TextContent{Text: "world"}},
+		StructuredContent: map[string]interface{}{"count": 2},
+	}))
+	assert.Equal(t, "hello\nworld\nStructured content:\n{\n  \"count\": 2\n}\n", out.String())
+}
+
+func TestRenderError(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, t.TempDir())
+
+	require.NoError(t, r.Result(&sdk.CallToolResult{
+		Content: []sdk.Content{&sdk.TextContent{Text: "file not found"}},
+		IsError: true,
+	}))
+	assert.Equal(t, "Tool returned an error (isError: true)\nfile not found\n", out.String())
+
+	out.Reset()
+	r.color = true
+	require.NoError(t, r.Result(&sdk.CallToolResult{
+		Content: []sdk.Content{&sdk.TextContent{Text: "file not found"}},
+		IsError: true,
+	}))
+	assert.Contains(t, out.String(), ansiRed+"file not found"+ansiReset)
+}
+
+func TestRenderSavesBlobs(t *testing.T) {
+	var out bytes.Buffer
+	dir := t.TempDir()
+	r := New(&out, dir)
+	data := testPNG(t)
+
+	require.NoError(t, r.Result(&sdk.CallToolResult{
+		Content: []sdk.Content{
+			&sdk.ImageContent{Data: data, MIMEType: "image/png"},
+			&sdk.AudioContent{Data: []byte("RIFF"), MIMEType: "audio/wav"},
+		},
+	}))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.True(t, strings.HasPrefix(names[0], "audio-") && strings.HasSuffix(names[0], ".wav"), names[0])
+	assert.True(t, strings.HasPrefix(names[1], "image-") && strings.HasSuffix(names[1], ".png"), names[1])
+
+	saved, err := os.ReadFile(dir + "/" + names[1])
+	require.NoError(t, err)
+	assert.Equal(t, data, saved)
+	assert.Contains(t, out.String(), "[image image/png, ")
+	assert.Contains(t, out.String(), "[audio audio/wav, 4 bytes saved to ")
+}
+
+func TestRenderResources(t *testing.T) {
+	var out bytes.Buffer
+	r := New(&out, t.TempDir())
+
+	require.NoError(t, r.Result(&sdk.CallToolResult{
+		Content: []sdk.Content{
+			&sdk.EmbeddedResource{Resource: &sdk.ResourceContents{URI: "file:///notes.txt", MIMEType: "text/plain", Text: "remember the milk"}},
+			&sdk.ResourceLink{URI: "file:///report.pdf", Name: "report", Description: "Monthly report", MIMEType: "application/pdf"},
+		},
+	}))
+	assert.Equal(t, "[resource] file:///notes.txt (text/plain)\nremember the milk\n"+
+		"[resource link] report (file:///report.pdf, application/pdf)\n  Monthly report\n", out.String())
+}
+
+func TestInlineImages(t *testing.T) {
+	data := testPNG(t)
+
+	var kitty bytes.Buffer
+	require.NoError(t, writeInlineImage(&kitty, GraphicsKitty, data))
+	assert.True(t, strings.HasPrefix(kitty.String(), "\x1b_Gf=100,a=T,m=0;"))
+	assert.True(t, strings.HasSuffix(kitty.String(), "\x1b\\"))
+
+	var sixel bytes.Buffer
+	require.NoError(t, writeInlineImage(&sixel, GraphicsSixel, data))
+	assert.True(t, strings.HasPrefix(sixel.String(), "\x1bPq\"1;1;4;7"))
+	assert.True(t, strings.HasSuffix(sixel.String(), "-\x1b\\"))
+	// Red fills the first band and all but one pixel of the second
+	assert.Contains(t, sixel.String(), "#180!4~$")
+	assert.Contains(t, sixel.String(), "#5@???$")
+
+	assert.Error(t, writeInlineImage(&sixel, GraphicsSixel, []byte("not an image")))
+}
+
+func TestPlainText(t *testing.T) {
+	assert.Equal(t, "hi", PlainText(&sdk.TextContent{Text: "hi"}))
+	assert.Equal(t, "[image image/png, 3 bytes]", PlainText(&sdk.ImageContent{Data: []byte("abc"), MIMEType: "image/png"}))
+	assert.Equal(t, "[resource file:///a]\ntext", PlainText(&sdk.EmbeddedResource{Resource: &sdk.ResourceContents{URI: "file:///a", Text: "text"}}))
+}
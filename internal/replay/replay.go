@@ -0,0 +1,456 @@
+// Package replay re-sends the client requests of a recording to live servers and compares
+// their responses with the recorded ones
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcp"
+)
+
+// Exchange outcomes
+const (
+	StatusMatch  = "match"
+	StatusDiffer = "differ"
+	StatusError  = "error"
+)
+
+// Dialer opens a new raw connection to a server of the recording
+type Dialer func(server string) (conformance.Conn, error)
+
+// Options controls a replay
+type Options struct {
+	// Timeout bounds each request
+	Timeout time.Duration
+	// Ignore lists response paths, such as result.serverInfo.version or result.content[*].text,
+	// whose differences are not reported. A path also ignores everything below it.
+	Ignore []string
+	// Servers limits the replay to these servers when not empty
+	Servers []string
+}
+
+// Difference is a value that differs between the recorded and the replayed response. A value
+// missing from one of them is empty.
+type Difference struct {
+	Path     string `json:"path"`
+	Recorded string `json:"recorded,omitempty"`
+	Replayed string `json:"replayed,omitempty"`
+}
+
+// Exchange is the outcome of replaying one request
+type Exchange struct {
+	Server      string       `json:"server"`
+	Method      string       `json:"method"`
+	Status      string       `json:"status"`
+	DurationMS  float64      `json:"duration_ms"`
+	Error       string       `json:"error,omitempty"`
+	Differences []Difference `json:"differences,omitempty"`
+}
+
+// Report is the outcome of a replay
+type Report struct {
+	Matched   int        `json:"matched"`
+	Differed  int        `json:"differed"`
+	Failed    int        `json:"failed"`
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// Mismatches returns the number of requests that differed or failed
+func (r *Report) Mismatches() int {
+	return r.Differed + r.Failed
+}
+
+// wireMessage is a recorded JSON-RPC message
+type wireMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *jsonrpc.Error  `json:"error,omitempty"`
+}
+
+// recorded is a recorded message decoded for replay
+type recorded struct {
+	mcp.RecordedMessage
+	wire wireMessage
+}
+
+// Servers returns the servers of a recording in the order they first appear
+func Servers(messages []mcp.RecordedMessage) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, msg := range messages {
+		if !seen[msg.Server] {
+			seen[msg.Server] = true
+			servers = append(servers, msg.Server)
+		}
+	}
+	return servers
+}
+
+// Run replays the client requests and notifications of every server in the recording, one
+// server at a time and in recorded order, and compares each response with the recorded one.
+// Server requests and client responses to them are not replayed; the connection answers server
+// pings itself.
+func Run(ctx context.Context, messages []mcp.RecordedMessage, dial Dialer, opts Options) (*Report, error) {
+	ignore, err := compileIgnore(opts.Ignore)
+	if err != nil {
+		return nil, err
+	}
+
+	servers := Servers(messages)
+	if len(opts.Servers) > 0 {
+		servers = opts.Servers
+	}
+
+	report := &Report{Exchanges: []Exchange{}}
+	for _, server := range servers {
+		var decoded []recorded
+		for i, msg := range messages {
+			if msg.Server != server {
+				continue
+			}
+			r := recorded{RecordedMessage: msg}
+			if err := json.Unmarshal(msg.Message, &r.wire); err != nil {
+				return nil, fmt.Errorf("message %d: %w", i+1, err)
+			}
+			decoded = append(decoded, r)
+		}
+		if len(decoded) == 0 {
+			return nil, fmt.Errorf("server %s does not appear in the recording", server)
+		}
+
+		r := &replayer{server: server, dial: dial, opts: opts, ignore: ignore, report: report}
+		r.run(ctx, decoded)
+	}
+	return report, nil
+}
+
+// replayer replays the messages of one server
+type replayer struct {
+	server string
+	dial   Dialer
+	opts   Options
+	ignore []*regexp.Regexp
+	report *Report
+	conn   conformance.Conn
+	// initialized is set once an initialize request has been sent on conn
+	initialized bool
+}
+
+// run replays the recorded messages of the server in order
+func (r *replayer) run(ctx context.Context, messages []recorded) {
+	defer r.close()
+
+	for i, msg := range messages {
+		if msg.Direction != mcp.DirectionSend || msg.wire.Method == "" {
+			continue
+		}
+		// Cancellations refer to request IDs of the recorded session
+		if msg.wire.Method == "notifications/cancelled" {
+			continue
+		}
+
+		// A new initialize means the client reconnected, so the replay does too
+		if msg.wire.Method == "initialize" && r.initialized {
+			r.close()
+		}
+		if r.conn == nil {
+			conn, err := r.dial(r.server)
+			if err != nil {
+				r.add(Exchange{Server: r.server, Method: msg.wire.Method, Status: StatusError, Error: fmt.Sprintf("failed to connect: %v", err)})
+				return
+			}
+			r.conn = conn
+			r.initialized = false
+		}
+
+		var params interface{}
+		if len(msg.wire.Params) > 0 {
+			params = msg.wire.Params
+		}
+
+		if len(msg.wire.ID) == 0 {
+			if err := r.conn.Notify(ctx, msg.wire.Method, params); err != nil {
+				r.add(Exchange{Server: r.server, Method: msg.wire.Method, Status: StatusError, Error: err.Error()})
+			}
+			continue
+		}
+
+		r.add(r.call(ctx, msg, params, findResponse(messages[i+1:], msg.wire.ID)))
+	}
+}
+
+// call sends a recorded request and compares the response with the recorded one
+func (r *replayer) call(ctx context.Context, msg recorded, params interface{}, expected *wireMessage) Exchange {
+	exchange := Exchange{Server: r.server, Method: msg.wire.Method}
+	if msg.wire.Method == "initialize" {
+		r.initialized = true
+	}
+
+	callCtx := ctx
+	if r.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(ctx, r.opts.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	response, err := r.conn.Call(callCtx, msg.wire.Method, params)
+	exchange.DurationMS = float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		exchange.Status = StatusError
+		exchange.Error = err.Error()
+		return exchange
+	}
+
+	if msg.wire.Method == "initialize" && response.Error == nil {
+		r.setProtocolVersion(response.Result)
+	}
+
+	exchange.Status = StatusMatch
+	if expected == nil {
+		// The recorded session ended before the response arrived
+		return exchange
+	}
+	exchange.Differences = r.compare(responseValue(expected.Result, expected.Error), responseValue(response.Result, response.Error))
+	if len(exchange.Differences) > 0 {
+		exchange.Status = StatusDiffer
+	}
+	return exchange
+}
+
+// setProtocolVersion passes the negotiated version to transports that send it with each request
+func (r *replayer) setProtocolVersion(result json.RawMessage) {
+	setter, ok := r.conn.(interface{ SetProtocolVersion(string) })
+	if !ok {
+		return
+	}
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+	}
+	if json.Unmarshal(result, &init) == nil && init.ProtocolVersion != "" {
+		setter.SetProtocolVersion(init.ProtocolVersion)
+	}
+}
+
+// add records the outcome of a request
+func (r *replayer) add(exchange Exchange) {
+	switch exchange.Status {
+	case StatusMatch:
+		r.report.Matched++
+	case StatusDiffer:
+		r.report.Differed++
+	default:
+		r.report.Failed++
+	}
+	r.report.Exchanges = append(r.report.Exchanges, exchange)
+}
+
+// close closes the current connection
+func (r *replayer) close() {
+	if r.conn != nil {
+		_ = r.conn.Close()
+		r.conn = nil
+	}
+}
+
+// findResponse returns the first recorded response to the request with the given ID
+func findResponse(messages []recorded, id json.RawMessage) *wireMessage {
+	for _, msg := range messages {
+		if msg.Direction == mcp.DirectionReceive && msg.wire.Method == "" && sameID(msg.wire.ID, id) {
+			return &msg.wire
+		}
+	}
+	return nil
+}
+
+// sameID compares two JSON-RPC IDs
+func sameID(a, b json.RawMessage) bool {
+	return bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b))
+}
+
+// responseValue decodes a response into a generic value so its paths start with result or error
+func responseValue(result json.RawMessage, rpcErr *jsonrpc.Error) interface{} {
+	if rpcErr != nil {
+		value := map[string]interface{}{"code": float64(rpcErr.Code), "message": rpcErr.Message}
+		if len(rpcErr.Data) > 0 {
+			value["data"] = decode(rpcErr.Data)
+		}
+		return map[string]interface{}{"error": value}
+	}
+	return map[string]interface{}{"result": decode(result)}
+}
+
+// decode parses JSON into a generic value, keeping invalid JSON as a string
+func decode(data json.RawMessage) interface{} {
+	var value interface{}
+	if len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return string(data)
+	}
+	return value
+}
+
+// compare lists the differences between two decoded responses that are not ignored
+func (r *replayer) compare(recorded, replayed interface{}) []Difference {
+	var differences []Difference
+	diff("", recorded, replayed, func(path string, a, b interface{}, aOK, bOK bool) {
+		if ignored(r.ignore, path) {
+			return
+		}
+		d := Difference{Path: path}
+		if aOK {
+			d.Recorded = show(a)
+		}
+		if bOK {
+			d.Replayed = show(b)
+		}
+		differences = append(differences, d)
+	})
+	return differences
+}
+
+// diff walks two values and reports every path where they differ. aOK and bOK are false when
+// the value is missing on that side.
+func diff(path string, a, b interface{}, report func(path string, a, b interface{}, aOK, bOK bool)) {
+	switch a := a.(type) {
+	case map[string]interface{}:
+		if b, ok := b.(map[string]interface{}); ok {
+			keys := make(map[string]bool, len(a)+len(b))
+			for key := range a {
+				keys[key] = true
+			}
+			for key := range b {
+				keys[key] = true
+			}
+			names := make([]string, 0, len(keys))
+			for key := range keys {
+				names = append(names, key)
+			}
+			sort.Strings(names)
+
+			for _, key := range names {
+				av, aOK := a[key]
+				bv, bOK := b[key]
+				child := joinPath(path, key)
+				if !aOK || !bOK {
+					report(child, av, bv, aOK, bOK)
+					continue
+				}
+				diff(child, av, bv, report)
+			}
+			return
+		}
+	case []interface{}:
+		if b, ok := b.([]interface{}); ok {
+			for i := 0; i < len(a) || i < len(b); i++ {
+				child := path + "[" + strconv.Itoa(i) + "]"
+				switch {
+				case i >= len(b):
+					report(child, a[i], nil, true, false)
+				case i >= len(a):
+					report(child, nil, b[i], false, true)
+				default:
+					diff(child, a[i], b[i], report)
+				}
+			}
+			return
+		}
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		report(path, a, b, true, true)
+	}
+}
+
+// joinPath appends an object key to a path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// compileIgnore turns ignored paths into patterns matching the path and everything below it,
+// where [*] matches any index
+func compileIgnore(paths []string) ([]*regexp.Regexp, error) {
+	patterns := make([]*regexp.Regexp, 0, len(paths))
+	for _, path := range paths {
+		path = strings.TrimPrefix(strings.TrimSpace(path), "$.")
+		if path == "" {
+			return nil, fmt.Errorf("empty ignore path")
+		}
+		quoted := strings.ReplaceAll(regexp.QuoteMeta(path), `\[\*\]`, `\[\d+\]`)
+		patterns = append(patterns, regexp.MustCompile(`^`+quoted+`($|[.\[])`))
+	}
+	return patterns, nil
+}
+
+// ignored reports whether a path matches one of the ignore patterns
+func ignored(patterns []*regexp.Regexp, path string) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// show renders a value as compact JSON for reports
+func show(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	const limit = 120
+	if len(data) > limit {
+		return string(data[:limit]) + "..."
+	}
+	return string(data)
+}
+
+// WriteText writes one line per replayed request, followed by its differences
+func (r *Report) WriteText(w io.Writer) error {
+	labels := map[string]string{StatusMatch: "MATCH", StatusDiffer: "DIFF", StatusError: "ERROR"}
+	for _, exchange := range r.Exchanges {
+		fmt.Fprintf(w, "%-6s %s  %s (%s)\n", labels[exchange.Status], exchange.Server, exchange.Method, time.Duration(exchange.DurationMS*float64(time.Millisecond)).Round(time.Millisecond))
+		if exchange.Error != "" {
+			fmt.Fprintf(w, "       %s\n", exchange.Error)
+		}
+		for _, d := range exchange.Differences {
+			recorded, replayed := d.Recorded, d.Replayed
+			if recorded == "" {
+				recorded = "(missing)"
+			}
+			if replayed == "" {
+				replayed = "(missing)"
+			}
+			fmt.Fprintf(w, "       %s: recorded %s, replayed %s\n", d.Path, recorded, replayed)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d matched, %d differed, %d failed\n", r.Matched, r.Differed, r.Failed)
+	return err
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,175 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/conformance"
+	"mcp_tstr/internal/jsonrpc"
+	"mcp_tstr/internal/mcptest"
+)
+
+// stubConn answers calls from a table of results by method
+type stubConn struct {
+	results map[string]string
+	errors  map[string]*jsonrpc.Error
+	sent    []string
+	version string
+	closed  bool
+}
+
+func (c *stubConn) Call(ctx context.Context, method string, params interface{}) (*conformance.Response, error) {
+	c.sent = append(c.sent, method)
+	if err, ok := c.errors[method]; ok {
+		return &conformance.Response{Error: err}, nil
+	}
+	result, ok := c.results[method]
+	if !ok {
+		return nil, errors.New("connection closed")
+	}
+	return &conformance.Response{Result: json.RawMessage(result)}, nil
+}
+
+func (c *stubConn) Notify(ctx context.Context, method string, params interface{}) error {
+	c.sent = append(c.sent, method)
+	return nil
+}
+
+func (c *stubConn) SetProtocolVersion(version string) { c.version = version }
+func (c *stubConn) Violations() []string              { return nil }
+func (c *stubConn) Notifications() []string           { return nil }
+func (c *stubConn) Close() error                      { c.closed = true; return nil }
+
+var session = []string{
+	`fake send {"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+	`fake receive {"jsonrpc":"2.0","id":1,"result":{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"1.0"}}}`,
+	`fake send {"jsonrpc":"2.0","method":"notifications/initialized","params":{}}`,
+	`fake send {"jsonrpc":"2.0","id":2,"method":"tools/list","params":{}}`,
+	`fake receive {"jsonrpc":"2.0","id":2,"result":{"tools":[{"name":"echo"},{"name":"grow"}]}}`,
+	`fake send {"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"ghost"}}`,
+	`fake receive {"jsonrpc":"2.0","id":3,"error":{"code":-32602,"message":"unknown tool"}}`,
+}
+
+func TestRunMatches(t *testing.T) {
+	conn := &stubConn{
+		results: map[string]string{
+			"initialize": `{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"1.0"}}`,
+			"tools/list": `{"tools":[{"name":"echo"},{"name":"grow"}]}`,
+		},
+		errors: map[string]*jsonrpc.Error{"tools/call": {Code: -32602, Message: "unknown tool"}},
+	}
+	dial := func(server string) (conformance.Conn, error) {
+		assert.Equal(t, "fake", server)
+		return conn, nil
+	}
+
+	report, err := Run(context.Background(), mcptest.Recording(t, session...), dial, Options{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, report.Matched)
+	assert.Zero(t, report.Mismatches())
+	assert.Equal(t, []string{"initialize", "notifications/initialized", "tools/list", "tools/call"}, conn.sent)
+	assert.Equal(t, "2025-06-18", conn.version)
+	assert.True(t, conn.closed)
+}
+
+func TestRunReportsDifferences(t *testing.T) {
+	conn := &stubConn{
+		results: map[string]string{
+			"initialize": `{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"2.0"}}`,
+			"tools/list": `{"tools":[{"name":"echo","description":"Echo"}]}`,
+			"tools/call": `{"content":[]}`,
+		},
+	}
+	dial := func(string) (conformance.Conn, error) { return conn, nil }
+
+	report, err := Run(context.Background(), mcptest.Recording(t, session...), dial, Options{Ignore: []string{"result.serverInfo.version"}})
+	require.NoError(t, err)
+
+	require.Len(t, report.Exchanges, 3)
+	assert.Equal(t, StatusMatch, report.Exchanges[0].Status)
+	assert.Equal(t, StatusDiffer, report.Exchanges[1].Status)
+	assert.Equal(t, []Difference{
+		{Path: "result.tools[0].description", Replayed: `"Echo"`},
+		{Path: "result.tools[1]", Recorded: `{"name":"grow"}`},
+	}, report.Exchanges[1].Differences)
+	assert.Equal(t, []Difference{
+		{Path: "error", Recorded: `{"code":-32602,"message":"unknown tool"}`},
+		{Path: "result", Replayed: `{"content":[]}`},
+	}, report.Exchanges[2].Differences)
+	assert.Equal(t, 2, report.Differed)
+
+	var out bytes.Buffer
+	require.NoError(t, report.WriteText(&out))
+	assert.Contains(t, out.String(), "DIFF   fake  tools/list")
+	assert.Contains(t, out.String(), `result.tools[1]: recorded {"name":"grow"}, replayed (missing)`)
+	assert.Contains(t, out.String(), "1 matched, 2 differed, 0 failed")
+}
+
+func TestRunIgnoreWildcards(t *testing.T) {
+	conn := &stubConn{results: map[string]string{
+		"initialize": `{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"1.0"}}`,
+		"tools/list": `{"tools":[{"name":"echo2"},{"name":"grow2"}]}`,
+		"tools/call": `{}`,
+	}}
+	dial := func(string) (conformance.Conn, error) { return conn, nil }
+
+	report, err := Run(context.Background(), mcptest.Recording(t, session[:5]...), dial, Options{Ignore: []string{"result.tools[*].name"}})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Matched)
+}
+
+func TestRunReconnectsOnInitialize(t *testing.T) {
+	var conns []*stubConn
+	dial := func(string) (conformance.Conn, error) {
+		conn := &stubConn{results: map[string]string{
+			"initialize": `{"protocolVersion":"2025-06-18","serverInfo":{"name":"fake","version":"1.0"}}`,
+		}}
+		conns = append(conns, conn)
+		return conn, nil
+	}
+
+	messages := mcptest.Recording(t, append(session[:2:2], session[:2]...)...)
+	report, err := Run(context.Background(), messages, dial, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Matched)
+	require.Len(t, conns, 2)
+	assert.True(t, conns[0].closed)
+}
+
+func TestRunFailures(t *testing.T) {
+	// No result for tools/list makes the stub fail the call
+	conn := &stubConn{results: map[string]string{"initialize": `{}`}}
+	dial := func(string) (conformance.Conn, error) { return conn, nil }
+	messages := mcptest.Recording(t, session[0], session[3])
+
+	report, err := Run(context.Background(), messages, dial, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Matched, "a request without a recorded response only has to succeed")
+	assert.Equal(t, 1, report.Failed)
+	assert.Equal(t, "connection closed", report.Exchanges[1].Error)
+
+	failing := func(string) (conformance.Conn, error) { return nil, errors.New("no such command") }
+	report, err = Run(context.Background(), messages, failing, Options{})
+	require.NoError(t, err)
+	require.Len(t, report.Exchanges, 1)
+	assert.Equal(t, "failed to connect: no such command", report.Exchanges[0].Error)
+
+	_, err = Run(context.Background(), messages, dial, Options{Servers: []string{"ghost"}})
+	assert.EqualError(t, err, "server ghost does not appear in the recording")
+}
+
+func TestServers(t *testing.T) {
+	messages := mcptest.Recording(t,
+		`b send {}`,
+		`a send {}`,
+		`b receive {}`,
+	)
+	assert.Equal(t, []string{"b", "a"}, Servers(messages))
+}
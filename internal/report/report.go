@@ -0,0 +1,328 @@
+// Package report writes test results as text, JUnit XML, TAP or JSON for people and CI systems
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Status is the outcome of a test case
+type Status string
+
+// Case statuses
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Format is an output format for results
+type Format string
+
+// Report formats
+const (
+	FormatText  Format = "text"
+	FormatJUnit Format = "junit"
+	FormatTAP   Format = "tap"
+	FormatJSON  Format = "json"
+)
+
+// Case is a single test case
+type Case struct {
+	Name     string
+	Status   Status
+	Duration time.Duration
+	// Message explains a failure or skip
+	Message string
+	// Output is extra text attached to the case, such as a server's stderr
+	Output string
+}
+
+// Suite is a named group of cases
+type Suite struct {
+	Name  string
+	Cases []Case
+}
+
+// Duration returns the total duration of the suite's cases
+func (s Suite) Duration() time.Duration {
+	var total time.Duration
+	for _, c := range s.Cases {
+		total += c.Duration
+	}
+	return total
+}
+
+// Totals counts cases by status
+type Totals struct {
+	Tests    int
+	Passed   int
+	Failed   int
+	Skipped  int
+	Duration time.Duration
+}
+
+// Count totals the cases of some suites
+func Count(suites ...Suite) Totals {
+	var totals Totals
+	for _, s := range suites {
+		for _, c := range s.Cases {
+			totals.Tests++
+			totals.Duration += c.Duration
+			switch c.Status {
+			case StatusPassed:
+				totals.Passed++
+			case StatusFailed:
+				totals.Failed++
+			case StatusSkipped:
+				totals.Skipped++
+			}
+		}
+	}
+	return totals
+}
+
+// ParseFormat converts a flag value into a Format
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(strings.ToLower(value)); format {
+	case FormatText, FormatJUnit, FormatTAP, FormatJSON:
+		return format, nil
+	}
+	return "", fmt.Errorf("invalid report format %q (expected %s, %s, %s or %s)", value, FormatText, FormatJUnit, FormatTAP, FormatJSON)
+}
+
+// Write writes suites in the given format
+func Write(w io.Writer, format Format, suites []Suite) error {
+	switch format {
+	case FormatJUnit:
+		return WriteJUnit(w, suites)
+	case FormatTAP:
+		return WriteTAP(w, suites)
+	case FormatJSON:
+		return WriteJSON(w, suites)
+	default:
+		return WriteText(w, suites)
+	}
+}
+
+// WriteText writes a human-readable report, with the output of failed cases indented below them
+func WriteText(w io.Writer, suites []Suite) error {
+	for _, s := range suites {
+		fmt.Fprintf(w, "%s\n", s.Name)
+		for _, c := range s.Cases {
+			label := map[Status]string{StatusPassed: "ok", StatusFailed: "FAIL", StatusSkipped: "skip"}[c.Status]
+			fmt.Fprintf(w, "  %-4s  %s (%s)", label, c.Name, formatDuration(c.Duration))
+			if c.Message != "" {
+				fmt.Fprintf(w, ": %s", c.Message)
+			}
+			fmt.Fprintln(w)
+			if c.Status == StatusFailed && c.Output != "" {
+				for _, line := range strings.Split(strings.TrimRight(c.Output, "\n"), "\n") {
+					fmt.Fprintf(w, "        | %s\n", line)
+				}
+			}
+		}
+	}
+
+	totals := Count(suites...)
+	_, err := fmt.Fprintf(w, "\n%d passed, %d failed, %d skipped in %s\n", totals.Passed, totals.Failed, totals.Skipped, formatDuration(totals.Duration))
+	return err
+}
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes a JUnit XML report with one testsuite per suite. Case output is attached as
+// system-err.
+func WriteJUnit(w io.Writer, suites []Suite) error {
+	totals := Count(suites...)
+	root := junitTestSuites{
+		Tests:    totals.Tests,
+		Failures: totals.Failed,
+		Skipped:  totals.Skipped,
+		Time:     seconds(totals.Duration),
+	}
+
+	for _, s := range suites {
+		suiteTotals := Count(s)
+		junitSuite := junitTestSuite{
+			Name:     s.Name,
+			Tests:    suiteTotals.Tests,
+			Failures: suiteTotals.Failed,
+			Skipped:  suiteTotals.Skipped,
+			Time:     seconds(suiteTotals.Duration),
+		}
+		for _, c := range s.Cases {
+			testCase := junitTestCase{Name: c.Name, Classname: s.Name, Time: seconds(c.Duration), SystemErr: c.Output}
+			switch c.Status {
+			case StatusFailed:
+				testCase.Failure = &junitMessage{Message: c.Message, Text: c.Message}
+			case StatusSkipped:
+				testCase.Skipped = &junitMessage{Message: c.Message}
+			}
+			junitSuite.Cases = append(junitSuite.Cases, testCase)
+		}
+		root.Suites = append(root.Suites, junitSuite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(root); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+// tapDiagnostics is the YAML block TAP 13 attaches to a failed test
+type tapDiagnostics struct {
+	Message    string  `yaml:"message,omitempty"`
+	DurationMS float64 `yaml:"duration_ms"`
+	Output     string  `yaml:"output,omitempty"`
+}
+
+// WriteTAP writes a TAP version 13 report, naming each test "suite: case"
+func WriteTAP(w io.Writer, suites []Suite) error {
+	totals := Count(suites...)
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", totals.Tests)
+
+	n := 0
+	for _, s := range suites {
+		for _, c := range s.Cases {
+			n++
+			name := fmt.Sprintf("%s: %s", s.Name, c.Name)
+			switch c.Status {
+			case StatusPassed:
+				fmt.Fprintf(w, "ok %d - %s\n", n, name)
+			case StatusSkipped:
+				fmt.Fprintf(w, "ok %d - %s # SKIP %s\n", n, name, c.Message)
+			default:
+				fmt.Fprintf(w, "not ok %d - %s\n", n, name)
+				var data strings.Builder
+				encoder := yaml.NewEncoder(&data)
+				encoder.SetIndent(2)
+				if err := encoder.Encode(tapDiagnostics{Message: c.Message, DurationMS: milliseconds(c.Duration), Output: c.Output}); err != nil {
+					return fmt.Errorf("failed to write TAP diagnostics: %w", err)
+				}
+				fmt.Fprintln(w, "  ---")
+				for _, line := range strings.Split(strings.TrimRight(data.String(), "\n"), "\n") {
+					fmt.Fprintf(w, "  %s\n", line)
+				}
+				fmt.Fprintln(w, "  ...")
+			}
+		}
+	}
+	return nil
+}
+
+// jsonReport is the JSON summary of a run
+type jsonReport struct {
+	Tests      int         `json:"tests"`
+	Passed     int         `json:"passed"`
+	Failed     int         `json:"failed"`
+	Skipped    int         `json:"skipped"`
+	DurationMS float64     `json:"duration_ms"`
+	Suites     []jsonSuite `json:"suites"`
+}
+
+type jsonSuite struct {
+	Name       string     `json:"name"`
+	DurationMS float64    `json:"duration_ms"`
+	Cases      []jsonCase `json:"cases"`
+}
+
+type jsonCase struct {
+	Name       string  `json:"name"`
+	Status     Status  `json:"status"`
+	DurationMS float64 `json:"duration_ms"`
+	Message    string  `json:"message,omitempty"`
+	Output     string  `json:"output,omitempty"`
+}
+
+// WriteJSON writes the totals and every case as indented JSON
+func WriteJSON(w io.Writer, suites []Suite) error {
+	totals := Count(suites...)
+	root := jsonReport{
+		Tests:      totals.Tests,
+		Passed:     totals.Passed,
+		Failed:     totals.Failed,
+		Skipped:    totals.Skipped,
+		DurationMS: milliseconds(totals.Duration),
+		Suites:     make([]jsonSuite, 0, len(suites)),
+	}
+	for _, s := range suites {
+		suite := jsonSuite{Name: s.Name, DurationMS: milliseconds(s.Duration()), Cases: make([]jsonCase, 0, len(s.Cases))}
+		for _, c := range s.Cases {
+			suite.Cases = append(suite.Cases, jsonCase{
+				Name:       c.Name,
+				Status:     c.Status,
+				DurationMS: milliseconds(c.Duration),
+				Message:    c.Message,
+				Output:     c.Output,
+			})
+		}
+		root.Suites = append(root.Suites, suite)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(root)
+}
+
+// seconds formats a duration the way JUnit expects
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// formatDuration rounds a duration for people
+func formatDuration(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
@@ -0,0 +1,107 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleSuites() []Suite {
+	return []Suite{
+		{Name: "broken", Cases: []Case{
+			{Name: "initialize", Status: StatusFailed, Duration: 1500 * time.Microsecond, Message: "failed to connect", Output: "fatal: no token\n"},
+			{Name: "ping", Status: StatusSkipped, Message: "server did not start"},
+		}},
+		{Name: "good", Cases: []Case{
+			{Name: "initialize", Status: StatusPassed, Duration: 2 * time.Millisecond},
+		}},
+	}
+}
+
+func TestCount(t *testing.T) {
+	totals := Count(sampleSuites()...)
+	assert.Equal(t, Totals{Tests: 3, Passed: 1, Failed: 1, Skipped: 1, Duration: 3500 * time.Microsecond}, totals)
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("JUnit")
+	require.NoError(t, err)
+	assert.Equal(t, FormatJUnit, format)
+
+	_, err = ParseFormat("html")
+	assert.Error(t, err)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJUnit(&buf, sampleSuites()))
+
+	var parsed junitTestSuites
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, 3, parsed.Tests)
+	assert.Equal(t, 1, parsed.Failures)
+	assert.Equal(t, 1, parsed.Skipped)
+	require.Len(t, parsed.Suites, 2)
+
+	broken := parsed.Suites[0]
+	assert.Equal(t, "broken", broken.Name)
+	assert.Equal(t, "0.002", broken.Time)
+	require.Len(t, broken.Cases, 2)
+	assert.Equal(t, "broken", broken.Cases[0].Classname)
+	require.NotNil(t, broken.Cases[0].Failure)
+	assert.Equal(t, "failed to connect", broken.Cases[0].Failure.Message)
+	assert.Equal(t, "fatal: no token\n", broken.Cases[0].SystemErr)
+	require.NotNil(t, broken.Cases[1].Skipped)
+	assert.Nil(t, parsed.Suites[1].Cases[0].Failure)
+}
+
+func TestWriteTAP(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteTAP(&buf, sampleSuites()))
+
+	assert.Equal(t, `TAP version 13
+1..3
+not ok 1 - broken: initialize
+  ---
+  message: failed to connect
+  duration_ms: 1.5
+  output: |
+    fatal: no token
+  ...
+ok 2 - broken: ping # SKIP server did not start
+ok 3 - good: initialize
+`, buf.String())
+}
+
+func TestWriteJSON(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, sampleSuites()))
+
+	var parsed jsonReport
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &parsed))
+	assert.Equal(t, 3, parsed.Tests)
+	assert.Equal(t, 1, parsed.Failed)
+	assert.Equal(t, 3.5, parsed.DurationMS)
+	require.Len(t, parsed.Suites, 2)
+	assert.Equal(t, jsonCase{Name: "initialize", Status: StatusFailed, DurationMS: 1.5, Message: "failed to connect", Output: "fatal: no token\n"}, parsed.Suites[0].Cases[0])
+}
+
+func TestWriteText(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteText(&buf, sampleSuites()))
+
+	assert.Equal(t, `broken
+  FAIL  initialize (2ms): failed to connect
+        | fatal: no token
+  skip  ping (0s): server did not start
+good
+  ok    initialize (2ms)
+
+1 passed, 1 failed, 1 skipped in 4ms
+`, buf.String())
+}
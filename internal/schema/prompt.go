@@ -0,0 +1,359 @@
+package schema
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// errInputEnded is returned when the user's input ends before all questions are answered
+var errInputEnded = errors.New("input ended before all values were entered")
+
+// Prompter asks the user for a value matching a schema, one property at a time. Enums are
+// offered as numbered choices, booleans as y/n, arrays as repeated entries, and defaults are
+// prefilled so an empty answer accepts them.
+type Prompter struct {
+	in   *bufio.Scanner
+	out  io.Writer
+	root *jsonschema.Schema
+}
+
+// NewPrompter creates a prompter reading answers from in and writing questions to out
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewScanner(in), out: out}
+}
+
+// Object asks for every property of an object schema and returns the resulting object
+func (p *Prompter) Object(s *jsonschema.Schema) (map[string]interface{}, error) {
+	p.root = s
+	if s == nil {
+		return map[string]interface{}{}, nil
+	}
+	return p.object(p.resolve(s), "")
+}
+
+// Confirm asks a yes/no question
+func (p *Prompter) Confirm(question string, defaultValue bool) (bool, error) {
+	hint := "y/N"
+	if defaultValue {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.Line(fmt.Sprintf("%s [%s]: ", question, hint))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultValue, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "  Please answer y or n.")
+	}
+}
+
+// Line prints a prompt and reads one trimmed line of input
+func (p *Prompter) Line(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	if !p.in.Scan() {
+		if err := p.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errInputEnded
+	}
+	return strings.TrimSpace(p.in.Text()), nil
+}
+
+// object asks for the properties of an object, required ones first
+func (p *Prompter) object(s *jsonschema.Schema, path string) (map[string]interface{}, error) {
+	required := make(map[string]bool, len(s.Required))
+	for _, name := range s.Required {
+		required[name] = true
+	}
+
+	names := make([]string, 0, len(s.Properties))
+	for name := range s.Properties {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if required[names[i]] != required[names[j]] {
+			return required[names[i]]
+		}
+		return names[i] < names[j]
+	})
+
+	object := make(map[string]interface{})
+	for _, name := range names {
+		value, set, err := p.value(p.resolve(s.Properties[name]), joinPath(path, name), required[name])
+		if err != nil {
+			return nil, err
+		}
+		if set {
+			object[name] = value
+		}
+	}
+	return object, nil
+}
+
+// value asks for a single value. It returns set=false when an optional value was skipped.
+func (p *Prompter) value(s *jsonschema.Schema, path string, required bool) (interface{}, bool, error) {
+	if s.Description != "" {
+		fmt.Fprintf(p.out, "%s: %s\n", path, s.Description)
+	}
+
+	defaultValue, hasDefault := defaultOf(s)
+	switch valueType := typeOf(s); {
+	case len(s.Enum) > 0:
+		return p.choice(s, path, required, defaultValue, hasDefault)
+	case valueType == "object":
+		if !required && !hasDefault {
+			include, err := p.Confirm(fmt.Sprintf("%s (object): set it?", path), false)
+			if err != nil || !include {
+				return nil, false, err
+			}
+		}
+		object, err := p.object(s, path)
+		return object, err == nil, err
+	case valueType == "array":
+		return p.array(s, path, required, defaultValue, hasDefault)
+	default:
+		return p.scalar(s, valueType, path, required, defaultValue, hasDefault)
+	}
+}
+
+// choice offers the values of an enum as numbered choices
+func (p *Prompter) choice(s *jsonschema.Schema, path string, required bool, defaultValue interface{}, hasDefault bool) (interface{}, bool, error) {
+	for i, option := range s.Enum {
+		marker := ""
+		if hasDefault && formatValue(option) == formatValue(defaultValue) {
+			marker = " (default)"
+		}
+		fmt.Fprintf(p.out, "  %d) %s%s\n", i+1, formatValue(option), marker)
+	}
+
+	for {
+		answer, err := p.Line(p.label(path, "choice", required, defaultValue, hasDefault))
+		if err != nil {
+			return nil, false, err
+		}
+		if answer == "" {
+			if value, set, done := p.empty(required, defaultValue, hasDefault); done {
+				return value, set, nil
+			}
+			continue
+		}
+		if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(s.Enum) {
+			return s.Enum[n-1], true, nil
+		}
+		for _, option := range s.Enum {
+			if fmt.Sprint(option) == answer {
+				return option, true, nil
+			}
+		}
+		fmt.Fprintf(p.out, "  Enter a number between 1 and %d.\n", len(s.Enum))
+	}
+}
+
+// array asks for items one at a time until an empty answer
+func (p *Prompter) array(s *jsonschema.Schema, path string, required bool, defaultValue interface{}, hasDefault bool) (interface{}, bool, error) {
+	itemSchema := p.resolve(s.Items)
+	if itemSchema == nil {
+		itemSchema = &jsonschema.Schema{}
+	}
+
+	hint := "one item per prompt, empty to finish"
+	if hasDefault {
+		hint += fmt.Sprintf(", default %s", formatValue(defaultValue))
+	}
+	fmt.Fprintf(p.out, "%s (array; %s)\n", path, hint)
+
+	items := make([]interface{}, 0)
+	for i := 0; ; i++ {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if typeOf(itemSchema) == "object" || len(itemSchema.Enum) > 0 {
+			more, err := p.Confirm(fmt.Sprintf("Add %s?", itemPath), false)
+			if err != nil {
+				return nil, false, err
+			}
+			if !more {
+				break
+			}
+			value, _, err := p.value(itemSchema, itemPath, true)
+			if err != nil {
+				return nil, false, err
+			}
+			items = append(items, value)
+			continue
+		}
+
+		value, set, err := p.scalar(itemSchema, typeOf(itemSchema), itemPath, false, nil, false)
+		if err != nil {
+			return nil, false, err
+		}
+		if !set {
+			break
+		}
+		items = append(items, value)
+	}
+
+	if len(items) == 0 {
+		if hasDefault {
+			return defaultValue, true, nil
+		}
+		if !required {
+			return nil, false, nil
+		}
+	}
+	return items, true, nil
+}
+
+// scalar asks for a string, number, integer, boolean or, for other schemas, raw JSON
+func (p *Prompter) scalar(s *jsonschema.Schema, valueType, path string, required bool, defaultValue interface{}, hasDefault bool) (interface{}, bool, error) {
+	kind := valueType
+	switch valueType {
+	case "boolean":
+		kind = "y/n"
+	case "":
+		kind = "JSON"
+	}
+
+	for {
+		answer, err := p.Line(p.label(path, kind, required, defaultValue, hasDefault))
+		if err != nil {
+			return nil, false, err
+		}
+		if answer == "" {
+			if value, set, done := p.empty(required, defaultValue, hasDefault); done {
+				return value, set, nil
+			}
+			continue
+		}
+
+		value, err := parseScalar(valueType, answer)
+		if err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		if err := Validate(s, value); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return value, true, nil
+	}
+}
+
+// empty handles an empty answer: it accepts the default, skips an optional value, or asks again
+func (p *Prompter) empty(required bool, defaultValue interface{}, hasDefault bool) (interface{}, bool, bool) {
+	switch {
+	case hasDefault:
+		return defaultValue, true, true
+	case !required:
+		return nil, false, true
+	}
+	fmt.Fprintln(p.out, "  A value is required.")
+	return nil, false, false
+}
+
+// label builds the prompt for a value
+func (p *Prompter) label(path, kind string, required bool, defaultValue interface{}, hasDefault bool) string {
+	var notes []string
+	notes = append(notes, kind)
+	if required {
+		notes = append(notes, "required")
+	}
+	label := fmt.Sprintf("%s (%s)", path, strings.Join(notes, ", "))
+	if hasDefault {
+		label += fmt.Sprintf(" [%s]", formatValue(defaultValue))
+	}
+	return label + ": "
+}
+
+// resolve follows a local $ref, returning the schema itself if it has none
+func (p *Prompter) resolve(s *jsonschema.Schema) *jsonschema.Schema {
+	return Resolve(p.root, s)
+}
+
+// parseScalar converts an answer into a value of the given JSON type
+func parseScalar(valueType, answer string) (interface{}, error) {
+	switch valueType {
+	case "string":
+		return answer, nil
+	case "integer":
+		n, err := strconv.ParseInt(answer, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not an integer", answer)
+		}
+		return n, nil
+	case "number":
+		n, err := strconv.ParseFloat(answer, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", answer)
+		}
+		return n, nil
+	case "boolean":
+		switch strings.ToLower(answer) {
+		case "y", "yes", "true":
+			return true, nil
+		case "n", "no", "false":
+			return false, nil
+		}
+		return nil, fmt.Errorf("answer y or n")
+	case "null":
+		return nil, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(answer), &value); err != nil {
+		return nil, fmt.Errorf("enter a JSON value: %v", err)
+	}
+	return value, nil
+}
+
+// typeOf returns the JSON type a schema expects, ignoring null in type lists
+func typeOf(s *jsonschema.Schema) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	for _, t := range s.Types {
+		if t != "null" {
+			return t
+		}
+	}
+	switch {
+	case s.Properties != nil:
+		return "object"
+	case s.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// defaultOf decodes a schema's default value
+func defaultOf(s *jsonschema.Schema) (interface{}, bool) {
+	if len(s.Default) == 0 {
+		return nil, false
+	}
+	var value interface{}
+	if err := json.Unmarshal(s.Default, &value); err != nil {
+		return nil, false
+	}
+	return value, true
+}
+
+// joinPath appends a property name to a dotted path
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
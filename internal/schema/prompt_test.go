@@ -0,0 +1,80 @@
+package schema_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/schema"
+)
+
+func TestPrompterObject(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"type": "object",
+		"required": ["query"],
+		"properties": {
+			"query": {"type": "string", "description": "What to search for"},
+			"limit": {"type": "integer", "default": 10},
+			"exact": {"type": "boolean"},
+			"sort": {"enum": ["relevance", "date"], "default": "relevance"},
+			"tags": {"type": "array", "items": {"type": "string"}},
+			"range": {"$ref": "#/$defs/range"}
+		},
+		"$defs": {
+			"range": {"type": "object", "properties": {"from": {"type": "number"}}}
+		}
+	}`)
+
+	answers := strings.Join([]string{
+		"",    // query is required, so it is asked again
+		"mcp", // query
+		"y",   // exact
+		"",    // limit keeps its default
+		"yes", // set range
+		"1.5", // range.from
+		"2",   // sort
+		"go",  // tags[0]
+		"sdk", // tags[1]
+		"",    // end of tags
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	params, err := schema.NewPrompter(strings.NewReader(answers), &out).Object(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{
+		"query": "mcp",
+		"exact": true,
+		"limit": float64(10),
+		"range": map[string]interface{}{"from": 1.5},
+		"sort":  "date",
+		"tags":  []interface{}{"go", "sdk"},
+	}, params)
+	assert.Contains(t, out.String(), "query: What to search for")
+	assert.Contains(t, out.String(), "A value is required.")
+	assert.Contains(t, out.String(), "limit (integer) [10]: ")
+	assert.Contains(t, out.String(), `  1) "relevance" (default)`)
+}
+
+func TestPrompterRetriesInvalidValues(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{"type": "object", "properties": {"count": {"type": "integer", "minimum": 1}}}`)
+
+	var out bytes.Buffer
+	params, err := schema.NewPrompter(strings.NewReader("many\n0\n3\n"), &out).Object(s)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]interface{}{"count": int64(3)}, params)
+	assert.Contains(t, out.String(), `"many" is not an integer`)
+	assert.Contains(t, out.String(), "$: minimum: 0/1 is less than 1.000000")
+}
+
+func TestPrompterInputEnded(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{"type": "object", "required": ["name"], "properties": {"name": {"type": "string"}}}`)
+
+	_, err := schema.NewPrompter(strings.NewReader(""), &bytes.Buffer{}).Object(s)
+	assert.ErrorContains(t, err, "input ended before all values were entered")
+}
@@ -0,0 +1,297 @@
+// Package schema validates JSON values against the JSON Schemas published by MCP servers
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+)
+
+// maxRefDepth stops $ref cycles while following references
+const maxRefDepth = 64
+
+// ValidationError is a schema violation at a JSON path such as $.items[2].name
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+// Error implements the error interface
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Message)
+}
+
+// Validate checks a value against a schema with the SDK's JSON Schema validator. It returns nil
+// for a nil schema, and a ValidationError locating the first violation otherwise.
+func Validate(s *jsonschema.Schema, instance interface{}) error {
+	if s == nil {
+		return nil
+	}
+
+	value, err := Normalize(instance)
+	if err != nil {
+		return err
+	}
+	resolved, err := resolve(s)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+	if err := resolved.Validate(value); err != nil {
+		return locate(s, value, err.Error())
+	}
+	return nil
+}
+
+// Normalize converts a Go value into the generic form produced by encoding/json
+func Normalize(instance interface{}) (interface{}, error) {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return nil, fmt.Errorf("value is not valid JSON: %w", err)
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, fmt.Errorf("value is not valid JSON: %w", err)
+	}
+	return value, nil
+}
+
+// resolve prepares a copy of a schema for the SDK's validator, which marks the schemas it
+// resolves and refuses to resolve them twice. The SDK only validates draft 2020-12, so a
+// declared $schema is dropped: servers commonly declare draft-07, which means the same for the
+// keywords tool schemas use.
+func resolve(s *jsonschema.Schema) (*jsonschema.Resolved, error) {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+	var clone jsonschema.Schema
+	if err := json.Unmarshal(data, &clone); err != nil {
+		return nil, err
+	}
+	clone.Schema = ""
+	return clone.Resolve(nil)
+}
+
+// locate turns an error of the SDK's validator into a ValidationError. The SDK wraps the
+// violation in the JSON Pointer of every schema it passed through, as in "validating root:
+// validating /properties/tags: validating /properties/tags/items: type: ...", so the steps from
+// each schema into its subschema give the path in the instance. The SDK does not say which array
+// item or extra property failed, so those show as [*].
+func locate(root *jsonschema.Schema, value interface{}, message string) ValidationError {
+	at := &location{path: "$", value: value}
+	pointer := ""
+	for {
+		rest, ok := strings.CutPrefix(message, "validating ")
+		if !ok {
+			break
+		}
+		next, remainder, ok := strings.Cut(rest, ": ")
+		if !ok {
+			break
+		}
+		if next == "root" {
+			next = ""
+		}
+		// A pointer outside the current schema was reached through a $ref
+		if suffix, ok := strings.CutPrefix(next, pointer+"/"); ok {
+			at.step(strings.Split(suffix, "/"))
+		}
+		pointer, message = next, remainder
+	}
+
+	// additionalProperties: false is how schemas forbid unknown properties, which the SDK
+	// reports in terms of its {"not": {}} form of false
+	if at.keyword == "additionalProperties" && strings.HasPrefix(message, "not: validated against ") {
+		message = "property is not allowed"
+		parent := schemaAt(root, strings.TrimSuffix(pointer, "/additionalProperties"))
+		if name, ok := unknownProperty(parent, at.object); ok {
+			return ValidationError{Path: PropertyPath(at.objectPath, name), Message: message}
+		}
+	}
+	return ValidationError{Path: at.path, Message: message}
+}
+
+// location follows the steps of a schema pointer through the instance
+type location struct {
+	path  string
+	value interface{}
+	// keyword is the last keyword stepped through
+	keyword string
+	// object and objectPath are the object whose additional properties were last entered
+	object     interface{}
+	objectPath string
+}
+
+// step moves from a schema into the subschema at the given pointer segments
+func (l *location) step(segments []string) {
+	for i := 0; i < len(segments); i++ {
+		l.keyword = unescapePointer(segments[i])
+		name := ""
+		if i+1 < len(segments) {
+			name = unescapePointer(segments[i+1])
+		}
+		switch l.keyword {
+		case "properties":
+			i++
+			l.path = PropertyPath(l.path, name)
+			l.value = childValue(l.value, name)
+		case "prefixItems":
+			i++
+			l.path = fmt.Sprintf("%s[%s]", l.path, name)
+			if index, err := strconv.Atoi(name); err == nil {
+				l.value = itemValue(l.value, index)
+			}
+		case "additionalProperties":
+			l.object, l.objectPath = l.value, l.path
+			l.path, l.value = l.path+"[*]", nil
+		case "items", "additionalItems", "unevaluatedItems", "unevaluatedProperties", "contains":
+			l.path, l.value = l.path+"[*]", nil
+		case "patternProperties":
+			i++
+			l.path, l.value = l.path+"[*]", nil
+		case "$defs", "definitions", "dependentSchemas", "allOf", "anyOf", "oneOf":
+			// The next segment names a subschema that applies to the same value
+			i++
+		}
+	}
+}
+
+// unknownProperty names the first property of an object that a schema neither declares nor
+// matches with a pattern
+func unknownProperty(s *jsonschema.Schema, value interface{}) (string, bool) {
+	object, ok := value.(map[string]interface{})
+	if s == nil || !ok {
+		return "", false
+	}
+
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, ok := s.Properties[name]; ok {
+			continue
+		}
+		matched := false
+		for pattern := range s.PatternProperties {
+			if re, err := regexp.Compile(pattern); err == nil && re.MatchString(name) {
+				matched = true
+			}
+		}
+		if !matched {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// schemaAt finds the schema at a JSON Pointer from the root, or nil if the pointer goes through
+// a keyword other than properties, $defs, definitions, items and prefixItems
+func schemaAt(root *jsonschema.Schema, pointer string) *jsonschema.Schema {
+	if pointer == "" {
+		return root
+	}
+	segments := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	s := root
+	for i := 0; s != nil && i < len(segments); i++ {
+		name := ""
+		if i+1 < len(segments) {
+			name = unescapePointer(segments[i+1])
+		}
+		switch unescapePointer(segments[i]) {
+		case "properties":
+			s, i = s.Properties[name], i+1
+		case "$defs":
+			s, i = s.Defs[name], i+1
+		case "definitions":
+			s, i = s.Definitions[name], i+1
+		case "items":
+			s = s.Items
+		case "prefixItems":
+			index, err := strconv.Atoi(name)
+			if err != nil || index < 0 || index >= len(s.PrefixItems) {
+				return nil
+			}
+			s, i = s.PrefixItems[index], i+1
+		default:
+			return nil
+		}
+	}
+	return s
+}
+
+// unescapePointer decodes a JSON Pointer segment
+func unescapePointer(segment string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(segment)
+}
+
+// childValue returns a property of an object, or nil if the value is not an object
+func childValue(value interface{}, name string) interface{} {
+	if object, ok := value.(map[string]interface{}); ok {
+		return object[name]
+	}
+	return nil
+}
+
+// itemValue returns an item of an array, or nil if there is none
+func itemValue(value interface{}, index int) interface{} {
+	if items, ok := value.([]interface{}); ok && index >= 0 && index < len(items) {
+		return items[index]
+	}
+	return nil
+}
+
+// Resolve follows the local $ref of a schema within the root document, returning the schema
+// itself if it has none or the reference cannot be resolved
+func Resolve(root, s *jsonschema.Schema) *jsonschema.Schema {
+	for depth := 0; s != nil && s.Ref != "" && depth < maxRefDepth; depth++ {
+		target := resolveRef(root, s.Ref)
+		if target == nil {
+			break
+		}
+		s = target
+	}
+	return s
+}
+
+// resolveRef finds a schema referenced from within a root document. Only local references to
+// the root and its definitions are supported.
+func resolveRef(root *jsonschema.Schema, ref string) *jsonschema.Schema {
+	if ref == "#" {
+		return root
+	}
+	for _, prefix := range []string{"#/$defs/", "#/definitions/"} {
+		if name, ok := strings.CutPrefix(ref, prefix); ok {
+			if s := root.Defs[name]; s != nil {
+				return s
+			}
+			return root.Definitions[name]
+		}
+	}
+	return nil
+}
+
+// PropertyPath appends an object property to a JSON path such as $.items[2]
+func PropertyPath(path, name string) string {
+	if identifierPattern.MatchString(name) {
+		return path + "." + name
+	}
+	return path + "[" + strconv.Quote(name) + "]"
+}
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// formatValue renders a value as compact JSON for messages
+func formatValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
@@ -0,0 +1,157 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/mcptest"
+	"mcp_tstr/internal/schema"
+)
+
+// These tests run in their own package so they can use mcptest, which imports the mock server.
+
+func TestValidate(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"type": "object",
+		"required": ["location"],
+		"properties": {
+			"location": {"type": "string", "minLength": 1},
+			"units": {"enum": ["c", "f"]},
+			"days": {"type": "integer", "minimum": 1, "maximum": 7},
+			"tags": {"type": "array", "items": {"type": "string"}, "uniqueItems": true},
+			"options": {"$ref": "#/$defs/options"}
+		},
+		"additionalProperties": false,
+		"$defs": {
+			"options": {"type": "object", "properties": {"detail": {"type": "boolean"}}}
+		}
+	}`)
+
+	tests := []struct {
+		name     string
+		instance interface{}
+		err      *schema.ValidationError
+	}{
+		{
+			name:     "valid",
+			instance: map[string]interface{}{"location": "Oslo", "units": "c", "days": 3, "tags": []string{"a", "b"}},
+		},
+		{
+			name:     "missing required property",
+			instance: map[string]interface{}{},
+			err:      &schema.ValidationError{Path: "$", Message: `required: missing properties: ["location"]`},
+		},
+		{
+			name:     "wrong type",
+			instance: map[string]interface{}{"location": 42},
+			err:      &schema.ValidationError{Path: "$.location", Message: `type: 42 has type "integer", want "string"`},
+		},
+		{
+			name:     "range",
+			instance: map[string]interface{}{"location": "Oslo", "days": 9},
+			err:      &schema.ValidationError{Path: "$.days", Message: "maximum: 9/1 is greater than 7.000000"},
+		},
+		{
+			name:     "enum",
+			instance: map[string]interface{}{"location": "Oslo", "units": "k"},
+			err:      &schema.ValidationError{Path: "$.units", Message: "enum: k does not equal any of: [c f]"},
+		},
+		{
+			name:     "integer",
+			instance: map[string]interface{}{"location": "Oslo", "days": 1.5},
+			err:      &schema.ValidationError{Path: "$.days", Message: `type: 1.5 has type "number", want "integer"`},
+		},
+		{
+			name:     "array item",
+			instance: map[string]interface{}{"location": "Oslo", "tags": []interface{}{"a", true}},
+			err:      &schema.ValidationError{Path: "$.tags[*]", Message: `type: true has type "boolean", want "string"`},
+		},
+		{
+			name:     "reference",
+			instance: map[string]interface{}{"location": "Oslo", "options": map[string]interface{}{"detail": "yes"}},
+			err:      &schema.ValidationError{Path: "$.options.detail", Message: `type: yes has type "string", want "boolean"`},
+		},
+		{
+			name:     "additional property",
+			instance: map[string]interface{}{"location": "Oslo", "my key": 1},
+			err:      &schema.ValidationError{Path: `$["my key"]`, Message: "property is not allowed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(s, tt.instance)
+			if tt.err == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var validationErr schema.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, *tt.err, validationErr)
+		})
+	}
+}
+
+func TestValidateCombinators(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{"oneOf": [{"type": "string"}, {"type": "number", "multipleOf": 2}]}`)
+
+	assert.NoError(t, schema.Validate(s, "text"))
+	assert.NoError(t, schema.Validate(s, 4))
+	assert.Error(t, schema.Validate(s, 3))
+
+	s = mcptest.ParseSchema(t, `{"anyOf": [{"type": "null"}, {"type": "string", "pattern": "^a"}]}`)
+	assert.NoError(t, schema.Validate(s, nil))
+	assert.NoError(t, schema.Validate(s, "abc"))
+	assert.Error(t, schema.Validate(s, "xyz"))
+}
+
+func TestValidateNestedPaths(t *testing.T) {
+	s := mcptest.ParseSchema(t, `{
+		"$schema": "http://json-schema.org/draft-07/schema#",
+		"type": "object",
+		"properties": {
+			"points": {"type": "array", "prefixItems": [{"type": "object", "properties": {"x": {"type": "number"}}, "additionalProperties": false}]}
+		}
+	}`)
+
+	assert.NoError(t, schema.Validate(s, map[string]interface{}{"points": []interface{}{map[string]interface{}{"x": 1}}}))
+	assert.EqualError(t, schema.Validate(s, map[string]interface{}{"points": []interface{}{map[string]interface{}{"x": "1"}}}),
+		`$.points[0].x: type: 1 has type "string", want "number"`)
+	assert.EqualError(t, schema.Validate(s, map[string]interface{}{"points": []interface{}{map[string]interface{}{"x": 1, "y": 2}}}),
+		"$.points[0].y: property is not allowed")
+
+	// The schema is copied before the SDK resolves it, so it can be used again
+	assert.NoError(t, schema.Validate(s, map[string]interface{}{}))
+}
+
+// Paths are rebuilt from the "validating <pointer>: " prefixes of the SDK's errors. A go-sdk
+// upgrade that changes them would silently place every error at $, so this fails first.
+func TestValidateFollowsSDKMessages(t *testing.T) {
+	const order = `{
+		"type": "object",
+		"properties": {"order": {"$ref": "#/$defs/order"}},
+		"$defs": {
+			"order": {"type": "object", "properties": {"customer": {"$ref": "#/$defs/customer"}}},
+			"customer": {"type": "object", "properties": {"age": {"type": "integer"}}}
+		}
+	}`
+	instance := map[string]interface{}{"order": map[string]interface{}{"customer": map[string]interface{}{"age": "old"}}}
+
+	resolved, err := mcptest.ParseSchema(t, order).Resolve(nil)
+	require.NoError(t, err)
+	sdkErr := resolved.Validate(instance)
+	require.Error(t, sdkErr)
+	assert.True(t, strings.HasPrefix(sdkErr.Error(), "validating root: validating /properties/order: "), sdkErr.Error())
+
+	err = schema.Validate(mcptest.ParseSchema(t, order), instance)
+	var validationErr schema.ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	assert.Equal(t, "$.order.customer.age", validationErr.Path)
+}
+
+func TestValidateNilSchema(t *testing.T) {
+	assert.NoError(t, schema.Validate(nil, map[string]interface{}{"anything": true}))
+}
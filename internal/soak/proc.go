@@ -0,0 +1,166 @@
+package soak
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the kernel's USER_HZ, the unit of CPU times in /proc/<pid>/stat. It is 100 on
+// every mainstream Linux platform.
+const clockTicks = 100
+
+// ProcessStats is the resource usage of a process and all of its descendants, so servers
+// started through a wrapper such as npx or sh are measured as a whole
+type ProcessStats struct {
+	PID       int
+	Processes int
+	// RSS is the resident memory in bytes
+	RSS uint64
+	// CPUTime is the user and system time used so far
+	CPUTime time.Duration
+	// FDs is the number of open file descriptors
+	FDs int
+}
+
+// Proc reads process statistics from a procfs mount
+type Proc struct {
+	// Root is the mount point, normally /proc
+	Root string
+}
+
+// Stats reads the resource usage of a process tree
+func (p Proc) Stats(pid int) (ProcessStats, error) {
+	if _, err := os.Stat(filepath.Join(p.Root, strconv.Itoa(pid))); err != nil {
+		return ProcessStats{}, fmt.Errorf("process %d not found: %w", pid, err)
+	}
+
+	stats := ProcessStats{PID: pid}
+	for _, member := range p.tree(pid) {
+		usage, err := p.usage(member)
+		if err != nil {
+			if member == pid {
+				return ProcessStats{}, err
+			}
+			// Descendants can exit while the tree is being read
+			continue
+		}
+		stats.Processes++
+		stats.RSS += usage.RSS
+		stats.CPUTime += usage.CPUTime
+		stats.FDs += usage.FDs
+	}
+	return stats, nil
+}
+
+// usage reads the resource usage of a single process
+func (p Proc) usage(pid int) (ProcessStats, error) {
+	_, cpu, err := p.readStat(pid)
+	if err != nil {
+		return ProcessStats{}, err
+	}
+	rss, err := p.readRSS(pid)
+	if err != nil {
+		return ProcessStats{}, err
+	}
+	fds, err := p.countFDs(pid)
+	if err != nil {
+		return ProcessStats{}, err
+	}
+	return ProcessStats{PID: pid, Processes: 1, RSS: rss, CPUTime: cpu, FDs: fds}, nil
+}
+
+// tree returns a process and its descendants
+func (p Proc) tree(pid int) []int {
+	entries, err := os.ReadDir(p.Root)
+	if err != nil {
+		return []int{pid}
+	}
+	children := make(map[int][]int)
+	for _, entry := range entries {
+		child, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		parent, _, err := p.readStat(child)
+		if err != nil {
+			continue
+		}
+		children[parent] = append(children[parent], child)
+	}
+
+	tree := []int{pid}
+	for i := 0; i < len(tree); i++ {
+		tree = append(tree, children[tree[i]]...)
+	}
+	return tree
+}
+
+// readStat returns the parent and CPU time of a process from /proc/<pid>/stat
+func (p Proc) readStat(pid int) (int, time.Duration, error) {
+	data, err := os.ReadFile(filepath.Join(p.Root, strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, 0, err
+	}
+	// The command name is in parentheses and may contain spaces, so fields are counted after it
+	line := string(data)
+	end := strings.LastIndexByte(line, ')')
+	if end < 0 {
+		return 0, 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	fields := strings.Fields(line[end+1:])
+	// fields[0] is the state; ppid, utime and stime are fields 4, 14 and 15 of the whole line
+	if len(fields) < 13 {
+		return 0, 0, fmt.Errorf("malformed stat of process %d", pid)
+	}
+	ppid, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("malformed stat of process %d: %w", pid, err)
+	}
+	var ticks int64
+	for _, field := range fields[11:13] {
+		value, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return 0, 0, fmt.Errorf("malformed stat of process %d: %w", pid, err)
+		}
+		ticks += value
+	}
+	return ppid, time.Duration(ticks) * time.Second / clockTicks, nil
+}
+
+// readRSS returns the resident memory of a process from /proc/<pid>/status
+func (p Proc) readRSS(pid int) (uint64, error) {
+	file, err := os.Open(filepath.Join(p.Root, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "VmRSS:" {
+			continue
+		}
+		kb, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("malformed VmRSS of process %d: %w", pid, err)
+		}
+		return kb * 1024, nil
+	}
+	// Zombies and kernel threads have no resident memory
+	return 0, scanner.Err()
+}
+
+// countFDs counts the open file descriptors of a process
+func (p Proc) countFDs(pid int) (int, error) {
+	entries, err := os.ReadDir(filepath.Join(p.Root, strconv.Itoa(pid), "fd"))
+	if err != nil {
+		return 0, fmt.Errorf("failed to list file descriptors of process %d: %w", pid, err)
+	}
+	return len(entries), nil
+}
@@ -0,0 +1,62 @@
+package soak
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcess writes the procfs entries of a process under root
+func fakeProcess(t *testing.T, root string, pid, ppid int, rssKB, utime, stime, fds int) {
+	t.Helper()
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "fd"), 0o755))
+
+	stat := fmt.Sprintf("%d (my server) S %d %d %d 0 -1 4194560 100 0 0 0 %d %d 0 0 20 0 1 0 100 1000 %d\n",
+		pid, ppid, pid, pid, utime, stime, rssKB/4)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0o644))
+	status := fmt.Sprintf("Name:\tmy server\nState:\tS (sleeping)\nVmRSS:\t%8d kB\nThreads:\t1\n", rssKB)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "status"), []byte(status), 0o644))
+	for fd := 0; fd < fds; fd++ {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "fd", strconv.Itoa(fd)), nil, 0o644))
+	}
+}
+
+func TestProcStats(t *testing.T) {
+	root := t.TempDir()
+	fakeProcess(t, root, 100, 1, 2048, 150, 50, 5)
+	fakeProcess(t, root, 101, 100, 1024, 100, 0, 3)
+	fakeProcess(t, root, 102, 101, 512, 0, 0, 1)
+	fakeProcess(t, root, 200, 1, 4096, 0, 0, 9)
+	// Directories that are not processes are ignored
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "sys"), 0o755))
+
+	proc := Proc{Root: root}
+	stats, err := proc.Stats(100)
+	require.NoError(t, err)
+	assert.Equal(t, ProcessStats{PID: 100, Processes: 3, RSS: 3584 * 1024, CPUTime: 3 * time.Second, FDs: 9}, stats)
+
+	stats, err = proc.Stats(102)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.Processes)
+	assert.Equal(t, uint64(512*1024), stats.RSS)
+
+	_, err = proc.Stats(300)
+	assert.ErrorContains(t, err, "process 300 not found")
+}
+
+func TestProcStatsOwnProcess(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("no procfs")
+	}
+	stats, err := Proc{Root: "/proc"}.Stats(os.Getpid())
+	require.NoError(t, err)
+	assert.Greater(t, stats.RSS, uint64(0))
+	assert.Greater(t, stats.FDs, 0)
+}
@@ -0,0 +1,341 @@
+package soak
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"mcp_tstr/internal/bench"
+)
+
+// Units of trend values
+const (
+	UnitBytes   = "bytes"
+	UnitCount   = "count"
+	UnitPercent = "percent"
+	UnitMillis  = "ms"
+	UnitRatio   = "ratio"
+)
+
+// Report is the outcome of a soak test
+type Report struct {
+	Target        string             `json:"target"`
+	Interval      time.Duration      `json:"-"`
+	Warmup        time.Duration      `json:"-"`
+	Duration      time.Duration      `json:"-"`
+	Requests      int                `json:"requests"`
+	Errors        int                `json:"errors"`
+	ErrorRate     float64            `json:"error_rate"`
+	Restarts      int                `json:"restarts"`
+	Windows       []Window           `json:"windows"`
+	Trends        []Trend            `json:"trends"`
+	ErrorMessages []bench.ErrorCount `json:"error_messages,omitempty"`
+
+	errorCounts map[string]int
+}
+
+// Trend describes how a metric moved over the windows after the warm-up
+type Trend struct {
+	Metric  string `json:"metric"`
+	Unit    string `json:"unit"`
+	Windows int    `json:"windows"`
+	// First and Last average up to three windows at either end, which smooths out noise
+	First float64 `json:"first"`
+	Last  float64 `json:"last"`
+	// Change is the relative change from First to Last; it is zero when First is zero
+	Change       float64 `json:"change"`
+	SlopePerHour float64 `json:"slope_per_hour"`
+	// Tau is the Kendall rank correlation between time and value: 1 when the value rose in
+	// every window, -1 when it fell in every window
+	Tau     float64 `json:"tau"`
+	Growing bool    `json:"growing"`
+}
+
+// MarshalJSON encodes the durations in seconds
+func (r *Report) MarshalJSON() ([]byte, error) {
+	type plain Report
+	return json.Marshal(struct {
+		*plain
+		IntervalSeconds float64 `json:"interval_seconds"`
+		WarmupSeconds   float64 `json:"warmup_seconds"`
+		DurationSeconds float64 `json:"duration_seconds"`
+	}{(*plain)(r), r.Interval.Seconds(), r.Warmup.Seconds(), r.Duration.Seconds()})
+}
+
+// MarshalJSON encodes the time into the test in seconds
+func (w Window) MarshalJSON() ([]byte, error) {
+	type plain Window
+	return json.Marshal(struct {
+		plain
+		ElapsedSeconds float64 `json:"elapsed_seconds"`
+	}{plain(w), w.Elapsed.Seconds()})
+}
+
+// Growing returns the trends that count as growth
+func (r *Report) Growing() []Trend {
+	var growing []Trend
+	for _, trend := range r.Trends {
+		if trend.Growing {
+			growing = append(growing, trend)
+		}
+	}
+	return growing
+}
+
+// add records a finished window
+func (r *Report) add(window Window, result *bench.Result) {
+	r.Windows = append(r.Windows, window)
+	r.Requests += window.Requests
+	r.Errors += window.Errors
+	if r.Requests > 0 {
+		r.ErrorRate = float64(r.Errors) / float64(r.Requests)
+	}
+	if window.Process != nil && window.Process.Restarted {
+		r.Restarts++
+	}
+
+	if r.errorCounts == nil {
+		r.errorCounts = make(map[string]int)
+	}
+	for _, e := range result.ErrorMessages {
+		r.errorCounts[e.Message] += e.Count
+	}
+	r.ErrorMessages = r.ErrorMessages[:0]
+	for message, count := range r.errorCounts {
+		r.ErrorMessages = append(r.ErrorMessages, bench.ErrorCount{Message: message, Count: count})
+	}
+	sort.Slice(r.ErrorMessages, func(i, j int) bool {
+		if r.ErrorMessages[i].Count != r.ErrorMessages[j].Count {
+			return r.ErrorMessages[i].Count > r.ErrorMessages[j].Count
+		}
+		return r.ErrorMessages[i].Message < r.ErrorMessages[j].Message
+	})
+}
+
+// point is one window's value of a metric
+type point struct {
+	hours float64
+	value float64
+}
+
+// analyze computes the trends of every metric over the windows after the warm-up
+func (r *Report) analyze(opts Options) {
+	series := make(map[string][]point)
+	for _, w := range r.Windows {
+		if w.Elapsed < r.Warmup {
+			continue
+		}
+		hours := w.Elapsed.Hours()
+		add := func(metric string, value float64) {
+			series[metric] = append(series[metric], point{hours: hours, value: value})
+		}
+
+		if p := w.Process; p != nil {
+			add("rss", float64(p.RSS))
+			add("fds", float64(p.FDs))
+			// The CPU usage of a restarted process is unknown
+			if !p.Restarted {
+				add("cpu", p.CPU)
+			}
+		}
+		// Latencies only cover successful requests
+		if w.Requests > w.Errors {
+			add("p50", millis(w.Latency.P50))
+			add("p99", millis(w.Latency.P99))
+		}
+		if w.Requests > 0 {
+			add("error_rate", w.ErrorRate)
+		}
+	}
+
+	r.Trends = nil
+	for _, metric := range []struct{ name, unit string }{
+		{"rss", UnitBytes},
+		{"fds", UnitCount},
+		{"cpu", UnitPercent},
+		{"p50", UnitMillis},
+		{"p99", UnitMillis},
+		{"error_rate", UnitRatio},
+	} {
+		if points := series[metric.name]; len(points) > 0 {
+			r.Trends = append(r.Trends, trend(metric.name, metric.unit, points, opts))
+		}
+	}
+}
+
+// minTrendWindows is the fewest windows a trend needs to count as growth
+const minTrendWindows = 4
+
+// trend summarizes a series and decides whether it grows: it must rise in most windows, which
+// the rank correlation measures, and by a meaningful amount overall
+func trend(metric, unit string, points []point, opts Options) Trend {
+	ends := min(3, len(points))
+	t := Trend{
+		Metric:       metric,
+		Unit:         unit,
+		Windows:      len(points),
+		First:        mean(points[:ends]),
+		Last:         mean(points[len(points)-ends:]),
+		SlopePerHour: slope(points),
+		Tau:          kendallTau(points),
+	}
+	if t.First != 0 {
+		t.Change = (t.Last - t.First) / t.First
+	}
+
+	grew := t.Change >= opts.MinGrowth
+	if t.First == 0 {
+		grew = t.Last > 0
+	}
+	t.Growing = len(points) >= minTrendWindows && t.Tau >= opts.MinTau && grew
+	return t
+}
+
+// mean averages the values of the points
+func mean(points []point) float64 {
+	var total float64
+	for _, p := range points {
+		total += p.value
+	}
+	return total / float64(len(points))
+}
+
+// slope fits a least-squares line through the points and returns its slope per hour
+func slope(points []point) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	var sumX, sumY float64
+	for _, p := range points {
+		sumX += p.hours
+		sumY += p.value
+	}
+	n := float64(len(points))
+	meanX, meanY := sumX/n, sumY/n
+
+	var covariance, variance float64
+	for _, p := range points {
+		covariance += (p.hours - meanX) * (p.value - meanY)
+		variance += (p.hours - meanX) * (p.hours - meanX)
+	}
+	if variance == 0 {
+		return 0
+	}
+	return covariance / variance
+}
+
+// kendallTau is the Mann-Kendall statistic of the values in time order, scaled to [-1, 1]
+func kendallTau(points []point) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	var s int
+	for i := range points {
+		for j := i + 1; j < len(points); j++ {
+			switch {
+			case points[j].value > points[i].value:
+				s++
+			case points[j].value < points[i].value:
+				s--
+			}
+		}
+	}
+	pairs := len(points) * (len(points) - 1) / 2
+	return float64(s) / float64(pairs)
+}
+
+// WriteText writes a table of the windows followed by the trends
+func (r *Report) WriteText(w io.Writer) error {
+	fmt.Fprintf(w, "Soak test of %s for %s in windows of %s\n", r.Target, r.Duration.Round(time.Second), r.Interval)
+	fmt.Fprintf(w, "Requests: %d, errors: %d (%.2f%%), restarts: %d\n\n", r.Requests, r.Errors, r.ErrorRate*100, r.Restarts)
+
+	fmt.Fprintf(w, "  %-10s %9s %8s %10s %10s %11s %7s %5s\n", "ELAPSED", "REQUESTS", "ERRORS", "P50", "P99", "RSS", "CPU", "FDS")
+	for _, window := range r.Windows {
+		rss, cpu, fds := "-", "-", "-"
+		if p := window.Process; p != nil {
+			rss, fds = formatValue(UnitBytes, float64(p.RSS)), fmt.Sprint(p.FDs)
+			cpu = formatValue(UnitPercent, p.CPU)
+			if p.Restarted {
+				cpu = "restart"
+			}
+		}
+		fmt.Fprintf(w, "  %-10s %9d %8s %10s %10s %11s %7s %5s\n",
+			window.Elapsed.Round(time.Second), window.Requests, formatValue(UnitRatio, window.ErrorRate),
+			formatValue(UnitMillis, millis(window.Latency.P50)), formatValue(UnitMillis, millis(window.Latency.P99)), rss, cpu, fds)
+	}
+	for _, window := range r.Windows {
+		if window.SampleError != "" {
+			fmt.Fprintf(w, "\nThe server process could not be sampled: %s\n", window.SampleError)
+			break
+		}
+	}
+
+	if len(r.Trends) > 0 {
+		fmt.Fprintln(w)
+		if r.Warmup > 0 {
+			fmt.Fprintf(w, "Trends after a %s warm-up:\n", r.Warmup)
+		} else {
+			fmt.Fprintln(w, "Trends:")
+		}
+		for _, t := range r.Trends {
+			verdict := "stable"
+			switch {
+			case t.Growing:
+				verdict = "GROWING"
+			case t.Windows < minTrendWindows:
+				verdict = "too few windows"
+			}
+			fmt.Fprintf(w, "  %-10s %11s -> %-11s %8s %14s/h  tau %5.2f  %s\n", t.Metric,
+				formatValue(t.Unit, t.First), formatValue(t.Unit, t.Last), fmt.Sprintf("%+.1f%%", t.Change*100),
+				formatSigned(t.Unit, t.SlopePerHour), t.Tau, verdict)
+		}
+	}
+
+	if len(r.ErrorMessages) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Errors by message:")
+		for _, e := range r.ErrorMessages {
+			fmt.Fprintf(w, "  %6d  %s\n", e.Count, e.Message)
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(r)
+}
+
+// formatValue formats a metric value in its unit
+func formatValue(unit string, value float64) string {
+	switch unit {
+	case UnitBytes:
+		return fmt.Sprintf("%.1f MiB", value/(1<<20))
+	case UnitPercent:
+		return fmt.Sprintf("%.1f%%", value)
+	case UnitMillis:
+		return fmt.Sprintf("%.2fms", value)
+	case UnitRatio:
+		return fmt.Sprintf("%.2f%%", value*100)
+	}
+	return fmt.Sprintf("%.1f", value)
+}
+
+// formatSigned formats a change of a metric value with its sign
+func formatSigned(unit string, value float64) string {
+	formatted := formatValue(unit, math.Abs(value))
+	if value < 0 {
+		return "-" + formatted
+	}
+	return "+" + formatted
+}
+
+// millis converts a duration to fractional milliseconds
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
@@ -0,0 +1,143 @@
+// Package soak runs a workload against an MCP server for a long time, sampling the server
+// process's resources from /proc and reporting how they and the latencies trend
+package soak
+
+import (
+	"context"
+	"time"
+
+	"mcp_tstr/internal/bench"
+	"mcp_tstr/internal/mcp"
+)
+
+// Default thresholds for calling a trend growth
+const (
+	// DefaultMinGrowth is the smallest relative increase from the first to the last window
+	DefaultMinGrowth = 0.1
+	// DefaultMinTau is the smallest Kendall rank correlation between time and value, where 1
+	// means the value rose in every window
+	DefaultMinTau = 0.6
+)
+
+// Options controls a soak test
+type Options struct {
+	Duration time.Duration
+	// Interval is the length of a window; resources are sampled at the end of every window
+	Interval time.Duration
+	// Warmup leaves the windows that start before it out of the trends, as servers allocate
+	// caches and pools while they warm up
+	Warmup      time.Duration
+	Concurrency int
+	Rate        float64
+	Timeout     time.Duration
+	MinGrowth   float64
+	MinTau      float64
+}
+
+// PIDFunc returns the ID of the server process to sample; it is called for every window, so a
+// restarted server is followed
+type PIDFunc func() (int, error)
+
+// Process is the resource usage of the server at the end of a window
+type Process struct {
+	PID       int     `json:"pid"`
+	Processes int     `json:"processes"`
+	RSS       uint64  `json:"rss_bytes"`
+	CPU       float64 `json:"cpu_percent"`
+	FDs       int     `json:"fds"`
+	Restarted bool    `json:"restarted,omitempty"`
+}
+
+// Window is the outcome of one interval
+type Window struct {
+	Elapsed   time.Duration `json:"-"`
+	Requests  int           `json:"requests"`
+	Errors    int           `json:"errors"`
+	ErrorRate float64       `json:"error_rate"`
+	Latency   bench.Latency `json:"latency"`
+	Process   *Process      `json:"process,omitempty"`
+	// SampleError says why the process could not be sampled
+	SampleError string `json:"sample_error,omitempty"`
+}
+
+// Run sends the target to the client in windows of opts.Interval until opts.Duration has
+// passed or ctx is done, sampling the process pid returns after every window. pid may be nil
+// when there is no process to sample. progress, if set, is called after every window.
+func Run(ctx context.Context, client *mcp.Client, target bench.Target, pid PIDFunc, proc Proc, opts Options, progress func(Window)) *Report {
+	if opts.MinGrowth <= 0 {
+		opts.MinGrowth = DefaultMinGrowth
+	}
+	if opts.MinTau <= 0 {
+		opts.MinTau = DefaultMinTau
+	}
+
+	s := &sampler{pid: pid, proc: proc}
+	s.sample(time.Now())
+
+	report := &Report{Target: target.Name, Interval: opts.Interval, Warmup: opts.Warmup}
+	start := time.Now()
+	for ctx.Err() == nil {
+		remaining := opts.Duration - time.Since(start)
+		if remaining <= 0 {
+			break
+		}
+		elapsed := time.Since(start)
+		result := bench.Run(ctx, []*mcp.Client{client}, target, bench.Options{
+			Concurrency: opts.Concurrency,
+			Duration:    min(opts.Interval, remaining),
+			Rate:        opts.Rate,
+			Timeout:     opts.Timeout,
+		})
+
+		window := Window{
+			Elapsed:   elapsed,
+			Requests:  result.Requests,
+			Errors:    result.Errors,
+			ErrorRate: result.ErrorRate,
+			Latency:   result.Latency,
+		}
+		window.Process, window.SampleError = s.sample(time.Now())
+		report.add(window, result)
+		if progress != nil {
+			progress(window)
+		}
+	}
+	report.Duration = time.Since(start)
+	report.analyze(opts)
+	return report
+}
+
+// sampler turns successive process samples into CPU usage
+type sampler struct {
+	pid  PIDFunc
+	proc Proc
+
+	last     ProcessStats
+	lastTime time.Time
+}
+
+// sample reads the process and computes its CPU usage since the previous sample
+func (s *sampler) sample(now time.Time) (*Process, string) {
+	if s.pid == nil {
+		return nil, ""
+	}
+	pid, err := s.pid()
+	if err != nil {
+		return nil, err.Error()
+	}
+	stats, err := s.proc.Stats(pid)
+	if err != nil {
+		return nil, err.Error()
+	}
+
+	p := &Process{PID: stats.PID, Processes: stats.Processes, RSS: stats.RSS, FDs: stats.FDs}
+	switch {
+	case s.last.PID != 0 && s.last.PID != stats.PID:
+		// A new process has used no CPU time that belongs to the previous window
+		p.Restarted = true
+	case s.last.PID != 0 && now.After(s.lastTime):
+		p.CPU = 100 * (stats.CPUTime - s.last.CPUTime).Seconds() / now.Sub(s.lastTime).Seconds()
+	}
+	s.last, s.lastTime = stats, now
+	return p, ""
+}
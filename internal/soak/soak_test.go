@@ -0,0 +1,188 @@
+package soak
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"mcp_tstr/internal/bench"
+	"mcp_tstr/internal/mcptest"
+)
+
+func TestRun(t *testing.T) {
+	client := mcptest.MockClient(t)
+	root := t.TempDir()
+	fakeProcess(t, root, 100, 1, 1024, 0, 0, 4)
+
+	// The fake process leaks a file descriptor and 1 MiB per window
+	windows := 0
+	pid := func() (int, error) {
+		windows++
+		fakeProcess(t, root, 100, 1, 1024*(windows+1), 0, 0, 4+windows)
+		return 100, nil
+	}
+
+	var progress []Window
+	target := bench.Mixed(bench.Ping(), bench.CallTool("echo", map[string]interface{}{"text": "hi"}))
+	report := Run(context.Background(), client, target, pid, Proc{Root: root}, Options{
+		Duration: 500 * time.Millisecond,
+		Interval: 100 * time.Millisecond,
+		Rate:     50,
+	}, func(w Window) { progress = append(progress, w) })
+
+	assert.Equal(t, "mixed: ping, tools/call echo", report.Target)
+	// The last window may be cut short or left out when the windows overrun
+	require.GreaterOrEqual(t, len(report.Windows), 4)
+	assert.Equal(t, report.Windows, progress)
+	assert.Greater(t, report.Requests, 0)
+	assert.Zero(t, report.Errors)
+	assert.Zero(t, report.Restarts)
+	for i, w := range report.Windows {
+		require.NotNil(t, w.Process)
+		assert.Equal(t, 6+i, w.Process.FDs)
+		assert.Empty(t, w.SampleError)
+	}
+
+	var growing []string
+	for _, trend := range report.Growing() {
+		growing = append(growing, trend.Metric)
+	}
+	assert.Subset(t, growing, []string{"rss", "fds"})
+}
+
+func TestRunWithoutProcess(t *testing.T) {
+	client := mcptest.MockClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	report := Run(ctx, client, bench.Ping(), func() (int, error) {
+		return 0, fmt.Errorf("server gone")
+	}, Proc{Root: t.TempDir()}, Options{Duration: time.Hour, Interval: 50 * time.Millisecond}, func(w Window) {
+		if len(w.SampleError) > 0 {
+			cancel()
+		}
+	})
+
+	// Cancelling ends the test after the current window
+	require.Len(t, report.Windows, 1)
+	assert.Nil(t, report.Windows[0].Process)
+	assert.Equal(t, "server gone", report.Windows[0].SampleError)
+	for _, trend := range report.Trends {
+		assert.NotContains(t, []string{"rss", "fds", "cpu"}, trend.Metric)
+	}
+}
+
+func TestSamplerRestart(t *testing.T) {
+	root := t.TempDir()
+	fakeProcess(t, root, 100, 1, 1024, 100, 0, 1)
+	fakeProcess(t, root, 200, 1, 1024, 0, 0, 1)
+
+	current := 100
+	s := &sampler{pid: func() (int, error) { return current, nil }, proc: Proc{Root: root}}
+	start := time.Now()
+	first, _ := s.sample(start)
+	require.NotNil(t, first)
+	assert.Zero(t, first.CPU)
+
+	// One second of CPU time over two seconds
+	fakeProcess(t, root, 100, 1, 1024, 200, 0, 1)
+	second, _ := s.sample(start.Add(2 * time.Second))
+	assert.InDelta(t, 50, second.CPU, 0.001)
+	assert.False(t, second.Restarted)
+
+	current = 200
+	third, _ := s.sample(start.Add(3 * time.Second))
+	assert.True(t, third.Restarted)
+	assert.Zero(t, third.CPU)
+}
+
+// series builds points one hour apart
+func series(values ...float64) []point {
+	points := make([]point, len(values))
+	for i, value := range values {
+		points[i] = point{hours: float64(i), value: value}
+	}
+	return points
+}
+
+func TestTrend(t *testing.T) {
+	opts := Options{MinGrowth: DefaultMinGrowth, MinTau: DefaultMinTau}
+
+	tests := []struct {
+		name    string
+		values  []float64
+		growing bool
+	}{
+		{name: "steady leak", values: []float64{100, 110, 120, 130, 140, 150}, growing: true},
+		{name: "noisy leak", values: []float64{100, 120, 115, 140, 135, 160, 170}, growing: true},
+		{name: "flat", values: []float64{100, 101, 100, 99, 100, 101}},
+		{name: "slow creep below threshold", values: []float64{100, 101, 102, 103, 104, 105}},
+		{name: "one spike", values: []float64{100, 100, 300, 100, 100, 100}},
+		{name: "too few windows", values: []float64{100, 200, 300}},
+		{name: "errors appear", values: []float64{0, 0, 0, 0.1, 0.2, 0.3}, growing: true},
+		{name: "falling", values: []float64{150, 140, 130, 120, 110, 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := trend("rss", UnitBytes, series(tt.values...), opts)
+			assert.Equal(t, tt.growing, got.Growing)
+			assert.Equal(t, len(tt.values), got.Windows)
+		})
+	}
+}
+
+func TestTrendStatistics(t *testing.T) {
+	got := trend("fds", UnitCount, series(10, 12, 14, 16, 18, 20), Options{MinGrowth: 0.1, MinTau: 0.6})
+	assert.Equal(t, 12.0, got.First)
+	assert.Equal(t, 18.0, got.Last)
+	assert.InDelta(t, 0.5, got.Change, 1e-9)
+	assert.InDelta(t, 2, got.SlopePerHour, 1e-9)
+	assert.Equal(t, 1.0, got.Tau)
+
+	assert.Equal(t, -1.0, kendallTau(series(3, 2, 1)))
+	assert.Equal(t, 0.0, kendallTau(series(1)))
+	assert.Equal(t, 0.0, slope(series(5)))
+}
+
+func TestReportOutput(t *testing.T) {
+	report := &Report{Target: "ping", Interval: time.Minute, Warmup: time.Minute}
+	for i := 0; i < 6; i++ {
+		report.add(Window{
+			Elapsed:   time.Duration(i) * time.Minute,
+			Requests:  100,
+			Errors:    i,
+			ErrorRate: float64(i) / 100,
+			Latency:   bench.Latency{P50: time.Millisecond, P99: 5 * time.Millisecond},
+			Process:   &Process{PID: 100, Processes: 1, RSS: uint64(i+1) << 20, FDs: 10},
+		}, &bench.Result{ErrorMessages: []bench.ErrorCount{{Message: "timeout", Count: i}}})
+	}
+	report.Duration = 6 * time.Minute
+	report.analyze(Options{MinGrowth: DefaultMinGrowth, MinTau: DefaultMinTau})
+
+	assert.Equal(t, 600, report.Requests)
+	assert.Equal(t, 15, report.Errors)
+	assert.Equal(t, []bench.ErrorCount{{Message: "timeout", Count: 15}}, report.ErrorMessages)
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "Soak test of ping for 6m0s in windows of 1m0s")
+	assert.Contains(t, buf.String(), "Trends after a 1m0s warm-up:")
+	assert.Regexp(t, `rss\s+3\.0 MiB -> 5\.0 MiB .* GROWING`, buf.String())
+	assert.Regexp(t, `fds\s+10\.0 -> 10\.0 .* stable`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, 360.0, decoded["duration_seconds"])
+	window := decoded["windows"].([]interface{})[1].(map[string]interface{})
+	assert.Equal(t, 60.0, window["elapsed_seconds"])
+	assert.Equal(t, 1.0, window["latency"].(map[string]interface{})["p50_ms"])
+	assert.Equal(t, float64(2<<20), window["process"].(map[string]interface{})["rss_bytes"])
+}
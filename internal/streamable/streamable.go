@@ -0,0 +1,23 @@
+// Package streamable holds what the servers and clients of the streamable HTTP transport share
+package streamable
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+)
+
+// Streamable HTTP headers
+const (
+	HeaderSessionID       = "Mcp-Session-Id"
+	HeaderProtocolVersion = "MCP-Protocol-Version"
+)
+
+// NewSessionID returns a random session ID
+func NewSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to create session ID: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
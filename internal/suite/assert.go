@@ -0,0 +1,300 @@
+package suite
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonschema"
+	"gopkg.in/yaml.v3"
+
+	"mcp_tstr/internal/schema"
+)
+
+// maxShownLength bounds how much of a value failure messages quote
+const maxShownLength = 200
+
+// Assertion is a single check on a step's result. Without a path, equals, contains and regex
+// check the result's text and schema checks its structured content (or, for resources and
+// prompts, the whole result). With a JSONPath, they check the selected value of the result's
+// JSON; a path selecting several values (wildcards, ..) checks the list of them.
+type Assertion struct {
+	Path       string
+	Equals     interface{}
+	Contains   interface{}
+	Regex      *regexp.Regexp
+	Schema     *jsonschema.Schema
+	Exists     *bool
+	IsError    *bool
+	MaxLatency time.Duration
+
+	hasEquals   bool
+	hasContains bool
+}
+
+// assertionKeys are the fields an assertion may have
+var assertionKeys = map[string]bool{
+	"path": true, "equals": true, "contains": true, "regex": true, "schema": true,
+	"exists": true, "is_error": true, "max_latency": true,
+}
+
+// UnmarshalYAML decodes an assertion, telling an absent equals apart from equals: null
+func (a *Assertion) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: assertion must be a mapping", node.Line)
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i]; !assertionKeys[key.Value] {
+			return fmt.Errorf("line %d: unknown assertion field %q", key.Line, key.Value)
+		}
+	}
+
+	var raw struct {
+		Path       string    `yaml:"path"`
+		Equals     yaml.Node `yaml:"equals"`
+		Contains   yaml.Node `yaml:"contains"`
+		Regex      *string   `yaml:"regex"`
+		Schema     yaml.Node `yaml:"schema"`
+		Exists     *bool     `yaml:"exists"`
+		IsError    *bool     `yaml:"is_error"`
+		MaxLatency string    `yaml:"max_latency"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+
+	*a = Assertion{Path: raw.Path, Exists: raw.Exists, IsError: raw.IsError}
+	checks := 0
+	var err error
+	if raw.Equals.Kind != 0 {
+		checks++
+		a.hasEquals = true
+		if a.Equals, err = decodeValue(&raw.Equals); err != nil {
+			return err
+		}
+	}
+	if raw.Contains.Kind != 0 {
+		checks++
+		a.hasContains = true
+		if a.Contains, err = decodeValue(&raw.Contains); err != nil {
+			return err
+		}
+	}
+	if raw.Regex != nil {
+		checks++
+		if a.Regex, err = regexp.Compile(*raw.Regex); err != nil {
+			return fmt.Errorf("line %d: invalid regex: %w", node.Line, err)
+		}
+	}
+	if raw.Schema.Kind != 0 {
+		checks++
+		if a.Schema, err = decodeSchema(&raw.Schema); err != nil {
+			return err
+		}
+	}
+	if raw.MaxLatency != "" {
+		checks++
+		if a.MaxLatency, err = time.ParseDuration(raw.MaxLatency); err != nil {
+			return fmt.Errorf("line %d: invalid max_latency: %w", node.Line, err)
+		}
+	}
+	if raw.Exists != nil {
+		checks++
+	}
+	if raw.IsError != nil {
+		checks++
+	}
+
+	switch {
+	case checks != 1:
+		return fmt.Errorf("line %d: assertion must have exactly one of equals, contains, regex, schema, exists, is_error or max_latency", node.Line)
+	case a.Path != "" && (a.IsError != nil || a.MaxLatency != 0):
+		return fmt.Errorf("line %d: path cannot be combined with is_error or max_latency", node.Line)
+	case a.Exists != nil && a.Path == "":
+		return fmt.Errorf("line %d: exists needs a path", node.Line)
+	}
+	if a.Path != "" {
+		if _, err := parsePath(a.Path); err != nil {
+			return fmt.Errorf("line %d: %w", node.Line, err)
+		}
+	}
+	return nil
+}
+
+// decodeValue decodes a YAML value into the types encoding/json produces
+func decodeValue(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+	if err := node.Decode(&value); err != nil {
+		return nil, err
+	}
+	normalized, err := schema.Normalize(value)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", node.Line, err)
+	}
+	return normalized, nil
+}
+
+// decodeSchema decodes a JSON Schema written in YAML
+func decodeSchema(node *yaml.Node) (*jsonschema.Schema, error) {
+	value, err := decodeValue(node)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var s jsonschema.Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("line %d: invalid schema: %w", node.Line, err)
+	}
+	return &s, nil
+}
+
+// outcome is what a step produced, in the forms assertions inspect
+type outcome struct {
+	kind string
+	// text is the concatenated text of the result's content, messages or resource contents
+	text string
+	// document is the whole result decoded from JSON
+	document   interface{}
+	structured interface{}
+	isError    bool
+	latency    time.Duration
+}
+
+// check returns an error describing how the outcome fails the assertion
+func (a *Assertion) check(o *outcome) error {
+	switch {
+	case a.IsError != nil:
+		if o.isError != *a.IsError {
+			return fmt.Errorf("expected isError %v, got %v", *a.IsError, o.isError)
+		}
+		return nil
+	case a.MaxLatency != 0:
+		if o.latency > a.MaxLatency {
+			return fmt.Errorf("took %s, more than max_latency %s", o.latency.Round(time.Microsecond), a.MaxLatency)
+		}
+		return nil
+	}
+
+	subject, name, isText, err := a.subject(o)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case a.Exists != nil:
+		// subject reports whether the path matched
+		if exists := subject.(bool); exists != *a.Exists {
+			if *a.Exists {
+				return fmt.Errorf("expected %s to exist", a.Path)
+			}
+			return fmt.Errorf("expected %s not to exist", a.Path)
+		}
+	case a.hasEquals:
+		if isText {
+			if expected := stringify(a.Equals); subject != expected {
+				return fmt.Errorf("expected %s to equal %s, got %s", name, show(expected), show(subject))
+			}
+		} else if !reflect.DeepEqual(subject, a.Equals) {
+			return fmt.Errorf("expected %s to equal %s, got %s", name, show(a.Equals), show(subject))
+		}
+	case a.hasContains:
+		if !contains(subject, a.Contains) {
+			return fmt.Errorf("expected %s to contain %s, got %s", name, show(a.Contains), show(subject))
+		}
+	case a.Regex != nil:
+		if !a.Regex.MatchString(stringify(subject)) {
+			return fmt.Errorf("expected %s to match /%s/, got %s", name, a.Regex, show(subject))
+		}
+	case a.Schema != nil:
+		if err := schema.Validate(a.Schema, subject); err != nil {
+			return fmt.Errorf("%s does not match the schema: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// subject selects the value an assertion checks and names it for messages
+func (a *Assertion) subject(o *outcome) (interface{}, string, bool, error) {
+	if a.Path != "" {
+		values, definite, err := evalPath(o.document, a.Path)
+		if err != nil {
+			return nil, "", false, err
+		}
+		if a.Exists != nil {
+			return len(values) > 0, a.Path, false, nil
+		}
+		if len(values) == 0 {
+			return nil, "", false, fmt.Errorf("%s matched nothing", a.Path)
+		}
+		if definite {
+			return values[0], a.Path, false, nil
+		}
+		return values, a.Path, false, nil
+	}
+
+	if a.Schema != nil {
+		if o.kind != KindCallTool {
+			return o.document, "result", false, nil
+		}
+		if o.structured == nil {
+			return nil, "", false, fmt.Errorf("result has no structured content to check against the schema")
+		}
+		return o.structured, "structured content", false, nil
+	}
+	return o.text, "text", true, nil
+}
+
+// contains checks for a substring in strings, an element in arrays and a key in objects. Other
+// values are compared by their JSON encoding.
+func contains(subject, expected interface{}) bool {
+	switch v := subject.(type) {
+	case string:
+		return strings.Contains(v, stringify(expected))
+	case []interface{}:
+		for _, item := range v {
+			if reflect.DeepEqual(item, expected) {
+				return true
+			}
+		}
+		return false
+	case map[string]interface{}:
+		key, ok := expected.(string)
+		if !ok {
+			return false
+		}
+		_, found := v[key]
+		return found
+	}
+	return strings.Contains(stringify(subject), stringify(expected))
+}
+
+// stringify returns strings as they are and other values as compact JSON
+func stringify(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+// show quotes a value for a failure message, shortening long ones
+func show(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	text := string(data)
+	if len(text) > maxShownLength {
+		text = text[:maxShownLength] + "..."
+	}
+	return text
+}
@@ -0,0 +1,201 @@
+package suite
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathSegment is one selector of a JSONPath expression
+type pathSegment struct {
+	// recursive applies the selector to the node and all of its descendants (..)
+	recursive bool
+	wildcard  bool
+	isIndex   bool
+	index     int
+	name      string
+}
+
+// parsePath parses the JSONPath subset used by assertions: $, .name, ['name'], [n] (negative
+// counts from the end), [*], .* and recursive descent with ..
+func parsePath(path string) ([]pathSegment, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q: must start with $", path)
+	}
+
+	var segments []pathSegment
+	rest := path[1:]
+	for rest != "" {
+		var segment pathSegment
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			segment.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			name, remaining := splitName(rest)
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing name after ..", path)
+			}
+			segment.wildcard = name == "*"
+			segment.name = name
+			segments = append(segments, segment)
+			rest = remaining
+			continue
+		case strings.HasPrefix(rest, "."):
+			name, remaining := splitName(rest[1:])
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q: missing name after .", path)
+			}
+			segment.wildcard = name == "*"
+			segment.name = name
+			segments = append(segments, segment)
+			rest = remaining
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("invalid JSONPath %q: unexpected %q", path, rest)
+		}
+
+		end := closingBracket(rest)
+		if end < 0 {
+			return nil, fmt.Errorf("invalid JSONPath %q: unclosed [", path)
+		}
+		selector := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+
+		switch {
+		case selector == "*":
+			segment.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			segment.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid JSONPath %q: bad selector [%s]", path, selector)
+			}
+			segment.isIndex = true
+			segment.index = index
+		}
+		segments = append(segments, segment)
+	}
+	return segments, nil
+}
+
+// splitName splits a dotted member name from the rest of a path
+func splitName(s string) (string, string) {
+	end := strings.IndexAny(s, ".[")
+	if end < 0 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// closingBracket finds the ] closing the bracket at the start of s, skipping quoted names
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// evalPath returns the values a JSONPath selects from a JSON document. definite is false when
+// the path uses wildcards or recursive descent and may select any number of values.
+func evalPath(document interface{}, path string) (values []interface{}, definite bool, err error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, false, err
+	}
+
+	definite = true
+	nodes := []interface{}{document}
+	for _, segment := range segments {
+		if segment.recursive || segment.wildcard {
+			definite = false
+		}
+
+		var candidates []interface{}
+		if segment.recursive {
+			for _, node := range nodes {
+				candidates = appendDescendants(candidates, node)
+			}
+		} else {
+			candidates = nodes
+		}
+
+		var next []interface{}
+		for _, node := range candidates {
+			next = append(next, selectChildren(node, segment)...)
+		}
+		nodes = next
+	}
+	return nodes, definite, nil
+}
+
+// selectChildren applies one selector to a node
+func selectChildren(node interface{}, segment pathSegment) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if segment.wildcard {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			children := make([]interface{}, 0, len(keys))
+			for _, key := range keys {
+				children = append(children, v[key])
+			}
+			return children
+		}
+		if child, ok := v[segment.name]; ok && !segment.isIndex {
+			return []interface{}{child}
+		}
+	case []interface{}:
+		if segment.wildcard {
+			return v
+		}
+		if segment.isIndex {
+			index := segment.index
+			if index < 0 {
+				index += len(v)
+			}
+			if index >= 0 && index < len(v) {
+				return []interface{}{v[index]}
+			}
+		}
+	}
+	return nil
+}
+
+// appendDescendants appends a node and every node below it, depth first
+func appendDescendants(nodes []interface{}, node interface{}) []interface{} {
+	nodes = append(nodes, node)
+	switch v := node.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			nodes = appendDescendants(nodes, v[key])
+		}
+	case []interface{}:
+		for _, item := range v {
+			nodes = appendDescendants(nodes, item)
+		}
+	}
+	return nodes
+}
@@ -0,0 +1,49 @@
+package suite
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalPath(t *testing.T) {
+	document := map[string]interface{}{
+		"content": []interface{}{
+			map[string]interface{}{"type": "text", "text": "one"},
+			map[string]interface{}{"type": "text", "text": "two"},
+		},
+		"structuredContent": map[string]interface{}{"a b": 1.0, "nested": map[string]interface{}{"text": "three"}},
+	}
+
+	tests := []struct {
+		path     string
+		values   []interface{}
+		definite bool
+	}{
+		{"$", []interface{}{document}, true},
+		{"$.content[0].text", []interface{}{"one"}, true},
+		{"$.content[-1].text", []interface{}{"two"}, true},
+		{"$['structuredContent'][\"a b\"]", []interface{}{1.0}, true},
+		{"$.content[*].text", []interface{}{"one", "two"}, false},
+		{"$..text", []interface{}{"one", "two", "three"}, false},
+		{"$.content[5]", nil, true},
+		{"$.missing.deeper", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			values, definite, err := evalPath(document, tt.path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.values, values)
+			assert.Equal(t, tt.definite, definite)
+		})
+	}
+}
+
+func TestParsePathErrors(t *testing.T) {
+	for _, path := range []string{"content", "$.", "$[", "$[x]", "$..", "$content"} {
+		_, err := parsePath(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -0,0 +1,186 @@
+package suite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"mcp_tstr/internal/render"
+	"mcp_tstr/internal/schema"
+)
+
+// Client is the part of an MCP client that test steps use
+type Client interface {
+	CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*sdk.CallToolResult, error)
+	ReadResource(ctx context.Context, uri string) (*sdk.ReadResourceResult, error)
+	GetPrompt(ctx context.Context, name string, arguments map[string]string) (*sdk.GetPromptResult, error)
+}
+
+// CaseResult is the outcome of one test case
+type CaseResult struct {
+	Suite    string
+	Name     string
+	Server   string
+	Duration time.Duration
+	// Failures describes every failed assertion or request, prefixed with its step
+	Failures []string
+}
+
+// Passed reports whether every step of the case succeeded
+func (r CaseResult) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Runner runs test suites, looking up a client for each case's server
+type Runner struct {
+	clients       func(server string) (Client, error)
+	defaultServer string
+}
+
+// NewRunner creates a runner. Cases that name no server, in suites that name none either, run
+// against defaultServer.
+func NewRunner(clients func(server string) (Client, error), defaultServer string) *Runner {
+	return &Runner{clients: clients, defaultServer: defaultServer}
+}
+
+// Run runs every case of a suite in order, passing each result to report as it completes
+func (r *Runner) Run(ctx context.Context, s *Suite, report func(CaseResult)) []CaseResult {
+	results := make([]CaseResult, 0, len(s.Cases))
+	for _, c := range s.Cases {
+		result := r.runCase(ctx, s, c)
+		if report != nil {
+			report(result)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runCase runs the steps of a case until one fails
+func (r *Runner) runCase(ctx context.Context, s *Suite, c Case) CaseResult {
+	start := time.Now()
+	result := CaseResult{Suite: s.Name, Name: c.Name, Server: s.serverFor(c, r.defaultServer)}
+
+	if result.Server == "" {
+		result.Failures = []string{"no server given and no default server configured"}
+		result.Duration = time.Since(start)
+		return result
+	}
+	client, err := r.clients(result.Server)
+	if err != nil {
+		result.Failures = []string{err.Error()}
+		result.Duration = time.Since(start)
+		return result
+	}
+
+	for i, step := range c.Steps {
+		failures := runStep(ctx, client, step)
+		for _, failure := range failures {
+			result.Failures = append(result.Failures, fmt.Sprintf("step %d (%s): %s", i+1, step.Label(), failure))
+		}
+		if len(failures) > 0 {
+			break
+		}
+	}
+	result.Duration = time.Since(start)
+	return result
+}
+
+// runStep performs a step's request and returns every assertion it fails
+func runStep(ctx context.Context, client Client, step Step) []string {
+	o, err := perform(ctx, client, step)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var failures []string
+	expectsError := false
+	for _, assertion := range step.Expect {
+		if assertion.IsError != nil {
+			expectsError = true
+		}
+		if err := assertion.check(o); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	// A tool error fails the step unless the step states what isError should be
+	if o.isError && !expectsError {
+		failures = append([]string{"tool returned an error: " + o.text}, failures...)
+	}
+	return failures
+}
+
+// perform sends a step's request and collects its result
+func perform(ctx context.Context, client Client, step Step) (*outcome, error) {
+	kind, target := step.Kind()
+	o := &outcome{kind: kind}
+	start := time.Now()
+
+	var result interface{}
+	var texts []string
+	switch kind {
+	case KindCallTool:
+		arguments := step.Arguments
+		if arguments == nil {
+			arguments = map[string]interface{}{}
+		}
+		toolResult, err := client.CallTool(ctx, target, arguments)
+		if err != nil {
+			return nil, fmt.Errorf("call_tool failed: %w", err)
+		}
+		o.latency = time.Since(start)
+		result = toolResult
+		o.isError = toolResult.IsError
+		for _, content := range toolResult.Content {
+			texts = append(texts, render.PlainText(content))
+		}
+		if toolResult.StructuredContent != nil {
+			if o.structured, err = schema.Normalize(toolResult.StructuredContent); err != nil {
+				return nil, err
+			}
+		}
+	case KindReadResource:
+		resourceResult, err := client.ReadResource(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("read_resource failed: %w", err)
+		}
+		o.latency = time.Since(start)
+		result = resourceResult
+		for _, contents := range resourceResult.Contents {
+			texts = append(texts, contents.Text)
+		}
+	case KindGetPrompt:
+		promptResult, err := client.GetPrompt(ctx, target, promptArguments(step.Arguments))
+		if err != nil {
+			return nil, fmt.Errorf("get_prompt failed: %w", err)
+		}
+		o.latency = time.Since(start)
+		result = promptResult
+		for _, message := range promptResult.Messages {
+			texts = append(texts, render.PlainText(message.Content))
+		}
+	}
+
+	o.text = strings.Join(texts, "\n")
+	document, err := schema.Normalize(result)
+	if err != nil {
+		return nil, err
+	}
+	o.document = document
+	return o, nil
+}
+
+// promptArguments converts step arguments to the strings prompts take
+func promptArguments(arguments map[string]interface{}) map[string]string {
+	if len(arguments) == 0 {
+		return nil
+	}
+	converted := make(map[string]string, len(arguments))
+	for name, value := range arguments {
+		converted[name] = stringify(value)
+	}
+	return converted
+}
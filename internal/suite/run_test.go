@@ -0,0 +1,147 @@
+package suite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubClient answers requests with canned results
+type stubClient struct {
+	calls []string
+}
+
+func (c *stubClient) CallTool(ctx context.Context, name string, arguments map[string]interface{}) (*sdk.CallToolResult, error) {
+	c.calls = append(c.calls, name)
+	switch name {
+	case "weather":
+		return &sdk.CallToolResult{
+			Content:           []sdk.Content{&sdk.TextContent{Text: "21.5C in " + arguments["location"].(string)}},
+			StructuredContent: map[string]interface{}{"temperature": 21.5},
+		}, nil
+	case "broken":
+		return &sdk.CallToolResult{Content: []sdk.Content{&sdk.TextContent{Text: "boom"}}, IsError: true}, nil
+	}
+	return nil, errors.New("unknown tool")
+}
+
+func (c *stubClient) ReadResource(ctx context.Context, uri string) (*sdk.ReadResourceResult, error) {
+	return &sdk.ReadResourceResult{Contents: []*sdk.ResourceContents{{URI: uri, MIMEType: "text/plain", Text: "hello"}}}, nil
+}
+
+func (c *stubClient) GetPrompt(ctx context.Context, name string, arguments map[string]string) (*sdk.GetPromptResult, error) {
+	return &sdk.GetPromptResult{Messages: []*sdk.PromptMessage{
+		{Role: "user", Content: &sdk.TextContent{Text: "Summarize " + arguments["topic"]}},
+	}}, nil
+}
+
+func runSuite(t *testing.T, input string) ([]CaseResult, *stubClient) {
+	t.Helper()
+	s, err := Parse([]byte(input))
+	require.NoError(t, err)
+
+	client := &stubClient{}
+	runner := NewRunner(func(server string) (Client, error) {
+		if server != "stub" {
+			return nil, errors.New("server " + server + " not found")
+		}
+		return client, nil
+	}, "stub")
+	return runner.Run(context.Background(), s, nil), client
+}
+
+func TestRunPassingAssertions(t *testing.T) {
+	results, _ := runSuite(t, `
+cases:
+  - name: tool
+    steps:
+      - call_tool: weather
+        arguments: {location: Paris}
+        expect:
+          - equals: 21.5C in Paris
+          - contains: Paris
+          - regex: '^\d+\.\dC'
+          - schema: {type: object, required: [temperature]}
+          - path: $.structuredContent.temperature
+            equals: 21.5
+          - path: $.structuredContent
+            contains: temperature
+          - path: $.content[*].type
+            contains: text
+          - path: $.content[0].text
+            regex: Paris$
+          - path: $.isError
+            exists: false
+          - is_error: false
+          - max_latency: 1m
+      - call_tool: broken
+        expect:
+          - is_error: true
+          - contains: boom
+  - name: resource and prompt
+    steps:
+      - read_resource: file:///greeting
+        expect:
+          - equals: hello
+          - path: $.contents[0].mimeType
+            equals: text/plain
+          - schema: {type: object, required: [contents]}
+      - get_prompt: summarize
+        arguments: {topic: MCP}
+        expect:
+          - equals: Summarize MCP
+`)
+
+	require.Len(t, results, 2)
+	for _, result := range results {
+		assert.True(t, result.Passed(), "%s: %v", result.Name, result.Failures)
+		assert.Equal(t, "stub", result.Server)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	results, client := runSuite(t, `
+cases:
+  - name: wrong values
+    steps:
+      - call_tool: weather
+        arguments: {location: Paris}
+        expect:
+          - equals: 20C
+          - path: $.structuredContent.temperature
+            schema: {type: string}
+          - path: $.structuredContent.wind
+            equals: 3
+          - is_error: true
+      - call_tool: weather
+        arguments: {location: Rome}
+  - name: unexpected tool error
+    steps:
+      - call_tool: broken
+  - name: request fails
+    steps:
+      - call_tool: missing
+  - name: unknown server
+    server: ghost
+    steps:
+      - read_resource: file:///x
+`)
+
+	require.Len(t, results, 4)
+	assert.Equal(t, []string{
+		`step 1 (call_tool weather): expected text to equal "20C", got "21.5C in Paris"`,
+		"step 1 (call_tool weather): $.structuredContent.temperature does not match the schema: $: type: 21.5 has type \"number\", want \"string\"",
+		"step 1 (call_tool weather): $.structuredContent.wind matched nothing",
+		"step 1 (call_tool weather): expected isError true, got false",
+	}, results[0].Failures)
+	assert.Equal(t, []string{"step 1 (call_tool broken): tool returned an error: boom"}, results[1].Failures)
+	assert.Equal(t, []string{"step 1 (call_tool missing): call_tool failed: unknown tool"}, results[2].Failures)
+	assert.Equal(t, []string{"server ghost not found"}, results[3].Failures)
+
+	// The failing first step stops the case before the second weather call
+	assert.Equal(t, []string{"weather", "broken", "missing"}, client.calls)
+}
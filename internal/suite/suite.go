@@ -0,0 +1,190 @@
+// Package suite runs declarative YAML test suites against MCP servers
+package suite
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a YAML test file: a list of cases run against one or more servers
+type Suite struct {
+	Name string `yaml:"name"`
+	// Server is used by cases that do not name their own
+	Server string `yaml:"server"`
+	Cases  []Case `yaml:"cases"`
+
+	// Path is the file the suite was loaded from
+	Path string `yaml:"-"`
+}
+
+// Case is a named sequence of steps. A case passes when every step's assertions hold; it stops
+// at the first failing step since later steps often depend on earlier ones.
+type Case struct {
+	Name   string `yaml:"name"`
+	Server string `yaml:"server"`
+	Steps  []Step `yaml:"steps"`
+}
+
+// Step performs exactly one request and checks its result
+type Step struct {
+	Name         string                 `yaml:"name"`
+	CallTool     string                 `yaml:"call_tool"`
+	ReadResource string                 `yaml:"read_resource"`
+	GetPrompt    string                 `yaml:"get_prompt"`
+	Arguments    map[string]interface{} `yaml:"arguments"`
+	Expect       []Assertion            `yaml:"expect"`
+}
+
+// Step kinds
+const (
+	KindCallTool     = "call_tool"
+	KindReadResource = "read_resource"
+	KindGetPrompt    = "get_prompt"
+)
+
+// Kind returns which request the step performs and its target
+func (s Step) Kind() (string, string) {
+	switch {
+	case s.CallTool != "":
+		return KindCallTool, s.CallTool
+	case s.ReadResource != "":
+		return KindReadResource, s.ReadResource
+	case s.GetPrompt != "":
+		return KindGetPrompt, s.GetPrompt
+	}
+	return "", ""
+}
+
+// Label describes the step for reports
+func (s Step) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	kind, target := s.Kind()
+	return kind + " " + target
+}
+
+// Servers returns the sorted names of the servers a suite uses. Cases without a server use
+// the suite's server, then defaultServer.
+func (s *Suite) Servers(defaultServer string) []string {
+	seen := make(map[string]bool)
+	var servers []string
+	for _, c := range s.Cases {
+		if server := s.serverFor(c, defaultServer); server != "" && !seen[server] {
+			seen[server] = true
+			servers = append(servers, server)
+		}
+	}
+	sort.Strings(servers)
+	return servers
+}
+
+// serverFor returns the server a case runs against
+func (s *Suite) serverFor(c Case, defaultServer string) string {
+	switch {
+	case c.Server != "":
+		return c.Server
+	case s.Server != "":
+		return s.Server
+	}
+	return defaultServer
+}
+
+// Load reads and checks a suite file
+func Load(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read test suite: %w", err)
+	}
+	suite, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	suite.Path = path
+	if suite.Name == "" {
+		suite.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return suite, nil
+}
+
+// Parse decodes a suite from YAML and checks that every step is well formed
+func Parse(data []byte) (*Suite, error) {
+	var suite Suite
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("failed to parse test suite: %w", err)
+	}
+
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("test suite has no cases")
+	}
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			return nil, fmt.Errorf("case %d has no name", i+1)
+		}
+		if len(c.Steps) == 0 {
+			return nil, fmt.Errorf("case %q has no steps", c.Name)
+		}
+		for j, step := range c.Steps {
+			if err := checkStep(step); err != nil {
+				return nil, fmt.Errorf("case %q, step %d: %w", c.Name, j+1, err)
+			}
+		}
+	}
+	return &suite, nil
+}
+
+// checkStep ensures a step performs exactly one request and its assertions apply to it
+func checkStep(step Step) error {
+	actions := 0
+	for _, target := range []string{step.CallTool, step.ReadResource, step.GetPrompt} {
+		if target != "" {
+			actions++
+		}
+	}
+	if actions != 1 {
+		return fmt.Errorf("expected exactly one of %s, %s or %s", KindCallTool, KindReadResource, KindGetPrompt)
+	}
+
+	kind, _ := step.Kind()
+	for _, assertion := range step.Expect {
+		if assertion.IsError != nil && kind != KindCallTool {
+			return fmt.Errorf("is_error only applies to %s", KindCallTool)
+		}
+	}
+	return nil
+}
+
+// Files expands paths into suite files: directories contribute their .yaml and .yml files
+func Files(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find test suite: %w", err)
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read test directory: %w", err)
+		}
+		for _, entry := range entries {
+			ext := strings.ToLower(filepath.Ext(entry.Name()))
+			if !entry.IsDir() && (ext == ".yaml" || ext == ".yml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,91 @@
+package suite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`
+name: files
+server: fs
+cases:
+  - name: reads
+    steps:
+      - call_tool: read_file
+        arguments: {path: /tmp/a}
+        expect:
+          - equals: null
+          - path: $.content[0].text
+            contains: hello
+          - max_latency: 250ms
+  - name: other server
+    server: web
+    steps:
+      - get_prompt: summarize
+`))
+	require.NoError(t, err)
+
+	require.Len(t, s.Cases, 2)
+	step := s.Cases[0].Steps[0]
+	kind, target := step.Kind()
+	assert.Equal(t, KindCallTool, kind)
+	assert.Equal(t, "read_file", target)
+	assert.Equal(t, map[string]interface{}{"path": "/tmp/a"}, step.Arguments)
+
+	require.Len(t, step.Expect, 3)
+	assert.True(t, step.Expect[0].hasEquals)
+	assert.Nil(t, step.Expect[0].Equals)
+	assert.Equal(t, "$.content[0].text", step.Expect[1].Path)
+	assert.Equal(t, "hello", step.Expect[1].Contains)
+	assert.Equal(t, 250*time.Millisecond, step.Expect[2].MaxLatency)
+
+	assert.Equal(t, []string{"fs", "web"}, s.Servers("default"))
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"no cases", "name: empty\n", "test suite has no cases"},
+		{"unknown field", "cases:\n  - name: a\n    stepz: []\n", "field stepz not found"},
+		{"no action", "cases:\n  - name: a\n    steps:\n      - arguments: {}\n", "expected exactly one of call_tool, read_resource or get_prompt"},
+		{"two actions", "cases:\n  - name: a\n    steps:\n      - {call_tool: x, get_prompt: y}\n", "expected exactly one"},
+		{"two checks", "cases:\n  - name: a\n    steps:\n      - call_tool: x\n        expect:\n          - {equals: 1, contains: 1}\n", "exactly one of equals"},
+		{"unknown check", "cases:\n  - name: a\n    steps:\n      - call_tool: x\n        expect:\n          - {matches: 1}\n", `unknown assertion field "matches"`},
+		{"bad regex", "cases:\n  - name: a\n    steps:\n      - call_tool: x\n        expect:\n          - {regex: '('}\n", "invalid regex"},
+		{"bad path", "cases:\n  - name: a\n    steps:\n      - call_tool: x\n        expect:\n          - {path: content, equals: 1}\n", "must start with $"},
+		{"exists without path", "cases:\n  - name: a\n    steps:\n      - call_tool: x\n        expect:\n          - {exists: true}\n", "exists needs a path"},
+		{"is_error on prompt", "cases:\n  - name: a\n    steps:\n      - get_prompt: x\n        expect:\n          - {is_error: true}\n", "is_error only applies to call_tool"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.input))
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFiles(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yml", "notes.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), nil, 0o644))
+	}
+	single := filepath.Join(dir, "notes.txt")
+
+	files, err := Files([]string{dir, single})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.yaml"), filepath.Join(dir, "b.yml"), single}, files)
+
+	_, err = Files([]string{filepath.Join(dir, "missing")})
+	assert.Error(t, err)
+}